@@ -1,7 +1,9 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"os"
 
 	_ "github.com/wuwen/hello-go/docs"
 	"github.com/wuwen/hello-go/internal/app"
@@ -23,6 +25,34 @@ func main() {
 		log.Fatalf("Failed to initialize app: %v", err)
 	}
 
+	// 子命令：server export [-full]、server import-wordpress [-dry-run] [-owner name] <file>
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			fs := flag.NewFlagSet("export", flag.ExitOnError)
+			full := fs.Bool("full", false, "re-render every article instead of only the changed ones")
+			fs.Parse(os.Args[2:])
+
+			if err := app.Export(*full); err != nil {
+				log.Fatalf("Failed to export site: %v", err)
+			}
+			return
+		case "import-wordpress":
+			fs := flag.NewFlagSet("import-wordpress", flag.ExitOnError)
+			dryRun := fs.Bool("dry-run", false, "only report what would be imported")
+			owner := fs.String("owner", "admin", "user that owns posts whose author is not in the file")
+			fs.Parse(os.Args[2:])
+			if fs.NArg() != 1 {
+				log.Fatalf("Usage: %s import-wordpress [-dry-run] [-owner name] <file>", os.Args[0])
+			}
+
+			if err := app.ImportWordPress(fs.Arg(0), *owner, *dryRun); err != nil {
+				log.Fatalf("Failed to import: %v", err)
+			}
+			return
+		}
+	}
+
 	if err := app.Run(); err != nil {
 		log.Fatalf("Error running app: %v", err)
 	}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -18,10 +19,17 @@ import (
 
 	"github.com/wuwen/hello-go/internal/handler"
 	"github.com/wuwen/hello-go/internal/middleware"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+	"github.com/wuwen/hello-go/internal/pkg/breach"
 	"github.com/wuwen/hello-go/internal/pkg/config"
+	"github.com/wuwen/hello-go/internal/pkg/lint"
+	"github.com/wuwen/hello-go/internal/pkg/mail"
+	"github.com/wuwen/hello-go/internal/pkg/oidc"
+	"github.com/wuwen/hello-go/internal/pkg/theme"
 	"github.com/wuwen/hello-go/internal/repository"
 	"github.com/wuwen/hello-go/internal/router"
 	"github.com/wuwen/hello-go/internal/router/api"
+	"github.com/wuwen/hello-go/internal/router/web"
 	"github.com/wuwen/hello-go/internal/service"
 )
 
@@ -30,15 +38,22 @@ type App struct {
 	router   *gin.Engine
 	server   *http.Server
 	enforcer *casbin.Enforcer
+	tasks    []func(ctx context.Context) // 随服务启动、关闭时停止的后台任务
+	exporter *service.ExportService
+	importer *service.ImportService
 }
 
 func New() *App {
 	return &App{}
 }
 
-func (a *App) setupDependencies(db *gorm.DB) {
+func (a *App) setupDependencies(db *gorm.DB) error {
 	// 创建 gin 引擎
 	r := gin.Default()
+	// 客户端 IP 用于登录失败计数等，只信任配置的代理转发的地址
+	if err := r.SetTrustedProxies(a.config.Server.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid server.trusted_proxies: %v", err)
+	}
 
 	// 应用中间件
 	r.Use(middleware.LoggerMiddleware())
@@ -49,23 +64,211 @@ func (a *App) setupDependencies(db *gorm.DB) {
 	// 初始化策略服务
 	policyService := service.NewPolicyService(a.enforcer)
 
+	// 初始化 webhook 服务
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookService := service.NewWebhookService(webhookRepo)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	a.tasks = append(a.tasks, webhookService.Run)
+
 	// 初始化角色服务
 	roleRepo := repository.NewRoleRepository(db)
-	roleService := service.NewRoleService(roleRepo, policyService)
+	roleService := service.NewRoleService(roleRepo, policyService, webhookService)
 	roleHandler := handler.NewRoleHandler(roleService)
 
-	// 初始化文章服务
+	// 初始化文章、协作者、审核及系列服务
+	userRepo := repository.NewUserRepository(db)
 	articleRepo := repository.NewArticleRepository(db)
-	articleService := service.NewArticleService(articleRepo)
+	articleGrantRepo := repository.NewArticleGrantRepository(db)
+	collaboratorService := service.NewCollaboratorService(articleGrantRepo, articleRepo, userRepo, policyService)
+	collaboratorHandler := handler.NewCollaboratorHandler(collaboratorService)
+	reviewRepo := repository.NewReviewRepository(db)
+	reviewService := service.NewReviewService(reviewRepo, articleRepo, userRepo, collaboratorService, webhookService,
+		policyService, a.config.Review.MinApprovals, a.config.Review.ReviewerRoles)
+	reviewHandler := handler.NewReviewHandler(reviewService)
+	seriesRepo := repository.NewSeriesRepository(db)
+	seriesService := service.NewSeriesService(seriesRepo, articleRepo, collaboratorService)
+	seriesHandler := handler.NewSeriesHandler(seriesService)
+	seoService := service.NewSEOService(a.config.Site.Name, a.config.Site.BaseURL)
+	linter, err := lint.NewEngine(a.config.Lint.Rules,
+		append(lint.DefaultRules(), service.NewBrokenLinkRule(articleRepo, a.config.Site.BaseURL))...)
+	if err != nil {
+		return fmt.Errorf("failed to load lint rules: %v", err)
+	}
+	articleService := service.NewArticleService(articleRepo, seriesService, collaboratorService, reviewService, seoService,
+		linter, webhookService)
 	articleHandler := handler.NewArticleHandler(articleService)
 
-	// 初始化用户服务
-	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo, roleRepo, policyService)
+	// 初始化静态站点导出服务
+	site := theme.Site{Name: a.config.Site.Name, BaseURL: strings.TrimRight(a.config.Site.BaseURL, "/")}
+	a.exporter = service.NewExportService(articleRepo, seoService, site, a.config.Theme.Dir,
+		a.config.Export.Dir, a.config.Export.PageSize)
+	exportHandler := handler.NewExportHandler(a.exporter)
+
+	// 初始化电子书导出服务
+	ebookService := service.NewEbookService(articleRepo, seriesRepo, a.config.Site.Name)
+	ebookHandler := handler.NewEbookHandler(ebookService)
+
+	// 初始化展示位服务
+	placementRepo := repository.NewPlacementRepository(db)
+	placementService := service.NewPlacementService(placementRepo, articleRepo)
+	placementHandler := handler.NewPlacementHandler(placementService)
+
+	// 初始化预览链接服务
+	previewRepo := repository.NewPreviewRepository(db)
+	previewService := service.NewPreviewService(previewRepo, articleRepo, collaboratorService, a.config.JWT.Secret,
+		a.config.Site.BaseURL)
+	previewHandler := handler.NewPreviewHandler(previewService)
+
+	// 初始化自定义内容服务
+	contentTypeRepo := repository.NewContentTypeRepository(db)
+	contentEntryRepo := repository.NewContentEntryRepository(db)
+	contentTypeService := service.NewContentTypeService(contentTypeRepo, contentEntryRepo, policyService)
+	contentTypeHandler := handler.NewContentTypeHandler(contentTypeService)
+	contentService := service.NewContentService(contentTypeRepo, contentEntryRepo, articleRepo)
+	contentHandler := handler.NewContentHandler(contentService)
+
+	// 初始化编辑日历服务
+	calendarRepo := repository.NewCalendarRepository(db)
+	calendarService := service.NewCalendarService(calendarRepo, articleRepo, reviewRepo, collaboratorService,
+		a.config.Site.Name, a.config.Site.BaseURL)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+
+	// 初始化导入服务
+	importRepo := repository.NewImportRepository(db)
+	a.importer = service.NewImportService(importRepo, userRepo, policyService)
+	importHandler := handler.NewImportHandler(a.importer)
+
+	// 初始化邮件发送，邮件经队列异步发送
+	mailSender, err := mail.NewSender(&a.config.Mail, a.config.Server.Mode == gin.ReleaseMode)
+	if err != nil {
+		return err
+	}
+	mailTemplates, err := mail.LoadTemplates(a.config.Mail.TemplateDir, a.config.Mail.Locale)
+	if err != nil {
+		return err
+	}
+	mailQueue := mail.NewQueue(mailSender, a.config.Mail.QueueSize, a.config.Mail.MaxRetries, a.config.Mail.RetryInterval)
+	a.tasks = append(a.tasks, mailQueue.Run)
+	mailer := mail.NewMailer(mailQueue, mailTemplates)
+
+	// 初始化用户及 token 服务
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	tokenService := service.NewTokenService(refreshTokenRepo, userRepo, a.config.JWT.ExpireTime, a.config.JWT.RefreshExpireTime)
+	a.tasks = append(a.tasks, tokenService.Run)
+	revocationService := service.NewRevocationService(repository.NewTokenRevocationRepository(db), a.config.JWT.ExpireTime)
+	auth.SetRevocationStore(revocationService)
+	a.tasks = append(a.tasks, revocationService.Run)
+	personalTokenService := service.NewPersonalTokenService(repository.NewPersonalTokenRepository(db), userRepo, policyService)
+	auth.SetPersonalTokenStore(personalTokenService)
+	personalTokenHandler := handler.NewPersonalTokenHandler(personalTokenService)
+	verificationService := service.NewVerificationService(userRepo, mailer,
+		a.config.Account.RequireEmailVerification, a.config.Account.VerifyExpire, a.config.Account.VerifyResendInterval,
+		a.config.Site.Name, a.config.Site.BaseURL)
+	passwordResetService := service.NewPasswordResetService(repository.NewPasswordResetRepository(db), userRepo,
+		mailer, a.config.Account.ResetExpire, a.config.Site.Name)
+	a.tasks = append(a.tasks, passwordResetService.Run)
+	twoFactorService := service.NewTwoFactorService(repository.NewTwoFactorRepository(db), userRepo, roleRepo,
+		policyService, a.config.Site.Name)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+	loginGuardService := service.NewLoginGuardService(repository.NewLoginFailureRepository(db), webhookService,
+		service.LockoutPolicy{
+			MaxAttempts:   a.config.Lockout.MaxAttempts,
+			IPMaxAttempts: a.config.Lockout.IPMaxAttempts,
+			Window:        a.config.Lockout.Window,
+			Duration:      a.config.Lockout.Duration,
+			BaseDelay:     a.config.Lockout.BaseDelay,
+			MaxDelay:      a.config.Lockout.MaxDelay,
+		})
+	a.tasks = append(a.tasks, loginGuardService.Run)
+	var breachedPasswords breach.Source
+	if a.config.Password.CheckBreached {
+		if breachedPasswords, err = breach.Open(a.config.Password.BreachedList); err != nil {
+			return err
+		}
+	}
+	passwordPolicy := service.NewPasswordPolicy(service.PasswordRules{
+		MinLength:     a.config.Password.MinLength,
+		RequireLower:  a.config.Password.RequireLower,
+		RequireUpper:  a.config.Password.RequireUpper,
+		RequireDigit:  a.config.Password.RequireDigit,
+		RequireSymbol: a.config.Password.RequireSymbol,
+		CheckBreached: a.config.Password.CheckBreached,
+	}, breachedPasswords)
+	userService := service.NewUserService(userRepo, roleRepo, policyService, tokenService, revocationService,
+		personalTokenService, verificationService, passwordResetService, twoFactorService, loginGuardService, passwordPolicy, webhookService)
 	userHandler := handler.NewUserHandler(userService)
 
-	// 注册路由
-	a.setupRoutes(r, articleHandler, userHandler, roleHandler)
+	routers := []router.Router{
+		api.NewHealthRouter(),
+		api.NewUserRouter(userHandler),
+		api.NewTwoFactorRouter(twoFactorHandler),
+		api.NewPersonalTokenRouter(personalTokenHandler),
+		api.NewRoleRouter(roleHandler),
+		api.NewArticleRouter(articleHandler),
+		api.NewCollaboratorRouter(collaboratorHandler),
+		api.NewReviewRouter(reviewHandler),
+		api.NewSeriesRouter(seriesHandler),
+		api.NewPlacementRouter(placementHandler),
+		api.NewCalendarRouter(calendarHandler),
+		api.NewExportRouter(exportHandler),
+		api.NewEbookRouter(ebookHandler),
+		api.NewImportRouter(importHandler),
+		api.NewPreviewRouter(previewHandler),
+		api.NewContentTypeRouter(contentTypeHandler),
+		api.NewContentRouter(contentHandler),
+		api.NewWebhookRouter(webhookHandler),
+	}
+
+	// 通过外部 OIDC 身份提供方登录
+	if a.config.OIDC.Enabled {
+		redirectURL := a.config.OIDC.RedirectURL
+		if redirectURL == "" {
+			redirectURL = strings.TrimSuffix(a.config.Site.BaseURL, "/") + "/api/v1/users/oidc/callback"
+		}
+		oidcClient := oidc.NewClient(oidc.Config{
+			Issuer:       a.config.OIDC.Issuer,
+			ClientID:     a.config.OIDC.ClientID,
+			ClientSecret: a.config.OIDC.ClientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       a.config.OIDC.Scopes,
+		}, nil)
+		roleMappings := make([]service.OIDCRoleMapping, 0, len(a.config.OIDC.RoleMapping))
+		for _, m := range a.config.OIDC.RoleMapping {
+			roleMappings = append(roleMappings, service.OIDCRoleMapping{Value: m.Value, Role: m.Role})
+		}
+		oidcService := service.NewOIDCService(oidcClient, repository.NewExternalIdentityRepository(db), userRepo,
+			policyService, userService, webhookService, service.OIDCOptions{
+				UsernameClaim: a.config.OIDC.UsernameClaim,
+				EmailClaim:    a.config.OIDC.EmailClaim,
+				RoleClaim:     a.config.OIDC.RoleClaim,
+				RoleMappings:  roleMappings,
+				DefaultRole:   a.config.OIDC.DefaultRole,
+				AutoCreate:    a.config.OIDC.AutoCreate,
+			})
+		routers = append(routers, api.NewOIDCRouter(
+			handler.NewOIDCHandler(oidcService, strings.HasPrefix(a.config.Site.BaseURL, "https://"))))
+	}
+
+	// 注册路由，casbin 策略按用户名授权，token 中只有用户 ID
+	subject := func(userID uint) (string, error) {
+		user, err := userRepo.FindById(userID)
+		if err != nil {
+			return "", err
+		}
+		return service.UserPrefix + user.Username, nil
+	}
+	a.setupRoutes(r, routers, subject)
+
+	// 服务端渲染的公开站点，debug 模式下每次请求都重新加载模板
+	if a.config.Web.Enabled {
+		themes, err := theme.NewLoader(a.config.Theme.Dir, a.config.Server.Mode == gin.DebugMode)
+		if err != nil {
+			return fmt.Errorf("failed to load theme: %v", err)
+		}
+		siteService := service.NewSiteService(articleService, articleRepo, articleGrantRepo, userRepo,
+			seoService, site, a.config.Web.PageSize)
+		web.NewSiteRouter(handler.NewSiteHandler(siteService, themes)).Register(&r.RouterGroup)
+	}
 
 	// 创建 HTTP 服务器
 	a.router = r
@@ -73,10 +276,10 @@ func (a *App) setupDependencies(db *gorm.DB) {
 		Addr:    ":8080",
 		Handler: r,
 	}
+	return nil
 }
 
-func (a *App) setupRoutes(r *gin.Engine, articleHandler *handler.ArticleHandler,
-	userHandler *handler.UserHandler, roleHandler *handler.RoleHandler) {
+func (a *App) setupRoutes(r *gin.Engine, routers []router.Router, subject middleware.SubjectFunc) {
 	// swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -84,21 +287,22 @@ func (a *App) setupRoutes(r *gin.Engine, articleHandler *handler.ArticleHandler,
 	publicGroup := r.Group("/api/v1")
 	authGroup := r.Group("/api/v1")
 	authGroup.Use(middleware.AuthMiddleware())
-	authGroup.Use(middleware.CasbinMiddleware(a.enforcer))
+	authGroup.Use(middleware.CasbinMiddleware(a.enforcer, subject))
 
 	// 路由注册
-	routers := []router.Router{
-		api.NewHealthRouter(),
-		api.NewUserRouter(userHandler),
-		api.NewRoleRouter(roleHandler),
-		api.NewArticleRouter(articleHandler),
-	}
 	for _, r := range routers {
 		r.Register(publicGroup, authGroup)
 	}
 }
 
 func (a *App) Run() error {
+	// 启动后台任务
+	taskCtx, stopTasks := context.WithCancel(context.Background())
+	defer stopTasks()
+	for _, task := range a.tasks {
+		go task(taskCtx)
+	}
+
 	// 启动服务器
 	go func() {
 		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -111,6 +315,7 @@ func (a *App) Run() error {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutdown Server ...")
+	stopTasks()
 
 	// 优雅关闭
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -1,12 +1,16 @@
 package app
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"log"
 
 	"github.com/casbin/casbin/v2"
 	gormadapter "github.com/casbin/gorm-adapter/v3"
 	"github.com/gin-gonic/gin"
 	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
 	"github.com/wuwen/hello-go/internal/pkg/config"
 	"github.com/wuwen/hello-go/internal/pkg/database"
 	"gorm.io/gorm"
@@ -34,9 +38,7 @@ func (a *App) Initialize() error {
 		return err
 	}
 
-	a.setupDependencies(db)
-
-	return nil
+	return a.setupDependencies(db)
 }
 
 func (a *App) loadConfig() error {
@@ -47,6 +49,7 @@ func (a *App) loadConfig() error {
 	a.config = cfg
 
 	gin.SetMode(cfg.Server.Mode)
+	auth.Initialize(cfg.JWT.Secret, cfg.JWT.ExpireTime)
 	return nil
 }
 
@@ -62,7 +65,32 @@ func (a *App) initDatabase() (*gorm.DB, error) {
 	}
 
 	// 自动迁移数据库表
-	if err := db.AutoMigrate(&model.Role{}, &model.User{}, &model.Article{}); err != nil {
+	if err := db.AutoMigrate(
+		&model.Role{},
+		&model.User{},
+		&model.RefreshToken{},
+		&model.TokenRevocation{},
+		&model.PersonalToken{},
+		&model.ExternalIdentity{},
+		&model.PasswordReset{},
+		&model.TwoFactor{},
+		&model.BackupCode{},
+		&model.LoginFailure{},
+		&model.Article{},
+		&model.ArticleGrant{},
+		&model.ArticleReview{},
+		&model.Series{},
+		&model.SeriesPart{},
+		&model.PlacementItem{},
+		&model.PreviewLink{},
+		&model.PreviewAccess{},
+		&model.ContentType{},
+		&model.ContentEntry{},
+		&model.Webhook{},
+		&model.WebhookDelivery{},
+		&model.ImportMapping{},
+		&model.CalendarFeed{},
+	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %v", err)
 	}
 
@@ -94,11 +122,30 @@ func (a *App) initAdmin(db *gorm.DB) error {
 		Email:    "admin@example.com",
 		Status:   model.UserStatusActive,
 	}
-	if err := adminUser.SetPassword("admin123"); err != nil {
+
+	var count int64
+	if err := db.Model(&model.User{}).Where("username = ?", adminUser.Username).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check admin user: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	// 未配置管理员密码时生成随机密码，只在创建时输出一次
+	password := a.config.Account.AdminPassword
+	if password == "" {
+		b := make([]byte, 12)
+		if _, err := rand.Read(b); err != nil {
+			return fmt.Errorf("failed to generate admin password: %v", err)
+		}
+		password = hex.EncodeToString(b)
+		log.Printf("created admin user with generated password %s, change it after logging in", password)
+	}
+	if err := adminUser.SetPassword(password); err != nil {
 		return fmt.Errorf("failed to set admin password: %v", err)
 	}
 
-	if err := db.Where("username = ?", adminUser.Username).FirstOrCreate(&adminUser).Error; err != nil {
+	if err := db.Create(&adminUser).Error; err != nil {
 		return fmt.Errorf("failed to create admin user: %v", err)
 	}
 
@@ -0,0 +1,19 @@
+package app
+
+import (
+	"log"
+
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+// Export 导出静态站点，供命令行使用
+func (a *App) Export(full bool) error {
+	result, err := a.exporter.Export(&service.ExportRequest{Full: full})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("exported %d articles to %s (rendered %d, skipped %d, removed %d, %d index pages) in %s",
+		result.Articles, result.Dir, result.Rendered, result.Skipped, result.Removed, result.Pages, result.Duration)
+	return nil
+}
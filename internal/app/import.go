@@ -0,0 +1,42 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// ImportWordPress 从 WXR 文件导入，供命令行使用。作者未知的文章归 owner 所有
+func (a *App) ImportWordPress(path, owner string, dryRun bool) error {
+	ownerID, err := a.importer.FindOwner(owner)
+	if err != nil {
+		return fmt.Errorf("owner %s: %v", owner, err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	report, err := a.importer.ImportWordPress(file, ownerID, dryRun)
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	if report.DryRun {
+		prefix = "[dry-run] "
+	}
+	log.Printf("%simported from %s", prefix, report.Source)
+	log.Printf("%susers: %d created, %d existing, %d skipped, %d failed", prefix,
+		report.Users.Created, report.Users.Existing, report.Users.Skipped, report.Users.Failed)
+	log.Printf("%sarticles: %d created, %d existing, %d skipped, %d failed", prefix,
+		report.Articles.Created, report.Articles.Existing, report.Articles.Skipped, report.Articles.Failed)
+	log.Printf("%snot supported: %d categories, %d tags, %d comments, %d attachments", prefix,
+		report.Unsupported.Categories, report.Unsupported.Tags, report.Unsupported.Comments, report.Unsupported.Attachments)
+	for _, warning := range report.Warnings {
+		log.Printf("%swarning: %s", prefix, warning)
+	}
+	return nil
+}
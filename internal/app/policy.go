@@ -1,48 +1,151 @@
 package app
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
+// initializeDefaultPolicies 补充缺少的默认策略，已有的策略保持不变，
+// 升级后的安装也能获得新接口的权限。只有首次初始化时才为 admin 用户分配管理员角色
 func (a *App) initializeDefaultPolicies() error {
-	// 如果策略存在，直接返回
-	if p, err := a.enforcer.GetPolicy(); err != nil || len(p) > 0 {
-		return nil
+	existing, err := a.enforcer.GetPolicy()
+	if err != nil {
+		return fmt.Errorf("failed to load casbin policy: %v", err)
+	}
+	// 先升级旧格式的路径，避免与下面补充的策略重复
+	if err := a.upgradeWildcardPolicies(existing); err != nil {
+		return err
 	}
+	initial := len(existing) == 0
 
 	policies := map[string][][]string{
 		"role:user": {
 			{"/api/v1/articles", "GET"},
 			{"/api/v1/articles", "POST"},
-			{"/api/v1/articles/*", "PUT"},
-			{"/api/v1/articles/*", "DELETE"},
+			{"/api/v1/articles/:id", "PUT"},
+			{"/api/v1/articles/:id", "DELETE"},
+			{"/api/v1/articles/:id/lint", "GET"},
+			{"/api/v1/articles/:id/submit", "POST"},
+			{"/api/v1/articles/:id/reviews", "GET"},
+			{"/api/v1/articles/:id/reviews/decision", "POST"},
+			{"/api/v1/reviews/mine", "GET"},
+			{"/api/v1/articles/:id/preview-links", "GET"},
+			{"/api/v1/articles/:id/preview-links", "POST"},
+			{"/api/v1/articles/:id/preview-links/:link_id", "DELETE"},
+			{"/api/v1/articles/:id/preview-links/:link_id/accesses", "GET"},
+			{"/api/v1/articles/:id/collaborators", "GET"},
+			{"/api/v1/articles/:id/collaborators", "PUT"},
+			{"/api/v1/articles/:id/collaborators/:user_id", "DELETE"},
+			{"/api/v1/series", "POST"},
+			{"/api/v1/series/:id", "PUT"},
+			{"/api/v1/series/:id/articles", "PUT"},
+			{"/api/v1/series/:id", "DELETE"},
+			{"/api/v1/ebooks", "POST"},
+			{"/api/v1/calendar", "GET"},
+			{"/api/v1/calendar/feed-token", "POST"},
+			{"/api/v1/calendar/feed-token", "DELETE"},
 			{"/api/v1/users", "GET"},
 			{"/api/v1/users", "POST"},
-			{"/api/v1/users/*", "PUT"},
-			{"/api/v1/users/*", "DELETE"},
+			{"/api/v1/users/:id", "PUT"},
+			{"/api/v1/users/:id", "DELETE"},
+			{"/api/v1/users/logout", "POST"},
+			{"/api/v1/users/2fa", "GET"},
+			{"/api/v1/users/2fa/setup", "POST"},
+			{"/api/v1/users/2fa/confirm", "POST"},
+			{"/api/v1/users/2fa/disable", "POST"},
+			{"/api/v1/users/2fa/backup-codes", "POST"},
+			{"/api/v1/users/tokens", "GET"},
+			{"/api/v1/users/tokens", "POST"},
+			{"/api/v1/users/tokens/:id", "DELETE"},
 		},
 		"role:admin": {
 			{"/api/v1/articles", "GET"},
 			{"/api/v1/articles", "POST"},
-			{"/api/v1/articles/*", "PUT"},
-			{"/api/v1/articles/*", "DELETE"},
+			{"/api/v1/articles/:id", "PUT"},
+			{"/api/v1/articles/:id", "DELETE"},
+			{"/api/v1/articles/:id/lint", "GET"},
+			{"/api/v1/articles/:id/submit", "POST"},
+			{"/api/v1/articles/:id/reviews", "GET"},
+			{"/api/v1/articles/:id/reviews/decision", "POST"},
+			{"/api/v1/reviews/mine", "GET"},
+			{"/api/v1/articles/:id/preview-links", "GET"},
+			{"/api/v1/articles/:id/preview-links", "POST"},
+			{"/api/v1/articles/:id/preview-links/:link_id", "DELETE"},
+			{"/api/v1/articles/:id/preview-links/:link_id/accesses", "GET"},
+			{"/api/v1/articles/:id/collaborators", "GET"},
+			{"/api/v1/articles/:id/collaborators", "PUT"},
+			{"/api/v1/articles/:id/collaborators/:user_id", "DELETE"},
+			{"/api/v1/series", "POST"},
+			{"/api/v1/series/:id", "PUT"},
+			{"/api/v1/series/:id/articles", "PUT"},
+			{"/api/v1/series/:id", "DELETE"},
+			{"/api/v1/ebooks", "POST"},
+			{"/api/v1/calendar", "GET"},
+			{"/api/v1/calendar/feed-token", "POST"},
+			{"/api/v1/calendar/feed-token", "DELETE"},
+			{"/api/v1/users/logout", "POST"},
+			{"/api/v1/users/2fa", "GET"},
+			{"/api/v1/users/2fa/setup", "POST"},
+			{"/api/v1/users/2fa/confirm", "POST"},
+			{"/api/v1/users/2fa/disable", "POST"},
+			{"/api/v1/users/2fa/backup-codes", "POST"},
+			{"/api/v1/users/tokens", "GET"},
+			{"/api/v1/users/tokens", "POST"},
+			{"/api/v1/users/tokens/:id", "DELETE"},
+			{"/api/v1/users/:id/sessions", "DELETE"},
+			{"/api/v1/users/:id/lockout", "DELETE"},
+			{"/api/v1/users/:id/role", "PUT"},
 			{"/api/v1/roles", "GET"},
 			{"/api/v1/roles", "POST"},
-			{"/api/v1/roles/*", "PUT"},
-			{"/api/v1/roles/*", "DELETE"},
+			{"/api/v1/roles/:id", "GET"},
+			{"/api/v1/roles/:id", "PUT"},
+			{"/api/v1/roles/:id", "DELETE"},
+			{"/api/v1/placements", "GET"},
+			{"/api/v1/placements/:name", "PUT"},
+			{"/api/v1/placements/:name", "DELETE"},
+			{"/api/v1/export", "POST"},
+			{"/api/v1/imports/wordpress", "POST"},
+			{"/api/v1/content-types", "GET"},
+			{"/api/v1/content-types", "POST"},
+			{"/api/v1/content-types/:id", "GET"},
+			{"/api/v1/content-types/:id", "PUT"},
+			{"/api/v1/content-types/:id", "DELETE"},
+			{"/api/v1/webhooks", "GET"},
+			{"/api/v1/webhooks", "POST"},
+			{"/api/v1/webhooks/:id", "GET"},
+			{"/api/v1/webhooks/:id", "PUT"},
+			{"/api/v1/webhooks/:id", "DELETE"},
+			{"/api/v1/webhooks/:id/deliveries", "GET"},
+			{"/api/v1/webhooks/:id/deliveries/:delivery_id/replay", "POST"},
 		},
 	}
 
+	added := 0
 	for role, rules := range policies {
 		for _, rule := range rules {
+			ok, err := a.enforcer.HasPolicy(role, rule[0], rule[1])
+			if err != nil {
+				return fmt.Errorf("failed to check %s policy: %v", role, err)
+			}
+			if ok {
+				continue
+			}
 			if _, err := a.enforcer.AddPolicy(role, rule[0], rule[1]); err != nil {
 				return fmt.Errorf("failed to add %s policy: %v", role, err)
 			}
+			added++
 		}
 	}
 
-	if err := a.enforcer.SavePolicy(); err != nil {
-		return fmt.Errorf("failed to save casbin policy: %v", err)
+	if added > 0 {
+		if err := a.enforcer.SavePolicy(); err != nil {
+			return fmt.Errorf("failed to save casbin policy: %v", err)
+		}
 	}
 
+	if !initial {
+		return nil
+	}
 	// 为管理员用户分配管理员角色
 	if _, err := a.enforcer.AddGroupingPolicy("user:admin", "role:admin"); err != nil {
 		return fmt.Errorf("failed to assign admin role to admin user: %v", err)
@@ -50,3 +153,29 @@ func (a *App) initializeDefaultPolicies() error {
 
 	return nil
 }
+
+// upgradeWildcardPolicies 旧版本的策略用 * 表示一段路径。keyMatch2 中 * 匹配之后的任意多段，
+// 会让 /users/* 覆盖 /users/:id/sessions 这样的管理接口，改写为只匹配一段的 :id
+func (a *App) upgradeWildcardPolicies(policies [][]string) error {
+	for _, p := range policies {
+		if len(p) < 3 {
+			continue
+		}
+		segments := strings.Split(p[1], "/")
+		changed := false
+		for i, segment := range segments {
+			if segment == "*" {
+				segments[i] = ":id"
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		upgraded := []string{p[0], strings.Join(segments, "/"), p[2]}
+		if _, err := a.enforcer.UpdatePolicy(p, upgraded); err != nil {
+			return fmt.Errorf("failed to upgrade policy %v: %v", p, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+)
+
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.csv")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	enforcer, err := casbin.NewEnforcer("../../configs/model.conf", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &App{enforcer: enforcer}
+}
+
+func TestInitializeDefaultPoliciesUpgradesExistingStore(t *testing.T) {
+	a := newTestApp(t)
+	// 旧版本的策略：通配符路径，且没有后来新增的接口
+	for _, p := range [][]string{
+		{"role:user", "/api/v1/articles/*", "PUT"},
+		{"role:editor", "/api/v1/placements", "GET"},
+	} {
+		if _, err := a.enforcer.AddPolicy(p[0], p[1], p[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := a.initializeDefaultPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range [][]string{
+		{"role:user", "/api/v1/articles/:id", "PUT"},
+		{"role:user", "/api/v1/calendar", "GET"},
+		{"role:admin", "/api/v1/webhooks/:id/deliveries", "GET"},
+		{"role:editor", "/api/v1/placements", "GET"},
+	} {
+		if ok, err := a.enforcer.HasPolicy(p[0], p[1], p[2]); err != nil || !ok {
+			t.Errorf("policy %v missing after upgrade", p)
+		}
+	}
+	if ok, _ := a.enforcer.HasPolicy("role:user", "/api/v1/articles/*", "PUT"); ok {
+		t.Error("wildcard policy kept after upgrade")
+	}
+	if ok, _ := a.enforcer.HasGroupingPolicy("user:admin", "role:admin"); ok {
+		t.Error("admin role assigned on an existing store")
+	}
+
+	// 再次执行不重复添加
+	before, err := a.enforcer.GetPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.initializeDefaultPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	after, err := a.enforcer.GetPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Errorf("second run changed policy count from %d to %d", len(before), len(after))
+	}
+}
+
+func TestInitializeDefaultPoliciesOnEmptyStore(t *testing.T) {
+	a := newTestApp(t)
+	if err := a.initializeDefaultPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := a.enforcer.HasGroupingPolicy("user:admin", "role:admin"); !ok {
+		t.Error("admin role not assigned on first initialization")
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -35,10 +36,16 @@ func (h *ArticleHandler) Create(c *gin.Context) {
 		return
 	}
 
-	article, err := h.svc.Create(&req)
+	article, err := h.svc.Create(c.GetUint("userID"), &req)
 	if err != nil {
+		var verr *service.ValidationError
+		if errors.As(err, &verr) {
+			response.ErrorWithData(c, http.StatusBadRequest, "invalid article", verr.Errors)
+			return
+		}
 		switch err {
-		case service.ErrTitleRequired, service.ErrContentRequired:
+		case service.ErrTitleRequired, service.ErrContentRequired,
+			service.ErrInvalidVisibility, service.ErrArticlePasswordRequired:
 			response.Error(c, http.StatusBadRequest, err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, "internal server error")
@@ -50,12 +57,15 @@ func (h *ArticleHandler) Create(c *gin.Context) {
 }
 
 // @Summary     Get article
-// @Description Get article by ID
+// @Description Get article by ID. Drafts and articles in review are visible to collaborators only
 // @Tags        articles
 // @Accept      json
 // @Produce     json
-// @Param       id  path     int true "Article ID"
-// @Success     200 {object} response.Response{data=model.Article}
+// @Param       id             path     int    true  "Article ID"
+// @Param       X-Unlock-Token header   string false "Unlock token of a password-protected article"
+// @Success     200 {object} response.Response{data=service.ArticleDetail}
+// @Failure     401 {object} response.Response
+// @Failure     403 {object} response.Response
 // @Failure     404 {object} response.Response
 // @Failure     500 {object} response.Response
 // @Security    BearerAuth
@@ -67,11 +77,15 @@ func (h *ArticleHandler) Get(c *gin.Context) {
 		return
 	}
 
-	article, err := h.svc.Get(uint(id))
+	article, err := h.svc.Get(uint(id), viewer(c))
 	if err != nil {
 		switch err {
 		case service.ErrArticleNotFound:
 			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrArticleMembersOnly:
+			response.Error(c, http.StatusUnauthorized, err.Error())
+		case service.ErrArticleLocked:
+			response.Error(c, http.StatusForbidden, err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, "internal server error")
 		}
@@ -81,8 +95,47 @@ func (h *ArticleHandler) Get(c *gin.Context) {
 	response.Success(c, article)
 }
 
+// @Summary     Get article meta tags
+// @Description Get ready-made <meta> tag HTML for an article, with SEO and Open Graph defaults derived from title and excerpt
+// @Tags        articles
+// @Accept      json
+// @Produce     json
+// @Param       id             path     int    true  "Article ID"
+// @Param       X-Unlock-Token header   string false "Unlock token of a password-protected article"
+// @Success     200 {object} response.Response{data=service.SEOMetaTags}
+// @Failure     401 {object} response.Response
+// @Failure     403 {object} response.Response
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /articles/{id}/meta [get]
+func (h *ArticleHandler) Meta(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	tags, err := h.svc.MetaTags(uint(id), viewer(c))
+	if err != nil {
+		switch err {
+		case service.ErrArticleNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrArticleMembersOnly:
+			response.Error(c, http.StatusUnauthorized, err.Error())
+		case service.ErrArticleLocked:
+			response.Error(c, http.StatusForbidden, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, tags)
+}
+
 // @Summary     List articles
-// @Description Get articles with pagination
+// @Description Get published articles visible to the caller, plus the articles the caller collaborates on, with pagination; members-only articles require a token
 // @Tags        articles
 // @Accept      json
 // @Produce     json
@@ -90,12 +143,13 @@ func (h *ArticleHandler) Get(c *gin.Context) {
 // @Param       page_size query    int false "Page size"
 // @Success     200      {object} response.Response{data=response.ListResponse{items=[]model.Article}}
 // @Failure     500      {object} response.Response
+// @Security    BearerAuth
 // @Router      /articles [get]
 func (h *ArticleHandler) List(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 
-	articles, total, err := h.svc.List(page, pageSize)
+	articles, total, err := h.svc.List(page, pageSize, viewer(c))
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "internal server error")
 		return
@@ -107,8 +161,50 @@ func (h *ArticleHandler) List(c *gin.Context) {
 	})
 }
 
+// @Summary     Unlock article
+// @Description Exchange the password of a password-protected article for a short-lived unlock token, sent back as the X-Unlock-Token header
+// @Tags        articles
+// @Accept      json
+// @Produce     json
+// @Param       id       path     int                          true "Article ID"
+// @Param       password body     service.UnlockArticleRequest true "Article password"
+// @Success     200      {object} response.Response{data=service.UnlockArticleResponse}
+// @Failure     400      {object} response.Response
+// @Failure     403      {object} response.Response
+// @Failure     404      {object} response.Response
+// @Failure     500      {object} response.Response
+// @Router      /articles/{id}/unlock [post]
+func (h *ArticleHandler) Unlock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	var req service.UnlockArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.svc.Unlock(uint(id), &req)
+	if err != nil {
+		switch err {
+		case service.ErrArticleNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrInvalidArticlePassword:
+			response.Error(c, http.StatusForbidden, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, resp)
+}
+
 // @Summary     Update article
-// @Description Update article by ID
+// @Description Update article by ID. While the article is in review only the status can change
 // @Tags        articles
 // @Accept      json
 // @Produce     json
@@ -116,7 +212,10 @@ func (h *ArticleHandler) List(c *gin.Context) {
 // @Param       article body     service.UpdateArticleRequest true "Article info"
 // @Success     200    {object} response.Response{data=model.Article}
 // @Failure     400    {object} response.Response
+// @Failure     403    {object} response.Response
 // @Failure     404    {object} response.Response
+// @Failure     409    {object} response.Response
+// @Failure     422    {object} response.Response{data=[]lint.Finding}
 // @Failure     500    {object} response.Response
 // @Security    BearerAuth
 // @Router      /articles/{id} [put]
@@ -133,11 +232,81 @@ func (h *ArticleHandler) Update(c *gin.Context) {
 		return
 	}
 
-	article, err := h.svc.Update(uint(id), &req)
+	article, err := h.svc.Update(c.GetUint("userID"), uint(id), &req)
+	if err != nil {
+		var verr *service.ValidationError
+		if errors.As(err, &verr) {
+			response.ErrorWithData(c, http.StatusBadRequest, "invalid article", verr.Errors)
+			return
+		}
+		var lerr *service.ArticleLintError
+		if errors.As(err, &lerr) {
+			response.ErrorWithData(c, http.StatusUnprocessableEntity, err.Error(), lerr.Findings)
+			return
+		}
+		switch err {
+		case service.ErrArticleNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrInvalidVisibility, service.ErrArticlePasswordRequired, service.ErrSubmitForReview:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		case service.ErrArticleForbidden:
+			response.Error(c, http.StatusForbidden, err.Error())
+		case service.ErrArticleNotApproved, service.ErrArticleInReview:
+			response.Error(c, http.StatusConflict, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, article)
+}
+
+// @Summary     Submit article for review
+// @Description Move an article into review and assign reviewers. Resubmitting resets previous decisions
+// @Tags        reviews
+// @Accept      json
+// @Produce     json
+// @Param       id     path     int                          true "Article ID"
+// @Param       submit body     service.SubmitArticleRequest true "Reviewers"
+// @Success     200    {object} response.Response{data=model.Article}
+// @Failure     400    {object} response.Response
+// @Failure     403    {object} response.Response
+// @Failure     404    {object} response.Response
+// @Failure     409    {object} response.Response
+// @Failure     422    {object} response.Response{data=[]lint.Finding}
+// @Failure     500    {object} response.Response
+// @Security    BearerAuth
+// @Router      /articles/{id}/submit [post]
+func (h *ArticleHandler) Submit(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	var req service.SubmitArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	article, err := h.svc.Submit(c.GetUint("userID"), uint(id), &req)
 	if err != nil {
+		var lerr *service.ArticleLintError
+		if errors.As(err, &lerr) {
+			response.ErrorWithData(c, http.StatusUnprocessableEntity, err.Error(), lerr.Findings)
+			return
+		}
 		switch err {
 		case service.ErrArticleNotFound:
 			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrInvalidReviewers, service.ErrNotEnoughReviewers, service.ErrReviewerRoleRequired:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		case service.ErrArticleForbidden:
+			response.Error(c, http.StatusForbidden, err.Error())
+		case service.ErrArticleAlreadyPublished:
+			response.Error(c, http.StatusConflict, err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, "internal server error")
 		}
@@ -147,6 +316,41 @@ func (h *ArticleHandler) Update(c *gin.Context) {
 	response.Success(c, article)
 }
 
+// @Summary     Lint article
+// @Description Run the pre-publish checks on an article. Findings with severity error block publishing
+// @Tags        articles
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "Article ID"
+// @Success     200 {object} response.Response{data=[]lint.Finding}
+// @Failure     403 {object} response.Response
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /articles/{id}/lint [get]
+func (h *ArticleHandler) Lint(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	findings, err := h.svc.Lint(c.GetUint("userID"), uint(id))
+	if err != nil {
+		switch err {
+		case service.ErrArticleNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrArticleForbidden:
+			response.Error(c, http.StatusForbidden, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, findings)
+}
+
 // @Summary     Delete article
 // @Description Delete article by ID
 // @Tags        articles
@@ -154,6 +358,7 @@ func (h *ArticleHandler) Update(c *gin.Context) {
 // @Produce     json
 // @Param       id  path     int true "Article ID"
 // @Success     200 {object} response.Response
+// @Failure     403 {object} response.Response
 // @Failure     404 {object} response.Response
 // @Failure     500 {object} response.Response
 // @Security    BearerAuth
@@ -165,10 +370,12 @@ func (h *ArticleHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.svc.Delete(uint(id)); err != nil {
+	if err := h.svc.Delete(c.GetUint("userID"), uint(id)); err != nil {
 		switch err {
 		case service.ErrArticleNotFound:
 			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrArticleForbidden:
+			response.Error(c, http.StatusForbidden, err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, "internal server error")
 		}
@@ -177,3 +384,11 @@ func (h *ArticleHandler) Delete(c *gin.Context) {
 
 	response.Success(c, nil)
 }
+
+// viewer 从上下文中取出公开接口的访问者信息
+func viewer(c *gin.Context) *service.Viewer {
+	return &service.Viewer{
+		UserID:      c.GetUint("userID"),
+		UnlockToken: c.GetHeader("X-Unlock-Token"),
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type CalendarHandler struct {
+	svc *service.CalendarService
+}
+
+func NewCalendarHandler(svc *service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{svc: svc}
+}
+
+// @Summary     Editorial calendar
+// @Description Publish dates, review submissions and review deadlines aggregated by day (UTC). Unpublished articles appear only to their collaborators. Defaults to the current month
+// @Tags        calendar
+// @Accept      json
+// @Produce     json
+// @Param       from query    string false "First day (YYYY-MM-DD)"
+// @Param       to   query    string false "Last day, inclusive (YYYY-MM-DD)"
+// @Success     200  {object} response.Response{data=service.Calendar}
+// @Failure     400  {object} response.Response
+// @Failure     500  {object} response.Response
+// @Security    BearerAuth
+// @Router      /calendar [get]
+func (h *CalendarHandler) Get(c *gin.Context) {
+	calendar, err := h.svc.Range(c.GetUint("userID"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		switch err {
+		case service.ErrInvalidCalendarRange:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, calendar)
+}
+
+// @Summary     Calendar feed
+// @Description iCalendar feed of the editorial calendar, authenticated by a personal feed token
+// @Tags        calendar
+// @Produce     text/calendar
+// @Param       token query    string true "Feed token"
+// @Success     200   {string} string
+// @Failure     404   {object} response.Response
+// @Failure     500   {object} response.Response
+// @Router      /calendar.ics [get]
+func (h *CalendarHandler) Feed(c *gin.Context) {
+	data, err := h.svc.Feed(c.Query("token"))
+	if err != nil {
+		switch err {
+		case service.ErrInvalidFeedToken:
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-cache")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", data)
+}
+
+// @Summary     Create calendar feed token
+// @Description Issue a personal feed token for calendar apps. Any previous token stops working
+// @Tags        calendar
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} response.Response{data=service.CalendarFeedToken}
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /calendar/feed-token [post]
+func (h *CalendarHandler) CreateFeedToken(c *gin.Context) {
+	token, err := h.svc.CreateFeedToken(c.GetUint("userID"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response.Success(c, token)
+}
+
+// @Summary     Revoke calendar feed token
+// @Description Revoke the current user's calendar feed token
+// @Tags        calendar
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /calendar/feed-token [delete]
+func (h *CalendarHandler) DeleteFeedToken(c *gin.Context) {
+	if err := h.svc.DeleteFeedToken(c.GetUint("userID")); err != nil {
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type CollaboratorHandler struct {
+	svc *service.CollaboratorService
+}
+
+func NewCollaboratorHandler(svc *service.CollaboratorService) *CollaboratorHandler {
+	return &CollaboratorHandler{svc: svc}
+}
+
+// @Summary     List collaborators
+// @Description List users granted access to an article
+// @Tags        collaborators
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "Article ID"
+// @Success     200 {object} response.Response{data=[]service.Collaborator}
+// @Failure     403 {object} response.Response
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /articles/{id}/collaborators [get]
+func (h *CollaboratorHandler) List(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	collaborators, err := h.svc.List(uint(id), c.GetUint("userID"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, collaborators)
+}
+
+// @Summary     Set collaborator
+// @Description Grant a user viewer/editor access to an article, or transfer ownership with role owner. Owner only
+// @Tags        collaborators
+// @Accept      json
+// @Produce     json
+// @Param       id           path     int                            true "Article ID"
+// @Param       collaborator body     service.SetCollaboratorRequest true "Collaborator info"
+// @Success     200          {object} response.Response
+// @Failure     400          {object} response.Response
+// @Failure     403          {object} response.Response
+// @Failure     404          {object} response.Response
+// @Failure     500          {object} response.Response
+// @Security    BearerAuth
+// @Router      /articles/{id}/collaborators [put]
+func (h *CollaboratorHandler) Set(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	var req service.SetCollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.svc.Set(uint(id), c.GetUint("userID"), &req); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// @Summary     Remove collaborator
+// @Description Revoke a user's access to an article. Owner only
+// @Tags        collaborators
+// @Accept      json
+// @Produce     json
+// @Param       id      path     int true "Article ID"
+// @Param       user_id path     int true "User ID"
+// @Success     200     {object} response.Response
+// @Failure     400     {object} response.Response
+// @Failure     403     {object} response.Response
+// @Failure     404     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Security    BearerAuth
+// @Router      /articles/{id}/collaborators/{user_id} [delete]
+func (h *CollaboratorHandler) Remove(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	if err := h.svc.Remove(uint(id), c.GetUint("userID"), uint(userID)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+func (h *CollaboratorHandler) handleError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrArticleNotFound, service.ErrUserNotFound, service.ErrCollaboratorNotFound:
+		response.Error(c, http.StatusNotFound, err.Error())
+	case service.ErrInvalidGrantRole, service.ErrCannotRemoveOwner, service.ErrCannotChangeOwnerRole:
+		response.Error(c, http.StatusBadRequest, err.Error())
+	case service.ErrArticleForbidden:
+		response.Error(c, http.StatusForbidden, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+	}
+}
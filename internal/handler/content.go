@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type ContentHandler struct {
+	svc *service.ContentService
+}
+
+func NewContentHandler(svc *service.ContentService) *ContentHandler {
+	return &ContentHandler{svc: svc}
+}
+
+// @Summary     Create content entry
+// @Description Create an entry of a custom content type
+// @Tags        content
+// @Accept      json
+// @Produce     json
+// @Param       type  path     string                      true "Content type name"
+// @Param       entry body     service.ContentEntryRequest true "Entry data"
+// @Success     200   {object} response.Response{data=model.ContentEntry}
+// @Failure     400   {object} response.Response{data=[]service.FieldError}
+// @Failure     404   {object} response.Response
+// @Failure     500   {object} response.Response
+// @Security    BearerAuth
+// @Router      /content/{type} [post]
+func (h *ContentHandler) Create(c *gin.Context) {
+	var req service.ContentEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entry, err := h.svc.Create(c.Param("type"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, entry)
+}
+
+// @Summary     List content entries
+// @Description Get entries of a custom content type with pagination
+// @Tags        content
+// @Accept      json
+// @Produce     json
+// @Param       type      path     string true  "Content type name"
+// @Param       page      query    int    false "Page number"
+// @Param       page_size query    int    false "Page size"
+// @Success     200       {object} response.Response{data=response.ListResponse{items=[]model.ContentEntry}}
+// @Failure     404       {object} response.Response
+// @Failure     500       {object} response.Response
+// @Security    BearerAuth
+// @Router      /content/{type} [get]
+func (h *ContentHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	entries, total, err := h.svc.List(c.Param("type"), page, pageSize)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"items": entries,
+		"total": total,
+	})
+}
+
+// @Summary     Get content entry
+// @Description Get an entry of a custom content type by ID
+// @Tags        content
+// @Accept      json
+// @Produce     json
+// @Param       type path     string true "Content type name"
+// @Param       id   path     int    true "Entry ID"
+// @Success     200  {object} response.Response{data=model.ContentEntry}
+// @Failure     404  {object} response.Response
+// @Failure     500  {object} response.Response
+// @Security    BearerAuth
+// @Router      /content/{type}/{id} [get]
+func (h *ContentHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid entry id")
+		return
+	}
+
+	entry, err := h.svc.Get(c.Param("type"), uint(id))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, entry)
+}
+
+// @Summary     Update content entry
+// @Description Replace the data of a custom content entry
+// @Tags        content
+// @Accept      json
+// @Produce     json
+// @Param       type  path     string                      true "Content type name"
+// @Param       id    path     int                         true "Entry ID"
+// @Param       entry body     service.ContentEntryRequest true "Entry data"
+// @Success     200   {object} response.Response{data=model.ContentEntry}
+// @Failure     400   {object} response.Response{data=[]service.FieldError}
+// @Failure     404   {object} response.Response
+// @Failure     500   {object} response.Response
+// @Security    BearerAuth
+// @Router      /content/{type}/{id} [put]
+func (h *ContentHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid entry id")
+		return
+	}
+
+	var req service.ContentEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entry, err := h.svc.Update(c.Param("type"), uint(id), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, entry)
+}
+
+// @Summary     Delete content entry
+// @Description Delete an entry of a custom content type
+// @Tags        content
+// @Accept      json
+// @Produce     json
+// @Param       type path     string true "Content type name"
+// @Param       id   path     int    true "Entry ID"
+// @Success     200  {object} response.Response
+// @Failure     404  {object} response.Response
+// @Failure     500  {object} response.Response
+// @Security    BearerAuth
+// @Router      /content/{type}/{id} [delete]
+func (h *ContentHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid entry id")
+		return
+	}
+
+	if err := h.svc.Delete(c.Param("type"), uint(id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+func (h *ContentHandler) handleError(c *gin.Context, err error) {
+	var verr *service.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		response.ErrorWithData(c, http.StatusBadRequest, "invalid content entry", verr.Errors)
+	case err == service.ErrContentTypeNotFound, err == service.ErrContentEntryNotFound:
+		response.Error(c, http.StatusNotFound, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+	}
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type ContentTypeHandler struct {
+	svc *service.ContentTypeService
+}
+
+func NewContentTypeHandler(svc *service.ContentTypeService) *ContentTypeHandler {
+	return &ContentTypeHandler{svc: svc}
+}
+
+// @Summary     Create content type
+// @Description Define a new content type with its field schema
+// @Tags        content-types
+// @Accept      json
+// @Produce     json
+// @Param       content_type body     service.CreateContentTypeRequest true "Content type info"
+// @Success     200          {object} response.Response{data=model.ContentType}
+// @Failure     400          {object} response.Response{data=[]service.FieldError}
+// @Failure     500          {object} response.Response
+// @Security    BearerAuth
+// @Router      /content-types [post]
+func (h *ContentTypeHandler) Create(c *gin.Context) {
+	var req service.CreateContentTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	contentType, err := h.svc.Create(&req)
+	if err != nil {
+		var verr *service.ValidationError
+		switch {
+		case errors.As(err, &verr):
+			response.ErrorWithData(c, http.StatusBadRequest, "invalid content type", verr.Errors)
+		case err == service.ErrContentTypeExist:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, contentType)
+}
+
+// @Summary     List content types
+// @Description Get all content types
+// @Tags        content-types
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} response.Response{data=[]model.ContentType}
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /content-types [get]
+func (h *ContentTypeHandler) List(c *gin.Context) {
+	contentTypes, err := h.svc.List()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response.Success(c, contentTypes)
+}
+
+// @Summary     Get content type
+// @Description Get content type by ID
+// @Tags        content-types
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "Content type ID"
+// @Success     200 {object} response.Response{data=model.ContentType}
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /content-types/{id} [get]
+func (h *ContentTypeHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid content type id")
+		return
+	}
+
+	contentType, err := h.svc.Get(uint(id))
+	if err != nil {
+		switch err {
+		case service.ErrContentTypeNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, contentType)
+}
+
+// @Summary     Update content type
+// @Description Update label, description or field schema of a content type
+// @Tags        content-types
+// @Accept      json
+// @Produce     json
+// @Param       id           path     int                              true "Content type ID"
+// @Param       content_type body     service.UpdateContentTypeRequest true "Content type info"
+// @Success     200          {object} response.Response{data=model.ContentType}
+// @Failure     400          {object} response.Response{data=[]service.FieldError}
+// @Failure     404          {object} response.Response
+// @Failure     500          {object} response.Response
+// @Security    BearerAuth
+// @Router      /content-types/{id} [put]
+func (h *ContentTypeHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid content type id")
+		return
+	}
+
+	var req service.UpdateContentTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	contentType, err := h.svc.Update(uint(id), &req)
+	if err != nil {
+		var verr *service.ValidationError
+		switch {
+		case errors.As(err, &verr):
+			response.ErrorWithData(c, http.StatusBadRequest, "invalid content type", verr.Errors)
+		case err == service.ErrContentTypeNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, contentType)
+}
+
+// @Summary     Delete content type
+// @Description Delete a content type that has no entries left
+// @Tags        content-types
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "Content type ID"
+// @Success     200 {object} response.Response
+// @Failure     404 {object} response.Response
+// @Failure     409 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /content-types/{id} [delete]
+func (h *ContentTypeHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid content type id")
+		return
+	}
+
+	if err := h.svc.Delete(uint(id)); err != nil {
+		switch err {
+		case service.ErrContentTypeNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrContentTypeInUse:
+			response.Error(c, http.StatusConflict, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type EbookHandler struct {
+	svc *service.EbookService
+}
+
+func NewEbookHandler(svc *service.EbookService) *EbookHandler {
+	return &EbookHandler{svc: svc}
+}
+
+// @Summary     Export e-book
+// @Description Assemble the published public articles of a series or an explicit list into an EPUB 3 file, or a single print-optimized HTML document for PDF conversion
+// @Tags        ebooks
+// @Accept      json
+// @Produce     application/epub+zip,text/html
+// @Param       ebook body     service.EbookRequest true "Articles and metadata"
+// @Success     200   {file}   file
+// @Failure     400   {object} response.Response
+// @Failure     404   {object} response.Response
+// @Failure     500   {object} response.Response
+// @Security    BearerAuth
+// @Router      /ebooks [post]
+func (h *EbookHandler) Export(c *gin.Context) {
+	var req service.EbookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	file, err := h.svc.Build(&req)
+	if err != nil {
+		switch err {
+		case service.ErrEbookSource, service.ErrEbookFormat, service.ErrEbookEmpty, service.ErrEbookArticleCount:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		case service.ErrSeriesNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
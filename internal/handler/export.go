@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type ExportHandler struct {
+	svc *service.ExportService
+}
+
+func NewExportHandler(svc *service.ExportService) *ExportHandler {
+	return &ExportHandler{svc: svc}
+}
+
+// @Summary     Export static site
+// @Description Render published public articles, index pages, RSS and sitemap into the configured export directory. Unchanged articles are skipped unless full is set
+// @Tags        export
+// @Accept      json
+// @Produce     json
+// @Param       export body     service.ExportRequest false "Export options"
+// @Success     200    {object} response.Response{data=service.ExportResult}
+// @Failure     400    {object} response.Response
+// @Failure     409    {object} response.Response
+// @Failure     500    {object} response.Response
+// @Security    BearerAuth
+// @Router      /export [post]
+func (h *ExportHandler) Export(c *gin.Context) {
+	var req service.ExportRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	result, err := h.svc.Export(&req)
+	if err != nil {
+		switch err {
+		case service.ErrExportRunning:
+			response.Error(c, http.StatusConflict, err.Error())
+		default:
+			log.Printf("export failed: %v", err)
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+// maxImportSize 上传的导出文件大小上限
+const maxImportSize = 64 << 20
+
+type ImportHandler struct {
+	svc *service.ImportService
+}
+
+func NewImportHandler(svc *service.ImportService) *ImportHandler {
+	return &ImportHandler{svc: svc}
+}
+
+// @Summary     Import WordPress export
+// @Description Import users and posts from a WordPress WXR file. Posts whose author is not in the file are owned by the caller. Re-running with the same file skips everything imported before; dry_run only reports what would happen
+// @Tags        imports
+// @Accept      multipart/form-data
+// @Produce     json
+// @Param       file    formData file true  "WXR export file"
+// @Param       dry_run query    bool false "Only report, do not write"
+// @Success     200     {object} response.Response{data=service.ImportReport}
+// @Failure     400     {object} response.Response
+// @Failure     409     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Security    BearerAuth
+// @Router      /imports/wordpress [post]
+func (h *ImportHandler) WordPress(c *gin.Context) {
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	header, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "file is required")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	report, err := h.svc.ImportWordPress(file, c.GetUint("userID"), dryRun)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidWXR:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		case service.ErrImportRunning:
+			response.Error(c, http.StatusConflict, err.Error())
+		case service.ErrUserNotFound:
+			response.Error(c, http.StatusUnauthorized, "unauthorized")
+		default:
+			log.Printf("wordpress import failed: %v", err)
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, report)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/api/v1/users/oidc"
+)
+
+type OIDCHandler struct {
+	svc          *service.OIDCService
+	secureCookie bool
+}
+
+// NewOIDCHandler secureCookie 为 true 时 state cookie 只通过 HTTPS 发送
+func NewOIDCHandler(svc *service.OIDCService, secureCookie bool) *OIDCHandler {
+	return &OIDCHandler{svc: svc, secureCookie: secureCookie}
+}
+
+// @Summary     Start OIDC login
+// @Description Redirect to the identity provider to sign in. The login state is kept in a short-lived cookie
+// @Tags        users
+// @Produce     json
+// @Success     302
+// @Failure     502 {object} response.Response
+// @Router      /users/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authorization, err := h.svc.Begin(c.Request.Context())
+	if err != nil {
+		log.Printf("oidc: failed to start login: %v", err)
+		response.Error(c, http.StatusBadGateway, "identity provider is unavailable")
+		return
+	}
+
+	// 身份提供方通过顶层跳转回调，cookie 需要 SameSite=Lax 才会被带上
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, authorization.State, int(service.OIDCStateExpire.Seconds()),
+		oidcStateCookiePath, "", h.secureCookie, true)
+	c.Redirect(http.StatusFound, authorization.URL)
+}
+
+// @Summary     OIDC login callback
+// @Description Redirect target of the identity provider. Creates the user on first login when enabled,
+// @Description syncs mapped roles, and returns tokens, or a challenge token when two-factor is required
+// @Tags        users
+// @Produce     json
+// @Param       code  query    string false "Authorization code"
+// @Param       state query    string true  "State"
+// @Success     200   {object} response.Response{data=service.LoginResponse}
+// @Failure     400   {object} response.Response
+// @Failure     401   {object} response.Response
+// @Failure     403   {object} response.Response
+// @Failure     409   {object} response.Response
+// @Failure     500   {object} response.Response
+// @Router      /users/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var req service.OIDCCallbackRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// state cookie 只能使用一次
+	state, _ := c.Cookie(oidcStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcStateCookiePath, "", h.secureCookie, true)
+
+	resp, err := h.svc.Callback(c.Request.Context(), &req, state)
+	if err != nil {
+		switch err {
+		case service.ErrOIDCInvalidState, service.ErrOIDCMissingEmail:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		case service.ErrOIDCDenied, service.ErrOIDCAuthFailed:
+			response.Error(c, http.StatusUnauthorized, err.Error())
+		case service.ErrOIDCNotProvisioned, service.ErrUserInactive, service.ErrUserBanned:
+			response.Error(c, http.StatusForbidden, err.Error())
+		case service.ErrOIDCEmailConflict:
+			response.Error(c, http.StatusConflict, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, resp)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type PersonalTokenHandler struct {
+	svc *service.PersonalTokenService
+}
+
+func NewPersonalTokenHandler(svc *service.PersonalTokenService) *PersonalTokenHandler {
+	return &PersonalTokenHandler{svc: svc}
+}
+
+// @Summary     List personal access tokens
+// @Description List the personal access tokens of the current user, including expired ones. Requires a login session
+// @Tags        personal-tokens
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} response.Response{data=[]model.PersonalToken}
+// @Failure     403 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /users/tokens [get]
+func (h *PersonalTokenHandler) List(c *gin.Context) {
+	tokens, err := h.svc.List(c.GetUint("userID"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// @Summary     Create personal access token
+// @Description Create a token for automation, sent as "Authorization: Bearer pat_...". Scopes must be permissions the user has. The token is returned only once. Requires a login session
+// @Tags        personal-tokens
+// @Accept      json
+// @Produce     json
+// @Param       request body     service.CreatePersonalTokenRequest true "Token name, scopes and expiry"
+// @Success     200     {object} response.Response{data=service.PersonalTokenCreated}
+// @Failure     400     {object} response.Response
+// @Failure     403     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Security    BearerAuth
+// @Router      /users/tokens [post]
+func (h *PersonalTokenHandler) Create(c *gin.Context) {
+	var req service.CreatePersonalTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, err := h.svc.Create(c.GetUint("userID"), &req)
+	if err != nil {
+		var verr *service.ValidationError
+		if errors.As(err, &verr) {
+			response.ErrorWithData(c, http.StatusBadRequest, "invalid scopes", verr.Errors)
+			return
+		}
+		switch err {
+		case service.ErrUserNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, token)
+}
+
+// @Summary     Revoke personal access token
+// @Description Delete a personal access token of the current user. It stops working immediately. Requires a login session
+// @Tags        personal-tokens
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "Token ID"
+// @Success     200 {object} response.Response
+// @Failure     400 {object} response.Response
+// @Failure     403 {object} response.Response
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /users/tokens/{id} [delete]
+func (h *PersonalTokenHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid token id")
+		return
+	}
+
+	if err := h.svc.Revoke(c.GetUint("userID"), uint(id)); err != nil {
+		switch err {
+		case service.ErrPersonalTokenNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
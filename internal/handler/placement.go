@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type PlacementHandler struct {
+	svc *service.PlacementService
+}
+
+func NewPlacementHandler(svc *service.PlacementService) *PlacementHandler {
+	return &PlacementHandler{svc: svc}
+}
+
+// @Summary     List placements
+// @Description List all placements with their configured articles, including expired or unpublished ones
+// @Tags        placements
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} response.Response{data=[]service.Placement}
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /placements [get]
+func (h *PlacementHandler) List(c *gin.Context) {
+	placements, err := h.svc.List()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response.Success(c, placements)
+}
+
+// @Summary     Get placement
+// @Description Get the published articles pinned to a placement, in order. Expired, unpublished and deleted articles are left out
+// @Tags        placements
+// @Accept      json
+// @Produce     json
+// @Param       name path     string true "Placement name"
+// @Success     200  {object} response.Response{data=[]model.Article}
+// @Failure     400  {object} response.Response
+// @Failure     500  {object} response.Response
+// @Router      /placements/{name} [get]
+func (h *PlacementHandler) Get(c *gin.Context) {
+	articles, err := h.svc.Resolve(c.Param("name"), viewer(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, articles)
+}
+
+// @Summary     Set placement
+// @Description Replace the ordered list of articles pinned to a placement; an empty list clears it
+// @Tags        placements
+// @Accept      json
+// @Produce     json
+// @Param       name      path     string                      true "Placement name"
+// @Param       placement body     service.SetPlacementRequest true "Pinned articles"
+// @Success     200       {object} response.Response
+// @Failure     400       {object} response.Response
+// @Failure     500       {object} response.Response
+// @Security    BearerAuth
+// @Router      /placements/{name} [put]
+func (h *PlacementHandler) Set(c *gin.Context) {
+	var req service.SetPlacementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.svc.Set(c.Param("name"), &req); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// @Summary     Delete placement
+// @Description Remove all articles from a placement
+// @Tags        placements
+// @Accept      json
+// @Produce     json
+// @Param       name path     string true "Placement name"
+// @Success     200  {object} response.Response
+// @Failure     400  {object} response.Response
+// @Failure     500  {object} response.Response
+// @Security    BearerAuth
+// @Router      /placements/{name} [delete]
+func (h *PlacementHandler) Delete(c *gin.Context) {
+	if err := h.svc.Delete(c.Param("name")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+func (h *PlacementHandler) handleError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidPlacementName, service.ErrPlacementArticleInvalid, service.ErrPlacementExpiry:
+		response.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+	}
+}
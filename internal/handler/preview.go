@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type PreviewHandler struct {
+	svc *service.PreviewService
+}
+
+func NewPreviewHandler(svc *service.PreviewService) *PreviewHandler {
+	return &PreviewHandler{svc: svc}
+}
+
+// @Summary     Create preview link
+// @Description Issue a signed, expiring preview link for an article draft
+// @Tags        previews
+// @Accept      json
+// @Produce     json
+// @Param       id   path     int                              true "Article ID"
+// @Param       link body     service.CreatePreviewLinkRequest false "Link options"
+// @Success     200  {object} response.Response{data=service.PreviewLinkResponse}
+// @Failure     400  {object} response.Response
+// @Failure     403  {object} response.Response
+// @Failure     404  {object} response.Response
+// @Failure     500  {object} response.Response
+// @Security    BearerAuth
+// @Router      /articles/{id}/preview-links [post]
+func (h *PreviewHandler) Create(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	var req service.CreatePreviewLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	link, err := h.svc.CreateLink(uint(id), c.GetUint("userID"), &req)
+	if err != nil {
+		switch err {
+		case service.ErrArticleNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrInvalidExpiresIn:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		case service.ErrArticleForbidden:
+			response.Error(c, http.StatusForbidden, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, link)
+}
+
+// @Summary     List preview links
+// @Description List all preview links of an article
+// @Tags        previews
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "Article ID"
+// @Success     200 {object} response.Response{data=[]service.PreviewLinkResponse}
+// @Failure     403 {object} response.Response
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /articles/{id}/preview-links [get]
+func (h *PreviewHandler) List(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	links, err := h.svc.ListLinks(uint(id), c.GetUint("userID"))
+	if err != nil {
+		switch err {
+		case service.ErrArticleNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrArticleForbidden:
+			response.Error(c, http.StatusForbidden, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, links)
+}
+
+// @Summary     Revoke preview link
+// @Description Revoke a preview link so it can no longer be used
+// @Tags        previews
+// @Accept      json
+// @Produce     json
+// @Param       id      path     int true "Article ID"
+// @Param       link_id path     int true "Preview link ID"
+// @Success     200     {object} response.Response
+// @Failure     403     {object} response.Response
+// @Failure     404     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Security    BearerAuth
+// @Router      /articles/{id}/preview-links/{link_id} [delete]
+func (h *PreviewHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	linkID, err := strconv.ParseUint(c.Param("link_id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid preview link id")
+		return
+	}
+
+	if err := h.svc.RevokeLink(uint(id), uint(linkID), c.GetUint("userID")); err != nil {
+		switch err {
+		case service.ErrPreviewLinkNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrArticleForbidden:
+			response.Error(c, http.StatusForbidden, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// @Summary     List preview link accesses
+// @Description Get the access log of a preview link with pagination
+// @Tags        previews
+// @Accept      json
+// @Produce     json
+// @Param       id        path     int true  "Article ID"
+// @Param       link_id   path     int true  "Preview link ID"
+// @Param       page      query    int false "Page number"
+// @Param       page_size query    int false "Page size"
+// @Success     200       {object} response.Response{data=response.ListResponse{items=[]model.PreviewAccess}}
+// @Failure     403       {object} response.Response
+// @Failure     404       {object} response.Response
+// @Failure     500       {object} response.Response
+// @Security    BearerAuth
+// @Router      /articles/{id}/preview-links/{link_id}/accesses [get]
+func (h *PreviewHandler) ListAccesses(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	linkID, err := strconv.ParseUint(c.Param("link_id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid preview link id")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	accesses, total, err := h.svc.ListAccesses(uint(id), uint(linkID), c.GetUint("userID"), page, pageSize)
+	if err != nil {
+		switch err {
+		case service.ErrPreviewLinkNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrArticleForbidden:
+			response.Error(c, http.StatusForbidden, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{
+		"items": accesses,
+		"total": total,
+	})
+}
+
+// @Summary     View draft preview
+// @Description Read-only view of an article through a preview link, regardless of its status
+// @Tags        previews
+// @Accept      json
+// @Produce     json
+// @Param       token path     string true "Preview token"
+// @Success     200   {object} response.Response{data=model.Article}
+// @Failure     404   {object} response.Response
+// @Failure     500   {object} response.Response
+// @Router      /preview/{token} [get]
+func (h *PreviewHandler) View(c *gin.Context) {
+	article, err := h.svc.Resolve(c.Param("token"), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		switch err {
+		case service.ErrInvalidPreviewToken, service.ErrArticleNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	// 预览内容不允许被缓存或被搜索引擎收录
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+	response.Success(c, article)
+}
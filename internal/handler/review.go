@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type ReviewHandler struct {
+	svc *service.ReviewService
+}
+
+func NewReviewHandler(svc *service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{svc: svc}
+}
+
+// @Summary     List reviews
+// @Description List the reviewers and decisions of an article's current review round
+// @Tags        reviews
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "Article ID"
+// @Success     200 {object} response.Response{data=[]service.Review}
+// @Failure     403 {object} response.Response
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /articles/{id}/reviews [get]
+func (h *ReviewHandler) List(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	reviews, err := h.svc.List(uint(id), c.GetUint("userID"))
+	if err != nil {
+		switch err {
+		case service.ErrArticleNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrArticleForbidden:
+			response.Error(c, http.StatusForbidden, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, reviews)
+}
+
+// @Summary     Decide review
+// @Description Approve an article or request changes. Requesting changes moves the article back to draft
+// @Tags        reviews
+// @Accept      json
+// @Produce     json
+// @Param       id       path     int                           true "Article ID"
+// @Param       decision body     service.ReviewDecisionRequest true "Decision"
+// @Success     200      {object} response.Response{data=model.ArticleReview}
+// @Failure     400      {object} response.Response
+// @Failure     404      {object} response.Response
+// @Failure     409      {object} response.Response
+// @Failure     500      {object} response.Response
+// @Security    BearerAuth
+// @Router      /articles/{id}/reviews/decision [post]
+func (h *ReviewHandler) Decide(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	var req service.ReviewDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	review, err := h.svc.Decide(uint(id), c.GetUint("userID"), &req)
+	if err != nil {
+		switch err {
+		case service.ErrArticleNotFound, service.ErrReviewNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrInvalidReviewDecision:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		case service.ErrArticleNotInReview:
+			response.Error(c, http.StatusConflict, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, review)
+}
+
+// @Summary     My pending reviews
+// @Description List articles waiting for the current user's decision, earliest due first
+// @Tags        reviews
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} response.Response{data=[]service.PendingReview}
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /reviews/mine [get]
+func (h *ReviewHandler) Mine(c *gin.Context) {
+	reviews, err := h.svc.Mine(c.GetUint("userID"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response.Success(c, reviews)
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type SeriesHandler struct {
+	svc *service.SeriesService
+}
+
+func NewSeriesHandler(svc *service.SeriesService) *SeriesHandler {
+	return &SeriesHandler{svc: svc}
+}
+
+// @Summary     Create series
+// @Description Create a series with an ordered list of articles
+// @Tags        series
+// @Accept      json
+// @Produce     json
+// @Param       series body     service.CreateSeriesRequest true "Series info"
+// @Success     200    {object} response.Response{data=service.SeriesDetail}
+// @Failure     400    {object} response.Response
+// @Failure     403    {object} response.Response
+// @Failure     409    {object} response.Response
+// @Failure     500    {object} response.Response
+// @Security    BearerAuth
+// @Router      /series [post]
+func (h *SeriesHandler) Create(c *gin.Context) {
+	var req service.CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	series, err := h.svc.Create(c.GetUint("userID"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, series)
+}
+
+// @Summary     Get series
+// @Description Get series by ID with the published articles visible to the caller, in order
+// @Tags        series
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "Series ID"
+// @Success     200 {object} response.Response{data=service.SeriesDetail}
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Router      /series/{id} [get]
+func (h *SeriesHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid series id")
+		return
+	}
+
+	series, err := h.svc.Get(uint(id), viewer(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, series)
+}
+
+// @Summary     List series
+// @Description Get series with pagination
+// @Tags        series
+// @Accept      json
+// @Produce     json
+// @Param       page      query    int false "Page number"
+// @Param       page_size query    int false "Page size"
+// @Success     200       {object} response.Response{data=response.ListResponse{items=[]model.Series}}
+// @Failure     500       {object} response.Response
+// @Router      /series [get]
+func (h *SeriesHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	series, total, err := h.svc.List(page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"items": series,
+		"total": total,
+	})
+}
+
+// @Summary     Update series
+// @Description Update series title and description
+// @Tags        series
+// @Accept      json
+// @Produce     json
+// @Param       id     path     int                         true "Series ID"
+// @Param       series body     service.UpdateSeriesRequest true "Series info"
+// @Success     200    {object} response.Response{data=service.SeriesDetail}
+// @Failure     400    {object} response.Response
+// @Failure     403    {object} response.Response
+// @Failure     404    {object} response.Response
+// @Failure     500    {object} response.Response
+// @Security    BearerAuth
+// @Router      /series/{id} [put]
+func (h *SeriesHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid series id")
+		return
+	}
+
+	var req service.UpdateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	series, err := h.svc.Update(uint(id), c.GetUint("userID"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, series)
+}
+
+// @Summary     Reorder series articles
+// @Description Atomically replace the ordered article list of a series. The version read from the series must be sent back; a stale version returns 409.
+// @Tags        series
+// @Accept      json
+// @Produce     json
+// @Param       id    path     int                          true "Series ID"
+// @Param       order body     service.ReorderSeriesRequest true "New article order"
+// @Success     200   {object} response.Response{data=service.SeriesDetail}
+// @Failure     400   {object} response.Response
+// @Failure     403   {object} response.Response
+// @Failure     404   {object} response.Response
+// @Failure     409   {object} response.Response
+// @Failure     500   {object} response.Response
+// @Security    BearerAuth
+// @Router      /series/{id}/articles [put]
+func (h *SeriesHandler) Reorder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid series id")
+		return
+	}
+
+	var req service.ReorderSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	series, err := h.svc.Reorder(uint(id), c.GetUint("userID"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, series)
+}
+
+// @Summary     Delete series
+// @Description Delete a series; its articles are kept
+// @Tags        series
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "Series ID"
+// @Success     200 {object} response.Response
+// @Failure     403 {object} response.Response
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /series/{id} [delete]
+func (h *SeriesHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid series id")
+		return
+	}
+
+	if err := h.svc.Delete(uint(id), c.GetUint("userID")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+func (h *SeriesHandler) handleError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrSeriesNotFound:
+		response.Error(c, http.StatusNotFound, err.Error())
+	case service.ErrSeriesArticleInvalid:
+		response.Error(c, http.StatusBadRequest, err.Error())
+	case service.ErrSeriesForbidden, service.ErrArticleForbidden:
+		response.Error(c, http.StatusForbidden, err.Error())
+	case service.ErrSeriesVersionConflict, service.ErrArticleInOtherSeries:
+		response.Error(c, http.StatusConflict, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+	}
+}
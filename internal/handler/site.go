@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/theme"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+// 主题本身无法加载或渲染时返回的错误页，不依赖主题模板
+const fallbackErrorPage = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>服务器错误</title></head>
+<body><h1>服务器错误</h1><p>请稍后再试。</p></body></html>`
+
+// SiteHandler 服务端渲染的公开站点
+type SiteHandler struct {
+	svc    *service.SiteService
+	themes *theme.Loader
+}
+
+func NewSiteHandler(svc *service.SiteService, themes *theme.Loader) *SiteHandler {
+	return &SiteHandler{
+		svc:    svc,
+		themes: themes,
+	}
+}
+
+func (h *SiteHandler) Home(c *gin.Context) {
+	data, err := h.svc.Home(pageParam(c))
+	if err != nil {
+		h.renderError(c, err)
+		return
+	}
+	h.render(c, http.StatusOK, "index", data)
+}
+
+func (h *SiteHandler) Article(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.renderError(c, service.ErrArticleNotFound)
+		return
+	}
+
+	data, err := h.svc.Article(uint(id))
+	if err != nil {
+		h.renderError(c, err)
+		return
+	}
+	h.render(c, http.StatusOK, "article", data)
+}
+
+func (h *SiteHandler) Author(c *gin.Context) {
+	data, err := h.svc.Author(c.Param("username"), pageParam(c))
+	if err != nil {
+		h.renderError(c, err)
+		return
+	}
+	h.render(c, http.StatusOK, "author", data)
+}
+
+func (h *SiteHandler) Search(c *gin.Context) {
+	data, err := h.svc.Search(c.Query("q"), pageParam(c))
+	if err != nil {
+		h.renderError(c, err)
+		return
+	}
+	h.render(c, http.StatusOK, "search", data)
+}
+
+// Static 主题中的静态资源
+func (h *SiteHandler) Static(c *gin.Context) {
+	th, err := h.themes.Theme()
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.FileFromFS(c.Param("filepath"), http.FS(th.StaticFS()))
+}
+
+func (h *SiteHandler) render(c *gin.Context, status int, page string, data *theme.Page) {
+	th, err := h.themes.Theme()
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := th.Render(&buf, page, data); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func (h *SiteHandler) renderError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrArticleNotFound, service.ErrUserNotFound:
+		h.render(c, http.StatusNotFound, "error", h.svc.Error("页面不存在", ""))
+	case service.ErrArticleMembersOnly:
+		h.render(c, http.StatusUnauthorized, "error", h.svc.Error("仅会员可见", "这篇文章仅对登录会员开放。"))
+	case service.ErrArticleLocked:
+		h.render(c, http.StatusForbidden, "error", h.svc.Error("文章已加密", "这篇文章需要密码才能查看。"))
+	default:
+		log.Printf("site: %s: %v", c.Request.URL.Path, err)
+		h.render(c, http.StatusInternalServerError, "error", h.svc.Error("服务器错误", ""))
+	}
+}
+
+// fail 记录错误并返回不依赖主题的错误页，错误详情不展示给访问者
+func (h *SiteHandler) fail(c *gin.Context, err error) {
+	log.Printf("site: %s: %v", c.Request.URL.Path, err)
+	c.Data(http.StatusInternalServerError, "text/html; charset=utf-8", []byte(fallbackErrorPage))
+}
+
+func pageParam(c *gin.Context) int {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	return page
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type TwoFactorHandler struct {
+	svc *service.TwoFactorService
+}
+
+func NewTwoFactorHandler(svc *service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{svc: svc}
+}
+
+// @Summary     Two-factor status
+// @Description Whether two-factor authentication is enabled for, or required of, the current user. Requires a login session
+// @Tags        two-factor
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} response.Response{data=service.TwoFactorStatus}
+// @Failure     403 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /users/2fa [get]
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	status, err := h.svc.Status(c.GetUint("userID"))
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// @Summary     Set up two-factor
+// @Description Generate a new TOTP secret and its otpauth:// provisioning URI. Two-factor is enabled once confirmed with a code. Requires a login session
+// @Tags        two-factor
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} response.Response{data=service.TwoFactorSetup}
+// @Failure     403 {object} response.Response
+// @Failure     409 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /users/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	setup, err := h.svc.Setup(c.GetUint("userID"))
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, setup)
+}
+
+// @Summary     Confirm two-factor
+// @Description Enable two-factor with a code from the authenticator app. Returns the backup codes, which are shown only once. Requires a login session
+// @Tags        two-factor
+// @Accept      json
+// @Produce     json
+// @Param       request body     service.TwoFactorCodeRequest true "TOTP code"
+// @Success     200     {object} response.Response{data=service.BackupCodes}
+// @Failure     400     {object} response.Response
+// @Failure     401     {object} response.Response
+// @Failure     403     {object} response.Response
+// @Failure     409     {object} response.Response
+// @Failure     429     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Security    BearerAuth
+// @Router      /users/2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	var req service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	codes, err := h.svc.Confirm(c.GetUint("userID"), req.Code)
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, codes)
+}
+
+// @Summary     Disable two-factor
+// @Description Disable two-factor with a TOTP or backup code. Not allowed when a role of the user requires two-factor. Requires a login session
+// @Tags        two-factor
+// @Accept      json
+// @Produce     json
+// @Param       request body     service.TwoFactorCodeRequest true "TOTP or backup code"
+// @Success     200     {object} response.Response
+// @Failure     400     {object} response.Response
+// @Failure     401     {object} response.Response
+// @Failure     403     {object} response.Response
+// @Failure     429     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Security    BearerAuth
+// @Router      /users/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.svc.Disable(c.GetUint("userID"), req.Code); err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// @Summary     Regenerate backup codes
+// @Description Replace all backup codes with a new set. Requires a TOTP code and a login session
+// @Tags        two-factor
+// @Accept      json
+// @Produce     json
+// @Param       request body     service.TwoFactorCodeRequest true "TOTP code"
+// @Success     200     {object} response.Response{data=service.BackupCodes}
+// @Failure     400     {object} response.Response
+// @Failure     401     {object} response.Response
+// @Failure     403     {object} response.Response
+// @Failure     429     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Security    BearerAuth
+// @Router      /users/2fa/backup-codes [post]
+func (h *TwoFactorHandler) RegenerateBackupCodes(c *gin.Context) {
+	var req service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	codes, err := h.svc.RegenerateBackupCodes(c.GetUint("userID"), req.Code)
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, codes)
+}
+
+// writeTwoFactorError 两步验证相关接口共用的错误映射
+func writeTwoFactorError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidTwoFactorCode, service.ErrInvalidChallenge:
+		response.Error(c, http.StatusUnauthorized, err.Error())
+	case service.ErrTwoFactorLocked:
+		response.Error(c, http.StatusTooManyRequests, err.Error())
+	case service.ErrTwoFactorRequired:
+		response.Error(c, http.StatusForbidden, err.Error())
+	case service.ErrTwoFactorAlreadyEnabled:
+		response.Error(c, http.StatusConflict, err.Error())
+	case service.ErrTwoFactorNotEnabled, service.ErrTwoFactorNotSetUp:
+		response.Error(c, http.StatusBadRequest, err.Error())
+	case service.ErrUserNotFound:
+		response.Error(c, http.StatusNotFound, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+	}
+}
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
 	"github.com/wuwen/hello-go/internal/pkg/response"
 	"github.com/wuwen/hello-go/internal/service"
 )
@@ -36,6 +39,11 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 	user, err := h.svc.Register(&req)
 	if err != nil {
+		var verr *service.ValidationError
+		if errors.As(err, &verr) {
+			response.ErrorWithData(c, http.StatusBadRequest, "invalid password", verr.Errors)
+			return
+		}
 		switch err {
 		case service.ErrUserExist:
 			response.Error(c, http.StatusBadRequest, err.Error())
@@ -49,7 +57,8 @@ func (h *UserHandler) Register(c *gin.Context) {
 }
 
 // @Summary     Login user
-// @Description Login with username and password
+// @Description Login with username and password. When two-factor authentication is enabled or required,
+// @Description only a challenge token is returned; finish the login at /users/login/2fa
 // @Tags        users
 // @Accept      json
 // @Produce     json
@@ -57,6 +66,8 @@ func (h *UserHandler) Register(c *gin.Context) {
 // @Success     200  {object} response.Response{data=service.LoginResponse}
 // @Failure     400  {object} response.Response
 // @Failure     401  {object} response.Response
+// @Failure     403  {object} response.Response "Email not verified or user banned"
+// @Failure     429  {object} response.Response "Too many failed attempts, see Retry-After"
 // @Router      /users/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	var req service.LoginRequest
@@ -65,11 +76,19 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.svc.Login(&req)
+	resp, err := h.svc.Login(&req, c.ClientIP())
 	if err != nil {
+		var berr *service.LoginBlockedError
+		if errors.As(err, &berr) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(berr.RetryAfter.Seconds()))))
+			response.Error(c, http.StatusTooManyRequests, err.Error())
+			return
+		}
 		switch err {
 		case service.ErrInvalidAuth:
 			response.Error(c, http.StatusUnauthorized, err.Error())
+		case service.ErrUserInactive, service.ErrUserBanned:
+			response.Error(c, http.StatusForbidden, err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, "internal server error")
 		}
@@ -79,8 +98,310 @@ func (h *UserHandler) Login(c *gin.Context) {
 	response.Success(c, resp)
 }
 
+// @Summary     Complete two-factor login
+// @Description Exchange a login challenge token and a TOTP or backup code for access and refresh tokens.
+// @Description With a setup challenge, the code confirms the new authenticator and backup codes are returned
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       request body     service.TwoFactorLoginRequest true "Challenge token and code"
+// @Success     200     {object} response.Response{data=service.LoginResponse}
+// @Failure     400     {object} response.Response
+// @Failure     401     {object} response.Response
+// @Failure     429     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Router      /users/login/2fa [post]
+func (h *UserHandler) LoginTwoFactor(c *gin.Context) {
+	var req service.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.svc.LoginTwoFactor(&req)
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// @Summary     Set up two-factor during login
+// @Description For users whose role requires two-factor but who have not enabled it yet:
+// @Description generate a TOTP secret with the setup challenge token returned by login
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       request body     service.TwoFactorChallengeRequest true "Setup challenge token"
+// @Success     200     {object} response.Response{data=service.TwoFactorSetup}
+// @Failure     400     {object} response.Response
+// @Failure     401     {object} response.Response
+// @Failure     409     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Router      /users/login/2fa/setup [post]
+func (h *UserHandler) SetupTwoFactorLogin(c *gin.Context) {
+	var req service.TwoFactorChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	setup, err := h.svc.SetupTwoFactorLogin(&req)
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, setup)
+}
+
+// @Summary     Verify email
+// @Description Activate a newly registered user with the link sent by email
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       token query    string true "Verification token"
+// @Success     200   {object} response.Response{data=model.User}
+// @Failure     400   {object} response.Response
+// @Failure     500   {object} response.Response
+// @Router      /users/verify [get]
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	user, err := h.svc.VerifyEmail(c.Query("token"))
+	if err != nil {
+		switch err {
+		case service.ErrInvalidVerifyToken:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, user)
+}
+
+// @Summary     Resend verification email
+// @Description Send the verification email again. Responds the same whether or not the address is registered,
+// @Description and when the last email was sent too recently
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       request body     service.ResendVerificationRequest true "Email"
+// @Success     200     {object} response.Response
+// @Failure     400     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Router      /users/verify/resend [post]
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	var req service.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.svc.ResendVerification(&req); err != nil {
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// @Summary     Forgot password
+// @Description Email a one-time password reset token. Responds the same whether or not the address is registered
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       request body     service.ForgotPasswordRequest true "Email"
+// @Success     200     {object} response.Response
+// @Failure     400     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Router      /users/password/forgot [post]
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req service.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.svc.ForgotPassword(&req); err != nil {
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// @Summary     Reset password
+// @Description Set a new password with the token from the reset email. All existing sessions and personal access tokens are revoked
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       request body     service.ResetPasswordRequest true "Token and new password"
+// @Success     200     {object} response.Response
+// @Failure     400     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Router      /users/password/reset [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req service.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.svc.ResetPassword(&req); err != nil {
+		var verr *service.ValidationError
+		if errors.As(err, &verr) {
+			response.ErrorWithData(c, http.StatusBadRequest, "invalid password", verr.Errors)
+			return
+		}
+		switch err {
+		case service.ErrInvalidResetToken:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// @Summary     Refresh token
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token works once;
+// @Description reusing one revokes every token issued from the same login. Refreshing does not extend the login past refresh_expire_time
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       token body     service.RefreshTokenRequest true "Refresh token"
+// @Success     200   {object} response.Response{data=service.TokenPair}
+// @Failure     400   {object} response.Response
+// @Failure     401   {object} response.Response
+// @Failure     500   {object} response.Response
+// @Router      /users/token/refresh [post]
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req service.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokens, err := h.svc.RefreshToken(&req)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidRefreshToken, service.ErrRefreshTokenReused:
+			response.Error(c, http.StatusUnauthorized, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// @Summary     Logout
+// @Description Revoke the current access token, and the given refresh token if any
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       logout body     service.LogoutRequest false "Refresh token to revoke"
+// @Success     200    {object} response.Response
+// @Failure     400    {object} response.Response
+// @Failure     403    {object} response.Response
+// @Failure     500    {object} response.Response
+// @Security    BearerAuth
+// @Router      /users/logout [post]
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req service.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	claims, _ := c.Get("claims")
+	if err := h.svc.Logout(claims.(*auth.Claims), &req); err != nil {
+		switch err {
+		case service.ErrSessionRequired:
+			response.Error(c, http.StatusForbidden, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// @Summary     Unlock login
+// @Description Clear the failed login attempts of a user, lifting a lockout
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "User ID"
+// @Success     200 {object} response.Response
+// @Failure     400 {object} response.Response
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /users/{id}/lockout [delete]
+func (h *UserHandler) UnlockLogin(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	if err := h.svc.UnlockLogin(uint(id), c.GetUint("userID")); err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// @Summary     Revoke user sessions
+// @Description Revoke every access token, refresh token and personal access token of a user. Requires a login session
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "User ID"
+// @Success     200 {object} response.Response
+// @Failure     400 {object} response.Response
+// @Failure     403 {object} response.Response
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /users/{id}/sessions [delete]
+func (h *UserHandler) RevokeSessions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	if err := h.svc.RevokeSessions(uint(id)); err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
+
 // @Summary     Update user
-// @Description Update user info
+// @Description Update user info. Users can update only their own account, admins any account. Changing the email or password requires the caller's current_password; changing the password revokes all sessions of the user. Requires a login session
 // @Tags        users
 // @Accept      json
 // @Produce     json
@@ -88,6 +409,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 // @Param       user body     service.UpdateRequest true "User info"
 // @Success     200  {object} response.Response{data=model.User}
 // @Failure     400  {object} response.Response
+// @Failure     403  {object} response.Response
 // @Failure     404  {object} response.Response
 // @Failure     500  {object} response.Response
 // @Security    BearerAuth
@@ -105,11 +427,20 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
-	user, err := h.svc.UpdateUser(uint(id), &req)
+	user, err := h.svc.UpdateUser(c.GetUint("userID"), uint(id), &req)
 	if err != nil {
+		var verr *service.ValidationError
+		if errors.As(err, &verr) {
+			response.ErrorWithData(c, http.StatusBadRequest, "invalid password", verr.Errors)
+			return
+		}
 		switch err {
 		case service.ErrUserNotFound:
 			response.Error(c, http.StatusNotFound, err.Error())
+		case service.ErrInvalidCurrentPassword:
+			response.Error(c, http.StatusBadRequest, err.Error())
+		case service.ErrUserForbidden:
+			response.Error(c, http.StatusForbidden, err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, "internal server error")
 		}
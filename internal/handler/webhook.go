@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/response"
+	"github.com/wuwen/hello-go/internal/service"
+)
+
+type WebhookHandler struct {
+	svc *service.WebhookService
+}
+
+func NewWebhookHandler(svc *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{svc: svc}
+}
+
+// @Summary     Create webhook
+// @Description Subscribe a URL to content and user events. The signing secret is only returned once.
+// @Tags        webhooks
+// @Accept      json
+// @Produce     json
+// @Param       webhook body     service.CreateWebhookRequest true "Webhook info"
+// @Success     200     {object} response.Response{data=service.WebhookSecretResponse}
+// @Failure     400     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Security    BearerAuth
+// @Router      /webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req service.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webhook, err := h.svc.Create(&req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, webhook)
+}
+
+// @Summary     List webhooks
+// @Description Get all webhook subscriptions
+// @Tags        webhooks
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} response.Response{data=[]model.Webhook}
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /webhooks [get]
+func (h *WebhookHandler) List(c *gin.Context) {
+	webhooks, err := h.svc.List()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response.Success(c, webhooks)
+}
+
+// @Summary     Get webhook
+// @Description Get webhook subscription by ID
+// @Tags        webhooks
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "Webhook ID"
+// @Success     200 {object} response.Response{data=model.Webhook}
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /webhooks/{id} [get]
+func (h *WebhookHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	webhook, err := h.svc.Get(uint(id))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, webhook)
+}
+
+// @Summary     Update webhook
+// @Description Update webhook URL, event filter, secret or active flag
+// @Tags        webhooks
+// @Accept      json
+// @Produce     json
+// @Param       id      path     int                          true "Webhook ID"
+// @Param       webhook body     service.UpdateWebhookRequest true "Webhook info"
+// @Success     200     {object} response.Response{data=model.Webhook}
+// @Failure     400     {object} response.Response
+// @Failure     404     {object} response.Response
+// @Failure     500     {object} response.Response
+// @Security    BearerAuth
+// @Router      /webhooks/{id} [put]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	var req service.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webhook, err := h.svc.Update(uint(id), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, webhook)
+}
+
+// @Summary     Delete webhook
+// @Description Delete a webhook subscription and its delivery log
+// @Tags        webhooks
+// @Accept      json
+// @Produce     json
+// @Param       id  path     int true "Webhook ID"
+// @Success     200 {object} response.Response
+// @Failure     404 {object} response.Response
+// @Failure     500 {object} response.Response
+// @Security    BearerAuth
+// @Router      /webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	if err := h.svc.Delete(uint(id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// @Summary     List webhook deliveries
+// @Description Get the delivery log of a webhook with pagination
+// @Tags        webhooks
+// @Accept      json
+// @Produce     json
+// @Param       id        path     int true  "Webhook ID"
+// @Param       page      query    int false "Page number"
+// @Param       page_size query    int false "Page size"
+// @Success     200       {object} response.Response{data=response.ListResponse{items=[]model.WebhookDelivery}}
+// @Failure     404       {object} response.Response
+// @Failure     500       {object} response.Response
+// @Security    BearerAuth
+// @Router      /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	deliveries, total, err := h.svc.ListDeliveries(uint(id), page, pageSize)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"items": deliveries,
+		"total": total,
+	})
+}
+
+// @Summary     Replay webhook delivery
+// @Description Queue a new delivery with the same payload as an earlier one
+// @Tags        webhooks
+// @Accept      json
+// @Produce     json
+// @Param       id          path     int true "Webhook ID"
+// @Param       delivery_id path     int true "Delivery ID"
+// @Success     200         {object} response.Response{data=model.WebhookDelivery}
+// @Failure     404         {object} response.Response
+// @Failure     500         {object} response.Response
+// @Security    BearerAuth
+// @Router      /webhooks/{id}/deliveries/{delivery_id}/replay [post]
+func (h *WebhookHandler) Replay(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	deliveryID, err := strconv.ParseUint(c.Param("delivery_id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid delivery id")
+		return
+	}
+
+	delivery, err := h.svc.Replay(uint(id), uint(deliveryID))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, delivery)
+}
+
+func (h *WebhookHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case err == service.ErrWebhookNotFound, err == service.ErrDeliveryNotFound:
+		response.Error(c, http.StatusNotFound, err.Error())
+	case err == service.ErrInvalidWebhook, errors.Is(err, service.ErrInvalidEvent):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "internal server error")
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -9,31 +10,74 @@ import (
 	"github.com/wuwen/hello-go/internal/pkg/response"
 )
 
+var (
+	errMissingAuthHeader = errors.New("authorization header is required")
+	errInvalidAuthHeader = errors.New("invalid authorization header format")
+	errSessionRequired   = errors.New("this action requires a login session, personal access tokens are not accepted")
+)
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			response.Error(c, http.StatusUnauthorized, "authorization header is required")
+		token, err := bearerToken(c)
+		if err != nil {
+			response.Error(c, http.StatusUnauthorized, err.Error())
 			c.Abort()
 			return
 		}
 
-		parts := strings.SplitN(authHeader, " ", 2)
-		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			response.Error(c, http.StatusUnauthorized, "invalid authorization header format")
+		claims, err := auth.Authenticate(token)
+		if err != nil {
+			if err == auth.ErrTokenRevoked {
+				response.Error(c, http.StatusUnauthorized, err.Error())
+			} else {
+				response.Error(c, http.StatusUnauthorized, "invalid or expired token")
+			}
 			c.Abort()
 			return
 		}
 
-		userID, err := auth.ParseToken(parts[1])
-		if err != nil {
-			response.Error(c, http.StatusUnauthorized, "invalid or expired token")
+		// 将用户ID及 token 信息保存到上下文，注销时需要 token 的 jti
+		c.Set("userID", claims.UserID)
+		c.Set("claims", claims)
+		c.Next()
+	}
+}
+
+// SessionMiddleware 在 AuthMiddleware 之后使用，拒绝个人访问 token。
+// 用于修改账号凭据、token 和会话的接口，泄露的 token 不能借此扩大或延续权限
+func SessionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, ok := c.Get("claims"); ok && claims.(*auth.Claims).Personal() {
+			response.Error(c, http.StatusForbidden, errSessionRequired.Error())
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
 
-		// 将用户ID保存到上下文
-		c.Set("userID", userID)
+// OptionalAuthMiddleware 用于公开接口：携带有效 token 时识别用户，否则按匿名访问处理
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := bearerToken(c)
+		if err == nil {
+			if userID, err := auth.ParseToken(token); err == nil {
+				c.Set("userID", userID)
+			}
+		}
 		c.Next()
 	}
 }
+
+func bearerToken(c *gin.Context) (string, error) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return "", errMissingAuthHeader
+	}
+
+	parts := strings.SplitN(authHeader, " ", 2)
+	if !(len(parts) == 2 && parts[0] == "Bearer") {
+		return "", errInvalidAuthHeader
+	}
+	return parts[1], nil
+}
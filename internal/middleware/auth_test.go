@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+)
+
+func TestSessionMiddlewareRejectsPersonalTokens(t *testing.T) {
+	r := newTestRouter(t)
+	login, err := auth.GenerateToken(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 个人访问 token 的 scope 包含该接口，仍然被拒绝
+	for token, want := range map[string]int{login: http.StatusOK, testPersonalToken: http.StatusForbidden} {
+		req := httptest.NewRequest("PUT", "/api/v1/users/1", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("PUT /api/v1/users/1 with %.4s token = %d %s, want %d", token, w.Code, w.Body.String(), want)
+		}
+	}
+}
@@ -2,33 +2,44 @@ package middleware
 
 import (
 	"net/http"
-	"strings"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
 	"github.com/wuwen/hello-go/internal/pkg/response"
 )
 
-func CasbinMiddleware(enforcer *casbin.Enforcer) gin.HandlerFunc {
+// SubjectFunc 返回用户在 casbin 策略中的主体，例如 user:<用户名>
+type SubjectFunc func(userID uint) (string, error)
+
+// CasbinMiddleware 在 AuthMiddleware 之后使用，按请求的完整路径和方法鉴权
+func CasbinMiddleware(enforcer *casbin.Enforcer, subject SubjectFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// 个人访问 token 只能访问创建时指定的接口
+		if claims, ok := c.Get("claims"); ok {
+			if !claims.(*auth.Claims).Allows(c.Request.Method, c.Request.URL.Path) {
+				response.Error(c, http.StatusForbidden, "token scope does not allow this request")
+				c.Abort()
+				return
+			}
+		}
+
 		// 获取当前请求的用户
-		user, exists := c.Get("user")
-		if !exists {
+		userID := c.GetUint("userID")
+		if userID == 0 {
+			response.Error(c, http.StatusUnauthorized, "Unauthorized")
+			c.Abort()
+			return
+		}
+		sub, err := subject(userID)
+		if err != nil {
 			response.Error(c, http.StatusUnauthorized, "Unauthorized")
 			c.Abort()
 			return
 		}
-		username := user.(string)
-
-		// 获取请求的路径和方法
-		obj := c.Request.URL.Path
-		act := c.Request.Method
-
-		// 移除路径中的 /api/v1 前缀
-		obj = strings.TrimPrefix(obj, "/api/v1")
 
-		// 执行 Casbin 鉴权
-		ok, err := enforcer.Enforce(username, obj, act)
+		// 执行 Casbin 鉴权，策略中的路径带 /api/v1 前缀
+		ok, err := enforcer.Enforce(sub, c.Request.URL.Path, c.Request.Method)
 		if err != nil {
 			response.Error(c, http.StatusInternalServerError, "Internal Server Error")
 			c.Abort()
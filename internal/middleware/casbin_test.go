@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+)
+
+const testPersonalToken = "pat_test"
+
+type testPersonalTokens map[string]*auth.Claims
+
+func (s testPersonalTokens) AuthenticatePersonalToken(token string) (*auth.Claims, error) {
+	if claims, ok := s[token]; ok {
+		return claims, nil
+	}
+	return nil, auth.ErrInvalidToken
+}
+
+// newTestRouter 按 app 的方式组装认证和鉴权中间件，用户 1 为 alice，拥有 user 角色
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	auth.Initialize("test-secret", time.Minute)
+	auth.SetPersonalTokenStore(testPersonalTokens{
+		testPersonalToken: {UserID: 1, PersonalTokenID: 7, Scopes: []string{"PUT /api/v1/articles/:id", "PUT /api/v1/users/:id"}},
+	})
+	t.Cleanup(func() { auth.SetPersonalTokenStore(nil) })
+
+	enforcer, err := casbin.NewEnforcer("../../configs/model.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range [][]string{
+		{"role:user", "/api/v1/articles/:id", "PUT"},
+		{"role:user", "/api/v1/articles/:id/submit", "POST"},
+		{"role:user", "/api/v1/users/:id", "DELETE"},
+		{"role:user", "/api/v1/users/:id", "PUT"},
+		{"role:admin", "/api/v1/users/:id/sessions", "DELETE"},
+	} {
+		if _, err := enforcer.AddPolicy(p[0], p[1], p[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := enforcer.AddGroupingPolicy("user:alice", "role:user"); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := func(userID uint) (string, error) {
+		if userID == 1 {
+			return "user:alice", nil
+		}
+		return "", errors.New("user not found")
+	}
+	r := gin.New()
+	group := r.Group("/api/v1", AuthMiddleware(), CasbinMiddleware(enforcer, subject))
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+	group.PUT("/articles/:id", ok)
+	group.POST("/articles/:id/submit", ok)
+	group.DELETE("/users/:id", ok)
+	group.PUT("/users/:id", SessionMiddleware(), ok)
+	group.DELETE("/users/:id/sessions", ok)
+	return r
+}
+
+func TestCasbinMiddleware(t *testing.T) {
+	r := newTestRouter(t)
+	login, err := auth.GenerateToken(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown, err := auth.GenerateToken(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, token, method, path string
+		want                      int
+	}{
+		{"login token on allowed route", login, "PUT", "/api/v1/articles/3", http.StatusOK},
+		{"login token on another allowed route", login, "POST", "/api/v1/articles/3/submit", http.StatusOK},
+		{"policy segment does not cover sub-paths", login, "DELETE", "/api/v1/users/3/sessions", http.StatusForbidden},
+		{"personal token within scope", testPersonalToken, "PUT", "/api/v1/articles/3", http.StatusOK},
+		{"personal token outside scope", testPersonalToken, "POST", "/api/v1/articles/3/submit", http.StatusForbidden},
+		{"personal token on route the user may use", testPersonalToken, "DELETE", "/api/v1/users/3", http.StatusForbidden},
+		{"unknown user", unknown, "PUT", "/api/v1/articles/3", http.StatusUnauthorized},
+		{"no token", "", "PUT", "/api/v1/articles/3", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("%s %s = %d %s, want %d", tt.method, tt.path, w.Code, w.Body.String(), tt.want)
+			}
+		})
+	}
+}
@@ -2,13 +2,72 @@ package model
 
 import (
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 文章状态
+const (
+	ArticleStatusDraft     = 1 // 草稿
+	ArticleStatusPublished = 2 // 已发布
+	ArticleStatusInReview  = 3 // 审核中
+)
+
+// 文章可见性
+const (
+	ArticleVisibilityPublic   = "public"   // 所有人可见
+	ArticleVisibilityMembers  = "members"  // 登录用户可见
+	ArticleVisibilityPassword = "password" // 凭密码查看
+	ArticleVisibilityPrivate  = "private"  // 不在公开接口中展示
 )
 
 type Article struct {
-	ID        uint      `gorm:"primarykey" json:"id" example:"1"`
-	CreatedAt time.Time `json:"created_at" example:"2024-07-20T10:00:00Z"`
-	UpdatedAt time.Time `json:"updated_at" example:"2024-07-20T10:00:00Z"`
-	Title     string    `gorm:"size:200;not null" json:"title" example:"文章标题"`
-	Content   string    `gorm:"type:text" json:"content" example:"文章内容"`
-	Status    int       `gorm:"default:1" json:"status" example:"1"` // 1:draft 2:published
+	ID           uint       `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt    time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UpdatedAt    time.Time  `json:"updated_at" example:"2024-07-20T10:00:00Z"`
+	Title        string     `gorm:"size:200;not null" json:"title" example:"文章标题"`
+	Excerpt      string     `gorm:"size:500" json:"excerpt" example:"文章摘要"`
+	Content      string     `gorm:"type:text" json:"content" example:"文章内容"`
+	Status       int        `gorm:"default:1" json:"status" example:"1"` // 1:draft 2:published
+	PublishedAt  *time.Time `json:"published_at,omitempty" example:"2024-07-20T10:00:00Z"`
+	Visibility   string     `gorm:"size:20;not null;default:public" json:"visibility" example:"public"`
+	PasswordHash string     `gorm:"size:100" json:"-"`
+	SEO          ArticleSEO `gorm:"embedded;embeddedPrefix:seo_" json:"seo"`
+}
+
+// ArticleSEO 文章的搜索引擎及社交分享元数据，为空的字段在输出时使用默认值
+type ArticleSEO struct {
+	MetaTitle       string `gorm:"size:200" json:"meta_title" example:"文章标题"`
+	MetaDescription string `gorm:"size:500" json:"meta_description" example:"文章描述"`
+	CanonicalURL    string `gorm:"size:500" json:"canonical_url" example:"https://example.com/articles/1"`
+	OGImage         string `gorm:"size:500" json:"og_image" example:"https://example.com/uploads/cover.png"`
+	Robots          string `gorm:"size:100" json:"robots" example:"index,follow"`
+}
+
+// ValidVisibility 判断可见性取值是否合法
+func ValidVisibility(visibility string) bool {
+	switch visibility {
+	case ArticleVisibilityPublic, ArticleVisibilityMembers, ArticleVisibilityPassword, ArticleVisibilityPrivate:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetPassword 设置查看密码
+func (a *Article) SetPassword(password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	a.PasswordHash = string(hashedPassword)
+	return nil
+}
+
+// ValidatePassword 验证查看密码
+func (a *Article) ValidatePassword(password string) bool {
+	if a.PasswordHash == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(a.PasswordHash), []byte(password)) == nil
 }
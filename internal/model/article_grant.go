@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// 文章协作角色，权限依次递增
+const (
+	GrantRoleViewer = "viewer"
+	GrantRoleEditor = "editor"
+	GrantRoleOwner  = "owner"
+)
+
+// GrantRoleRank 返回角色的权限等级，未知角色为 0
+func GrantRoleRank(role string) int {
+	switch role {
+	case GrantRoleViewer:
+		return 1
+	case GrantRoleEditor:
+		return 2
+	case GrantRoleOwner:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// ArticleGrant 用户在单篇文章上的协作权限
+type ArticleGrant struct {
+	ID        uint      `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt time.Time `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-07-20T10:00:00Z"`
+	ArticleID uint      `gorm:"not null;uniqueIndex:idx_article_user" json:"article_id" example:"1"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_article_user;index" json:"user_id" example:"1"`
+	Role      string    `gorm:"size:20;not null" json:"role" example:"editor"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+// CalendarFeed 用户的日历订阅凭证，只保存 token 的哈希
+type CalendarFeed struct {
+	ID             uint       `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt      time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UpdatedAt      time.Time  `json:"updated_at" example:"2024-07-20T10:00:00Z"`
+	UserID         uint       `gorm:"not null;uniqueIndex" json:"user_id" example:"1"`
+	TokenHash      string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ContentFieldType 自定义内容字段类型
+type ContentFieldType string
+
+const (
+	FieldTypeString    ContentFieldType = "string"
+	FieldTypeText      ContentFieldType = "text"
+	FieldTypeNumber    ContentFieldType = "number"
+	FieldTypeDate      ContentFieldType = "date"
+	FieldTypeBool      ContentFieldType = "bool"
+	FieldTypeReference ContentFieldType = "reference"
+	FieldTypeMedia     ContentFieldType = "media"
+)
+
+// ReferenceArticle 引用字段指向文章时使用的目标名
+const ReferenceArticle = "article"
+
+// Valid 判断字段类型是否受支持
+func (t ContentFieldType) Valid() bool {
+	switch t {
+	case FieldTypeString, FieldTypeText, FieldTypeNumber, FieldTypeDate,
+		FieldTypeBool, FieldTypeReference, FieldTypeMedia:
+		return true
+	default:
+		return false
+	}
+}
+
+// ContentField 内容类型中的字段定义
+type ContentField struct {
+	Name      string           `json:"name" example:"starts_at"`
+	Label     string           `json:"label" example:"开始时间"`
+	Type      ContentFieldType `json:"type" example:"date"`
+	Required  bool             `json:"required" example:"true"`
+	Reference string           `json:"reference,omitempty" example:"article"` // reference 字段指向的内容类型
+}
+
+// ContentFields 以 JSON 形式存储的字段列表
+type ContentFields []ContentField
+
+func (f ContentFields) Value() (driver.Value, error) {
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (f *ContentFields) Scan(value interface{}) error {
+	return scanJSON(value, f)
+}
+
+// ContentData 以 JSON 形式存储的条目数据
+type ContentData map[string]interface{}
+
+func (d ContentData) Value() (driver.Value, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *ContentData) Scan(value interface{}) error {
+	return scanJSON(value, d)
+}
+
+// StringList 以 JSON 形式存储的字符串列表
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	return scanJSON(value, l)
+}
+
+func scanJSON(value interface{}, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return fmt.Errorf("unsupported JSON column type %T", value)
+	}
+}
+
+// ContentType 自定义内容类型
+type ContentType struct {
+	ID          uint          `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt   time.Time     `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UpdatedAt   time.Time     `json:"updated_at" example:"2024-07-20T10:00:00Z"`
+	Name        string        `gorm:"size:50;not null;uniqueIndex" json:"name" example:"event"`
+	Label       string        `gorm:"size:100" json:"label" example:"活动"`
+	Description string        `gorm:"size:255" json:"description" example:"线下活动"`
+	Fields      ContentFields `gorm:"type:text" json:"fields"`
+}
+
+// Field 按名称查找字段定义
+func (t *ContentType) Field(name string) (ContentField, bool) {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return ContentField{}, false
+}
+
+// ContentEntry 自定义内容条目
+type ContentEntry struct {
+	ID            uint        `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt     time.Time   `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UpdatedAt     time.Time   `json:"updated_at" example:"2024-07-20T10:00:00Z"`
+	ContentTypeID uint        `gorm:"not null;index" json:"content_type_id" example:"1"`
+	Data          ContentData `gorm:"type:text" json:"data"`
+}
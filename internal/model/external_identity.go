@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+// ExternalIdentity 用户在外部身份提供方（OIDC）的身份，issuer 与 subject 唯一确定一个外部账号
+type ExternalIdentity struct {
+	ID          uint       `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UserID      uint       `gorm:"not null;index" json:"user_id" example:"1"`
+	Issuer      string     `gorm:"size:255;not null;uniqueIndex:idx_external_identity" json:"issuer" example:"https://id.example.com"`
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:idx_external_identity" json:"subject" example:"248289761001"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
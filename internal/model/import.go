@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// 导入记录的对象类型
+const (
+	ImportKindUser    = "user"
+	ImportKindArticle = "article"
+)
+
+// ImportMapping 记录外部系统中的对象导入后对应的本地对象，重复导入时据此跳过
+type ImportMapping struct {
+	ID         uint      `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt  time.Time `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	Source     string    `gorm:"size:200;not null;uniqueIndex:idx_import_key" json:"source" example:"wordpress:https://blog.example.com"`
+	Kind       string    `gorm:"size:20;not null;uniqueIndex:idx_import_key" json:"kind" example:"article"`
+	ExternalID string    `gorm:"size:50;not null;uniqueIndex:idx_import_key" json:"external_id" example:"42"`
+	TargetID   uint      `gorm:"not null" json:"target_id" example:"1"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import (
+	"time"
+)
+
+// LoginFailure 按用户名或 IP 统计的连续登录失败，Target 形如 user:<用户名> 或 ip:<地址>
+type LoginFailure struct {
+	ID           uint       `gorm:"primarykey" json:"-"`
+	Target       string     `gorm:"size:150;not null;uniqueIndex" json:"target" example:"user:testuser"`
+	Failures     int        `gorm:"not null;default:0" json:"failures" example:"3"`
+	LastFailedAt time.Time  `gorm:"not null;index" json:"last_failed_at" example:"2024-07-20T10:00:00Z"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty" example:"2024-07-20T10:15:00Z"`
+}
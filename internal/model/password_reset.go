@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+// PasswordReset 找回密码的一次性 token，只保存哈希
+type PasswordReset struct {
+	ID        uint       `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UserID    uint       `gorm:"not null;index" json:"user_id" example:"1"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at" example:"2024-07-20T11:00:00Z"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+)
+
+// PersonalToken 个人访问 token，供 CI 等自动化场景调用 API，只保存 token 的哈希
+type PersonalToken struct {
+	ID         uint       `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt  time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UserID     uint       `gorm:"not null;index" json:"user_id" example:"1"`
+	Name       string     `gorm:"size:100;not null" json:"name" example:"ci-publish"`
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Hint       string     `gorm:"size:16" json:"hint" example:"pat_3f2a…9c1d"` // 用于辨认 token，不能用来认证
+	Scopes     StringList `gorm:"type:text" json:"scopes"`                     // 允许访问的接口，格式为 "METHOD path"
+	ExpiresAt  *time.Time `gorm:"index" json:"expires_at,omitempty"`           // 为空表示不过期
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// Expired 判断 token 是否已过期
+func (t *PersonalToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
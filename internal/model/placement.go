@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+)
+
+// PlacementItem 固定在某个展示位（如 home-hero、sidebar）上的文章
+type PlacementItem struct {
+	ID        uint       `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	Placement string     `gorm:"size:50;not null;uniqueIndex:idx_placement_position;uniqueIndex:idx_placement_article" json:"placement" example:"home-hero"`
+	Position  int        `gorm:"not null;uniqueIndex:idx_placement_position" json:"position" example:"1"`
+	ArticleID uint       `gorm:"not null;uniqueIndex:idx_placement_article;index" json:"article_id" example:"1"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2024-07-27T10:00:00Z"` // 为空表示长期有效
+}
+
+// Expired 判断是否已过期
+func (p *PlacementItem) Expired(now time.Time) bool {
+	return p.ExpiresAt != nil && !p.ExpiresAt.After(now)
+}
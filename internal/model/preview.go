@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+)
+
+// PreviewLink 草稿预览链接
+type PreviewLink struct {
+	ID             uint       `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt      time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UpdatedAt      time.Time  `json:"updated_at" example:"2024-07-20T10:00:00Z"`
+	ArticleID      uint       `gorm:"not null;index" json:"article_id" example:"1"`
+	CreatedBy      uint       `json:"created_by" example:"1"`
+	Note           string     `gorm:"size:200" json:"note" example:"外部审稿"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at" example:"2024-07-23T10:00:00Z"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	AccessCount    int64      `gorm:"default:0" json:"access_count" example:"0"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+}
+
+// Active 链接未撤销且未过期
+func (l *PreviewLink) Active(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// PreviewAccess 预览链接访问记录
+type PreviewAccess struct {
+	ID            uint      `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt     time.Time `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	PreviewLinkID uint      `gorm:"not null;index" json:"preview_link_id" example:"1"`
+	IP            string    `gorm:"size:64" json:"ip" example:"127.0.0.1"`
+	UserAgent     string    `gorm:"size:255" json:"user_agent" example:"Mozilla/5.0"`
+}
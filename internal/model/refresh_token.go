@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+// RefreshToken 刷新 token，只保存哈希。每次刷新后原 token 作废并签发同一 family 的新 token，
+// 已作废的 token 再次被使用说明发生了泄露，整个 family 随之失效
+type RefreshToken struct {
+	ID        uint       `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UserID    uint       `gorm:"not null;index" json:"user_id" example:"1"`
+	FamilyID  string     `gorm:"size:32;not null;index" json:"family_id"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at" example:"2024-08-19T10:00:00Z"` // family 的绝对过期时间，轮换时沿用
+	UsedAt    *time.Time `json:"used_at,omitempty"`                                               // 已轮换
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+// 审核结论
+const (
+	ReviewDecisionPending          = "pending"
+	ReviewDecisionApproved         = "approved"
+	ReviewDecisionChangesRequested = "changes_requested"
+)
+
+// ArticleReview 文章提交审核时指派的审核人及其结论，每次重新提交时重置
+type ArticleReview struct {
+	ID         uint       `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt  time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UpdatedAt  time.Time  `json:"updated_at" example:"2024-07-20T10:00:00Z"`
+	ArticleID  uint       `gorm:"not null;uniqueIndex:idx_article_reviewer" json:"article_id" example:"1"`
+	ReviewerID uint       `gorm:"not null;uniqueIndex:idx_article_reviewer;index" json:"reviewer_id" example:"2"`
+	AssignedBy uint       `json:"assigned_by" example:"1"`
+	Decision   string     `gorm:"size:20;not null;default:pending" json:"decision" example:"pending"`
+	Comment    string     `gorm:"type:text" json:"comment" example:"第二段需要补充数据来源"`
+	DueAt      *time.Time `json:"due_at,omitempty" example:"2024-07-22T10:00:00Z"`
+	DecidedAt  *time.Time `json:"decided_at,omitempty"`
+}
@@ -10,4 +10,6 @@ type Role struct {
 	CreatedAt time.Time `json:"created_at" example:"2024-07-20T10:00:00Z"`
 	UpdatedAt time.Time `json:"updated_at" example:"2024-07-20T10:00:00Z"`
 	Name      string    `gorm:"size:50;not null;uniqueIndex" json:"name" example:"admin"`
+	// 拥有该角色的用户必须启用两步验证才能登录
+	RequireTwoFactor bool `gorm:"not null;default:false" json:"require_two_factor" example:"true"`
 }
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+)
+
+// Series 由多篇文章按顺序组成的系列
+type Series struct {
+	ID          uint      `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt   time.Time `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UpdatedAt   time.Time `json:"updated_at" example:"2024-07-20T10:00:00Z"`
+	Title       string    `gorm:"size:200;not null" json:"title" example:"Go 入门教程"`
+	Description string    `gorm:"type:text" json:"description" example:"从零开始学习 Go"`
+	OwnerID     uint      `gorm:"index" json:"owner_id" example:"1"`             // 创建者，为 0 时只有管理员可以管理
+	Version     int       `gorm:"not null;default:1" json:"version" example:"1"` // 乐观锁版本号，每次调整文章顺序时递增
+}
+
+// SeriesPart 系列中的一篇文章，一篇文章最多属于一个系列
+type SeriesPart struct {
+	ID        uint `gorm:"primarykey" json:"id" example:"1"`
+	SeriesID  uint `gorm:"not null;uniqueIndex:idx_series_position" json:"series_id" example:"1"`
+	Position  int  `gorm:"not null;uniqueIndex:idx_series_position" json:"position" example:"1"`
+	ArticleID uint `gorm:"not null;uniqueIndex" json:"article_id" example:"1"`
+}
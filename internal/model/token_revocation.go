@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+)
+
+// TokenRevocation 访问 token 的撤销记录。JTI 非空时撤销单个 token，
+// 否则撤销用户在 IssuedBefore 之前签发的全部 token。
+// 过了 ExpiresAt 后相关 token 已自然过期，记录可以删除
+type TokenRevocation struct {
+	ID           uint       `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt    time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	JTI          string     `gorm:"column:jti;size:32;index" json:"jti,omitempty"`
+	UserID       uint       `gorm:"not null;index" json:"user_id" example:"1"`
+	IssuedBefore *time.Time `json:"issued_before,omitempty"`
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expires_at" example:"2024-07-20T10:15:00Z"`
+}
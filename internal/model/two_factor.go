@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// TwoFactor 用户的 TOTP 两步验证。EnabledAt 为空表示已生成密钥但尚未用验证码确认
+type TwoFactor struct {
+	ID             uint       `gorm:"primarykey" json:"-"`
+	CreatedAt      time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UpdatedAt      time.Time  `json:"updated_at" example:"2024-07-20T10:00:00Z"`
+	UserID         uint       `gorm:"not null;uniqueIndex" json:"user_id" example:"1"`
+	Secret         string     `gorm:"size:64;not null" json:"-"`
+	EnabledAt      *time.Time `json:"enabled_at,omitempty"`
+	LastCounter    int64      `gorm:"not null;default:0" json:"-"` // 最近一次使用的时间步，防止验证码重放
+	FailedAttempts int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil    *time.Time `json:"-"`
+}
+
+// BackupCode 两步验证的备用恢复码，只保存哈希，每个只能使用一次
+type BackupCode struct {
+	ID        uint       `gorm:"primarykey" json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
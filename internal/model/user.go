@@ -38,6 +38,9 @@ type User struct {
 	Password  string     `gorm:"size:100;not null" json:"-"`
 	Email     string     `gorm:"size:100;uniqueIndex" json:"email" example:"test@example.com"`
 	Status    UserStatus `gorm:"default:1" json:"status" example:"1"`
+	Locale    string     `gorm:"size:16" json:"locale,omitempty" example:"zh-CN"` // 邮件等通知使用的语言
+	// 最近一次发送验证邮件的时间，用于限制重发频率
+	VerificationSentAt *time.Time `json:"-"`
 }
 
 // ValidatePassword 验证密码
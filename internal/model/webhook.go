@@ -0,0 +1,55 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// 投递状态
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// Webhook 外部系统订阅的回调地址
+type Webhook struct {
+	ID          uint       `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UpdatedAt   time.Time  `json:"updated_at" example:"2024-07-20T10:00:00Z"`
+	URL         string     `gorm:"size:500;not null" json:"url" example:"https://example.com/hooks/cms"`
+	Description string     `gorm:"size:200" json:"description" example:"搜索索引"`
+	Events      StringList `gorm:"type:text" json:"events"` // 订阅的事件，支持 "*" 和 "article.*"
+	Secret      string     `gorm:"size:100;not null" json:"-"`
+	Active      bool       `gorm:"not null;default:true" json:"active" example:"true"`
+}
+
+// Subscribes 判断是否订阅了指定事件
+func (w *Webhook) Subscribes(event string) bool {
+	for _, pattern := range w.Events {
+		if pattern == "*" || pattern == event {
+			return true
+		}
+		if strings.HasSuffix(pattern, ".*") && strings.HasPrefix(event, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery 一次事件投递及其重试状态
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt      time.Time  `json:"created_at" example:"2024-07-20T10:00:00Z"`
+	UpdatedAt      time.Time  `json:"updated_at" example:"2024-07-20T10:00:00Z"`
+	WebhookID      uint       `gorm:"not null;index" json:"webhook_id" example:"1"`
+	Event          string     `gorm:"size:100;not null" json:"event" example:"article.published"`
+	Payload        string     `gorm:"type:text" json:"payload"`
+	Status         string     `gorm:"size:20;not null;index" json:"status" example:"pending"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts" example:"0"`
+	NextAttemptAt  *time.Time `gorm:"index" json:"next_attempt_at,omitempty"`
+	ResponseStatus int        `json:"response_status" example:"200"`
+	LastError      string     `gorm:"size:500" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	ReplayOf       *uint      `json:"replay_of,omitempty"`
+}
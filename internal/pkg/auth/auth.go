@@ -1,10 +1,15 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/casbin/casbin/v2/util"
 	"github.com/golang-jwt/jwt"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -12,14 +17,87 @@ import (
 var (
 	jwtSecret   []byte
 	tokenExpire time.Duration
+	revocations RevocationStore
+	personal    PersonalTokenStore
 )
 
+// PersonalTokenPrefix 个人访问 token 的前缀，用于和 JWT 区分
+const PersonalTokenPrefix = "pat_"
+
+var (
+	// ErrInvalidToken token 无效、过期或用途不符
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenRevoked token 已被注销或撤销
+	ErrTokenRevoked = errors.New("token has been revoked")
+)
+
+// Claims 访问 token 中的身份信息
+type Claims struct {
+	UserID    uint
+	ID        string // jti
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+
+	PersonalTokenID uint     // 使用个人访问 token 认证时为该 token 的 ID
+	Scopes          []string // 个人访问 token 允许访问的接口，格式为 "METHOD path"
+}
+
+// Personal 是否使用个人访问 token 认证
+func (c *Claims) Personal() bool {
+	return c.PersonalTokenID != 0
+}
+
+// Allows 判断请求是否在 token 的权限范围内，登录 token 不受限制。
+// path 的写法和 casbin 策略相同，按 keyMatch2 匹配
+func (c *Claims) Allows(method, requestPath string) bool {
+	if !c.Personal() {
+		return true
+	}
+	for _, scope := range c.Scopes {
+		parts := strings.SplitN(scope, " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], method) {
+			continue
+		}
+		if util.KeyMatch2(requestPath, parts[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenClaims 登录 token 的 claims。iat 只精确到秒，iat_ms 记录毫秒级的签发时间，
+// 使撤销之后同一秒内签发的 token 不会被误判为已撤销
+type tokenClaims struct {
+	jwt.StandardClaims
+	IssuedAtMillis int64 `json:"iat_ms,omitempty"`
+}
+
+// RevocationStore 判断访问 token 是否已被撤销
+type RevocationStore interface {
+	IsRevoked(claims *Claims) (bool, error)
+}
+
+// PersonalTokenStore 校验个人访问 token
+type PersonalTokenStore interface {
+	AuthenticatePersonalToken(token string) (*Claims, error)
+}
+
 // Initialize 初始化认证配置
 func Initialize(secret string, expire time.Duration) {
 	jwtSecret = []byte(secret)
 	tokenExpire = expire
 }
 
+// SetRevocationStore 设置 Authenticate 使用的撤销记录，为空时不检查撤销
+func SetRevocationStore(store RevocationStore) {
+	revocations = store
+}
+
+// SetPersonalTokenStore 设置 Authenticate 使用的个人访问 token 存储，为空时不接受个人访问 token
+func SetPersonalTokenStore(store PersonalTokenStore) {
+	personal = store
+}
+
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
@@ -31,10 +109,20 @@ func CheckPassword(password, hash string) bool {
 }
 
 func GenerateToken(userID uint) (string, error) {
-	claims := jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(tokenExpire).Unix(),
-		IssuedAt:  time.Now().Unix(),
-		Subject:   fmt.Sprint(userID),
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := tokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        hex.EncodeToString(jti),
+			ExpiresAt: now.Add(tokenExpire).Unix(),
+			IssuedAt:  now.Unix(),
+			Subject:   fmt.Sprint(userID),
+		},
+		IssuedAtMillis: now.UnixMilli(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -42,18 +130,89 @@ func GenerateToken(userID uint) (string, error) {
 }
 
 func ParseToken(tokenString string) (uint, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.StandardClaims{}, func(token *jwt.Token) (interface{}, error) {
+	claims, err := Authenticate(tokenString)
+	if err != nil {
+		return 0, err
+	}
+	return claims.UserID, nil
+}
+
+// Authenticate 校验访问 token 的签名、有效期，并确认其未被撤销。
+// 以 PersonalTokenPrefix 开头的 token 交给 PersonalTokenStore 校验
+func Authenticate(tokenString string) (*Claims, error) {
+	if strings.HasPrefix(tokenString, PersonalTokenPrefix) {
+		if personal == nil {
+			return nil, ErrInvalidToken
+		}
+		return personal.AuthenticatePersonalToken(tokenString)
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return jwtSecret, nil
 	})
 
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+
+	// 限定用途的 token 带有 audience，不能当作登录凭证使用
+	parsed, ok := token.Claims.(*tokenClaims)
+	if !ok || !token.Valid || parsed.Audience != "" {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	userID, _ := strconv.ParseUint(parsed.Subject, 10, 32)
+	claims := &Claims{
+		UserID:    uint(userID),
+		ID:        parsed.Id,
+		IssuedAt:  time.Unix(parsed.IssuedAt, 0),
+		ExpiresAt: time.Unix(parsed.ExpiresAt, 0),
+	}
+	// 升级前签发的 token 没有 iat_ms
+	if parsed.IssuedAtMillis != 0 {
+		claims.IssuedAt = time.UnixMilli(parsed.IssuedAtMillis)
+	}
+
+	if revocations != nil {
+		revoked, err := revocations.IsRevoked(claims)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
 	}
+	return claims, nil
+}
+
+// GenerateScopedToken 生成限定用途的短期 token，audience 标明用途，subject 为用途相关的标识
+func GenerateScopedToken(audience, subject string, expire time.Duration) (string, error) {
+	claims := jwt.StandardClaims{
+		Audience:  audience,
+		ExpiresAt: time.Now().Add(expire).Unix(),
+		IssuedAt:  time.Now().Unix(),
+		Subject:   subject,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
+}
 
-	if claims, ok := token.Claims.(*jwt.StandardClaims); ok && token.Valid {
-		userID, _ := strconv.ParseUint(claims.Subject, 10, 32)
-		return uint(userID), nil
+// ParseScopedToken 校验限定用途的 token 并返回其 subject
+func ParseScopedToken(audience, tokenString string) (string, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &jwt.StandardClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return jwtSecret, nil
+	})
+	if err != nil {
+		return "", ErrInvalidToken
 	}
 
-	return 0, jwt.ErrSignatureInvalid
+	claims, ok := token.Claims.(*jwt.StandardClaims)
+	if !ok || !token.Valid || claims.Audience != audience {
+		return "", ErrInvalidToken
+	}
+	return claims.Subject, nil
 }
@@ -0,0 +1,135 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PrefixLength 查询 range 时使用的 SHA-1 前缀长度，与 HIBP 的 range 接口一致
+const PrefixLength = 5
+
+// 内置的常见弱密码列表，每行一个大写的 SHA-1
+//
+//go:embed common.txt
+var common []byte
+
+// Source 按 SHA-1 前缀返回泄露密码哈希的其余部分（k-anonymity range），
+// 调用方只暴露前缀，在本地比较后缀
+type Source interface {
+	Range(prefix string) ([]string, error)
+}
+
+// Open 打开泄露密码列表。path 为空时使用内置列表；
+// path 为目录时按 HIBP range 文件组织，每个前缀一个 <PREFIX>.txt，每行 SUFFIX:COUNT；
+// path 为文件时每行一个完整的 SHA-1，可带 :COUNT，整个文件载入内存
+func Open(path string) (Source, error) {
+	if path == "" {
+		return load(bytes.NewReader(common))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("breach: %v", err)
+	}
+	if info.IsDir() {
+		return dirSource(path), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("breach: %v", err)
+	}
+	defer f.Close()
+	return load(f)
+}
+
+// Breached 判断密码是否出现在泄露列表中
+func Breached(src Source, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:PrefixLength], hash[PrefixLength:]
+
+	suffixes, err := src.Range(prefix)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range suffixes {
+		if s == suffix {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// memorySource 载入内存的列表，按前缀分组
+type memorySource map[string][]string
+
+func (m memorySource) Range(prefix string) ([]string, error) {
+	return m[strings.ToUpper(prefix)], nil
+}
+
+func load(r io.Reader) (memorySource, error) {
+	m := make(memorySource)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		hash := strings.TrimSpace(scanner.Text())
+		if hash == "" || strings.HasPrefix(hash, "#") {
+			continue
+		}
+		if i := strings.IndexByte(hash, ':'); i >= 0 {
+			hash = hash[:i]
+		}
+		if len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("breach: line %d: not a SHA-1 hash", line)
+		}
+		hash = strings.ToUpper(hash)
+		m[hash[:PrefixLength]] = append(m[hash[:PrefixLength]], hash[PrefixLength:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// dirSource 每个前缀一个文件的目录，每次查询只读取对应前缀的文件
+type dirSource string
+
+func (d dirSource) Range(prefix string) ([]string, error) {
+	prefix = strings.ToUpper(prefix)
+	if len(prefix) != PrefixLength {
+		return nil, fmt.Errorf("breach: invalid prefix %q", prefix)
+	}
+	if _, err := hex.DecodeString(prefix + "0"); err != nil {
+		return nil, fmt.Errorf("breach: invalid prefix %q", prefix)
+	}
+
+	f, err := os.Open(filepath.Join(string(d), prefix+".txt"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var suffixes []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		suffix := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(suffix, ':'); i >= 0 {
+			suffix = suffix[:i]
+		}
+		if suffix != "" {
+			suffixes = append(suffixes, strings.ToUpper(suffix))
+		}
+	}
+	return suffixes, scanner.Err()
+}
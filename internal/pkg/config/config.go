@@ -10,11 +10,23 @@ type Config struct {
 	Server   ServerConfig   `mapstructure:"server"`
 	Database DatabaseConfig `mapstructure:"database"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
+	Site     SiteConfig     `mapstructure:"site"`
+	Theme    ThemeConfig    `mapstructure:"theme"`
+	Export   ExportConfig   `mapstructure:"export"`
+	Web      WebConfig      `mapstructure:"web"`
+	Lint     LintConfig     `mapstructure:"lint"`
+	Review   ReviewConfig   `mapstructure:"review"`
+	Account  AccountConfig  `mapstructure:"account"`
+	Mail     MailConfig     `mapstructure:"mail"`
+	Lockout  LockoutConfig  `mapstructure:"lockout"`
+	Password PasswordConfig `mapstructure:"password"`
+	OIDC     OIDCConfig     `mapstructure:"oidc"`
 }
 
 type ServerConfig struct {
-	Port string `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Port           string   `mapstructure:"port"`
+	Mode           string   `mapstructure:"mode"`
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // 可信的反向代理，只有来自这些地址的 X-Forwarded-For 才会被采用
 }
 
 type DatabaseConfig struct {
@@ -29,8 +41,113 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-	Secret     string        `mapstructure:"secret"`
-	ExpireTime time.Duration `mapstructure:"expire_time"`
+	Secret            string        `mapstructure:"secret"`
+	ExpireTime        time.Duration `mapstructure:"expire_time"`         // 访问 token 有效期
+	RefreshExpireTime time.Duration `mapstructure:"refresh_expire_time"` // 刷新 token 有效期，从登录时开始计算，轮换不延长
+}
+
+type SiteConfig struct {
+	Name    string `mapstructure:"name"`
+	BaseURL string `mapstructure:"base_url"`
+}
+
+type ThemeConfig struct {
+	Dir string `mapstructure:"dir"` // 为空时使用内置主题
+}
+
+type ExportConfig struct {
+	Dir      string `mapstructure:"dir"`
+	PageSize int    `mapstructure:"page_size"`
+}
+
+// WebConfig 服务端渲染的公开站点，使用 theme 中配置的主题
+type WebConfig struct {
+	Enabled  bool `mapstructure:"enabled"`
+	PageSize int  `mapstructure:"page_size"`
+}
+
+// LintConfig 发布前检查规则的严重程度：off、info、warning、error
+type LintConfig struct {
+	Rules map[string]string `mapstructure:"rules"`
+}
+
+// ReviewConfig 发布前需要的审核通过数，为 0 时不要求审核；ReviewerRoles 为可以被指派审核的角色
+type ReviewConfig struct {
+	MinApprovals  int      `mapstructure:"min_approvals"`
+	ReviewerRoles []string `mapstructure:"reviewer_roles"`
+}
+
+// AccountConfig 账号注册及验证
+type AccountConfig struct {
+	RequireEmailVerification bool          `mapstructure:"require_email_verification"`
+	VerifyExpire             time.Duration `mapstructure:"verify_expire"`          // 验证链接有效期
+	VerifyResendInterval     time.Duration `mapstructure:"verify_resend_interval"` // 重发验证邮件的最小间隔
+	ResetExpire              time.Duration `mapstructure:"reset_expire"`           // 找回密码 token 有效期
+	AdminPassword            string        `mapstructure:"admin_password"`         // 首次启动时创建的 admin 用户的密码，为空时随机生成
+}
+
+// LockoutConfig 登录失败的等待及锁定
+type LockoutConfig struct {
+	MaxAttempts   int           `mapstructure:"max_attempts"`    // 同一用户名连续失败多少次后锁定
+	IPMaxAttempts int           `mapstructure:"ip_max_attempts"` // 同一 IP 连续失败多少次后锁定
+	Window        time.Duration `mapstructure:"window"`          // 超过该时间没有新的失败时重新计数
+	Duration      time.Duration `mapstructure:"duration"`        // 锁定时长
+	BaseDelay     time.Duration `mapstructure:"base_delay"`      // 失败后需要等待的时间，之后每次失败翻倍
+	MaxDelay      time.Duration `mapstructure:"max_delay"`
+}
+
+// PasswordConfig 设置密码时需要满足的规则
+type PasswordConfig struct {
+	MinLength     int    `mapstructure:"min_length"`
+	RequireLower  bool   `mapstructure:"require_lower"`
+	RequireUpper  bool   `mapstructure:"require_upper"`
+	RequireDigit  bool   `mapstructure:"require_digit"`
+	RequireSymbol bool   `mapstructure:"require_symbol"`
+	CheckBreached bool   `mapstructure:"check_breached"` // 拒绝出现在泄露密码列表中的密码
+	BreachedList  string `mapstructure:"breached_list"`  // 泄露密码列表，为空时使用内置的常见弱密码列表
+}
+
+// OIDCConfig 通过外部 OpenID Connect 身份提供方登录
+type OIDCConfig struct {
+	Enabled       bool              `mapstructure:"enabled"`
+	Issuer        string            `mapstructure:"issuer"`
+	ClientID      string            `mapstructure:"client_id"`
+	ClientSecret  string            `mapstructure:"client_secret"` // 为空时按公开客户端处理，只依赖 PKCE
+	RedirectURL   string            `mapstructure:"redirect_url"`  // 为空时使用 site.base_url + /api/v1/users/oidc/callback
+	Scopes        []string          `mapstructure:"scopes"`
+	UsernameClaim string            `mapstructure:"username_claim"`
+	EmailClaim    string            `mapstructure:"email_claim"`
+	RoleClaim     string            `mapstructure:"role_claim"`
+	RoleMapping   []OIDCRoleMapping `mapstructure:"role_mapping"`
+	DefaultRole   string            `mapstructure:"default_role"` // 新用户没有映射到任何角色时分配的角色
+	AutoCreate    bool              `mapstructure:"auto_create"`  // 首次登录时自动创建用户
+}
+
+// OIDCRoleMapping role_claim 声明中包含 value 时授予 role
+type OIDCRoleMapping struct {
+	Value string `mapstructure:"value"`
+	Role  string `mapstructure:"role"`
+}
+
+// MailConfig 邮件发送。driver 为 smtp、file 或 stdout，file 和 stdout 只写出邮件内容，用于开发调试
+type MailConfig struct {
+	Driver        string        `mapstructure:"driver"`
+	From          string        `mapstructure:"from"`
+	Locale        string        `mapstructure:"locale"`       // 用户未设置语言时使用的模板语言
+	TemplateDir   string        `mapstructure:"template_dir"` // 为空时使用内置模板
+	FileDir       string        `mapstructure:"file_dir"`     // file 方式下邮件写入的目录
+	QueueSize     int           `mapstructure:"queue_size"`
+	MaxRetries    int           `mapstructure:"max_retries"`
+	RetryInterval time.Duration `mapstructure:"retry_interval"` // 首次重试的等待时间，之后逐次翻倍
+	SMTP          SMTPConfig    `mapstructure:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	TLS      string `mapstructure:"tls"` // starttls、tls 或 none
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -0,0 +1,156 @@
+package ebook
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Book 一本电子书，章节按顺序排列
+type Book struct {
+	Identifier  string // 为空时生成 urn:uuid
+	Title       string
+	Author      string
+	Publisher   string
+	Language    string
+	Description string
+	Modified    time.Time
+	Chapters    []*Chapter
+}
+
+type Chapter struct {
+	Title     string
+	Published *time.Time
+	Body      string // 纯文本正文，空行分段
+}
+
+// Paragraphs 按空行拆分正文，段内换行保留为行
+func (c *Chapter) Paragraphs() [][]string {
+	var paragraphs [][]string
+	for _, block := range strings.Split(strings.ReplaceAll(c.Body, "\r\n", "\n"), "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		paragraphs = append(paragraphs, strings.Split(block, "\n"))
+	}
+	return paragraphs
+}
+
+func (b *Book) normalize() {
+	if b.Identifier == "" {
+		b.Identifier = "urn:uuid:" + newUUID()
+	}
+	if b.Language == "" {
+		b.Language = "zh-CN"
+	}
+	if b.Modified.IsZero() {
+		b.Modified = time.Now()
+	}
+}
+
+// WriteEPUB 输出 EPUB 3 文件，同时包含 toc.ncx 以兼容只支持 EPUB 2 的阅读器
+func WriteEPUB(w io.Writer, book *Book) error {
+	book.normalize()
+	zw := zip.NewWriter(w)
+
+	// mimetype 必须是第一个文件且不压缩
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store, Modified: book.Modified})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		tmpl string
+		data interface{}
+	}{
+		{"META-INF/container.xml", containerXML, book},
+		{"OEBPS/content.opf", contentOPF, book},
+		{"OEBPS/nav.xhtml", navXHTML, book},
+		{"OEBPS/toc.ncx", tocNCX, book},
+	}
+	for _, f := range files {
+		if err := writeTemplate(zw, book, f.name, f.tmpl, f.data); err != nil {
+			return err
+		}
+	}
+
+	style, err := createFile(zw, book, "OEBPS/style.css")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(style, epubCSS); err != nil {
+		return err
+	}
+
+	for i, chapter := range book.Chapters {
+		data := struct {
+			*Chapter
+			Language string
+		}{chapter, book.Language}
+		if err := writeTemplate(zw, book, fmt.Sprintf("OEBPS/chapter-%d.xhtml", i+1), chapterXHTML, data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// WritePrintHTML 输出包含所有章节的单个 HTML 文档，样式针对打印和转换 PDF 优化
+func WritePrintHTML(w io.Writer, book *Book) error {
+	book.normalize()
+	return printTemplate.Execute(w, book)
+}
+
+func writeTemplate(zw *zip.Writer, book *Book, name, tmpl string, data interface{}) error {
+	t, err := texttemplate.New(name).Funcs(xmlFuncs).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	f, err := createFile(zw, book, name)
+	if err != nil {
+		return err
+	}
+	return t.Execute(f, data)
+}
+
+func createFile(zw *zip.Writer, book *Book, name string) (io.Writer, error) {
+	return zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: book.Modified})
+}
+
+// newUUID 生成随机的 UUID v4
+func newUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+var xmlFuncs = texttemplate.FuncMap{
+	"x":    texttemplate.HTMLEscapeString,
+	"inc":  func(i int) int { return i + 1 },
+	"utc":  func(t time.Time) string { return t.UTC().Format("2006-01-02T15:04:05Z") },
+	"date": formatDate,
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+var printTemplate = template.Must(template.New("print").Funcs(template.FuncMap{
+	"inc":  func(i int) int { return i + 1 },
+	"date": formatDate,
+}).Parse(printHTML))
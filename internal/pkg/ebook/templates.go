@@ -0,0 +1,193 @@
+package ebook
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const contentOPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="{{x .Language}}">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">{{x .Identifier}}</dc:identifier>
+    <dc:title>{{x .Title}}</dc:title>
+    <dc:language>{{x .Language}}</dc:language>
+{{- if .Author}}
+    <dc:creator>{{x .Author}}</dc:creator>
+{{- end}}
+{{- if .Publisher}}
+    <dc:publisher>{{x .Publisher}}</dc:publisher>
+{{- end}}
+{{- if .Description}}
+    <dc:description>{{x .Description}}</dc:description>
+{{- end}}
+    <meta property="dcterms:modified">{{utc .Modified}}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+{{- range $i, $c := .Chapters}}
+    <item id="chapter-{{inc $i}}" href="chapter-{{inc $i}}.xhtml" media-type="application/xhtml+xml"/>
+{{- end}}
+  </manifest>
+  <spine toc="ncx">
+{{- range $i, $c := .Chapters}}
+    <itemref idref="chapter-{{inc $i}}"/>
+{{- end}}
+  </spine>
+</package>
+`
+
+const navXHTML = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{x .Language}}" lang="{{x .Language}}">
+<head>
+  <title>{{x .Title}}</title>
+  <link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>目录</h1>
+    <ol>
+{{- range $i, $c := .Chapters}}
+      <li><a href="chapter-{{inc $i}}.xhtml">{{x $c.Title}}</a></li>
+{{- end}}
+    </ol>
+  </nav>
+</body>
+</html>
+`
+
+const tocNCX = `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head>
+    <meta name="dtb:uid" content="{{x .Identifier}}"/>
+  </head>
+  <docTitle><text>{{x .Title}}</text></docTitle>
+  <navMap>
+{{- range $i, $c := .Chapters}}
+    <navPoint id="nav-{{inc $i}}" playOrder="{{inc $i}}">
+      <navLabel><text>{{x $c.Title}}</text></navLabel>
+      <content src="chapter-{{inc $i}}.xhtml"/>
+    </navPoint>
+{{- end}}
+  </navMap>
+</ncx>
+`
+
+const chapterXHTML = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="{{x .Language}}" lang="{{x .Language}}">
+<head>
+  <title>{{x .Title}}</title>
+  <link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+  <section>
+    <h1>{{x .Title}}</h1>
+{{- with date .Published}}
+    <p class="date">{{.}}</p>
+{{- end}}
+{{- range .Paragraphs}}
+    <p>{{range $i, $line := .}}{{if $i}}<br/>{{end}}{{x $line}}{{end}}</p>
+{{- end}}
+  </section>
+</body>
+</html>
+`
+
+const epubCSS = `body {
+  font-family: serif;
+  line-height: 1.6;
+}
+
+h1 {
+  font-size: 1.5em;
+  margin: 1em 0;
+}
+
+p {
+  text-indent: 2em;
+  margin: 0.5em 0;
+}
+
+p.date {
+  text-indent: 0;
+  color: #666;
+  font-size: 0.9em;
+}
+`
+
+const printHTML = `<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+  @page {
+    size: A4;
+    margin: 20mm 18mm;
+  }
+  body {
+    font-family: serif;
+    font-size: 11pt;
+    line-height: 1.6;
+    color: #000;
+  }
+  .cover {
+    text-align: center;
+    padding-top: 30%;
+  }
+  .toc,
+  .chapter {
+    break-before: page;
+    page-break-before: always;
+  }
+  .toc a {
+    color: #000;
+    text-decoration: none;
+  }
+  .chapter h1 {
+    break-after: avoid;
+    page-break-after: avoid;
+  }
+  .chapter p {
+    text-indent: 2em;
+    orphans: 3;
+    widows: 3;
+  }
+  .chapter p.date {
+    text-indent: 0;
+    color: #555;
+  }
+</style>
+</head>
+<body>
+<section class="cover">
+  <h1>{{.Title}}</h1>
+  {{if .Author}}<p>{{.Author}}</p>{{end}}
+  {{if .Description}}<p>{{.Description}}</p>{{end}}
+</section>
+<nav class="toc">
+  <h2>目录</h2>
+  <ol>
+  {{range $i, $c := .Chapters}}
+    <li><a href="#chapter-{{inc $i}}">{{$c.Title}}</a></li>
+  {{end}}
+  </ol>
+</nav>
+{{range $i, $c := .Chapters}}
+<section class="chapter" id="chapter-{{inc $i}}">
+  <h1>{{$c.Title}}</h1>
+  {{with date $c.Published}}<p class="date">{{.}}</p>{{end}}
+  {{range $c.Paragraphs}}
+  <p>{{range $j, $line := .}}{{if $j}}<br>{{end}}{{$line}}{{end}}</p>
+  {{end}}
+</section>
+{{end}}
+</body>
+</html>
+`
@@ -0,0 +1,109 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// Calendar 一个 iCalendar（RFC 5545）日历
+type Calendar struct {
+	ProdID string // 为空时使用 -//hello-go//calendar//EN
+	Name   string
+	Events []*Event
+}
+
+// Event 日历中的一个事件，End 为空时与 Start 相同
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	URL         string
+	Categories  []string
+}
+
+const timeLayout = "20060102T150405Z"
+
+// maxLineOctets 单行最多 75 字节，超出部分折行
+const maxLineOctets = 75
+
+// Write 以 text/calendar 格式输出日历，时间统一使用 UTC
+func Write(w io.Writer, cal *Calendar) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	prodID := cal.ProdID
+	if prodID == "" {
+		prodID = "-//hello-go//calendar//EN"
+	}
+	stamp := time.Now().UTC().Format(timeLayout)
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", escape(prodID))
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", escape(cal.Name))
+	}
+	for _, event := range cal.Events {
+		end := event.End
+		if end.IsZero() {
+			end = event.Start
+		}
+		line("BEGIN", "VEVENT")
+		line("UID", escape(event.UID))
+		line("DTSTAMP", stamp)
+		line("DTSTART", event.Start.UTC().Format(timeLayout))
+		line("DTEND", end.UTC().Format(timeLayout))
+		line("SUMMARY", escape(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", escape(event.Description))
+		}
+		if event.URL != "" {
+			line("URL", event.URL)
+		}
+		if len(event.Categories) > 0 {
+			categories := make([]string, len(event.Categories))
+			for i, category := range event.Categories {
+				categories[i] = escape(category)
+			}
+			line("CATEGORIES", strings.Join(categories, ","))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	return bw.Flush()
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escape 转义文本值中的特殊字符
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// writeFolded 写入一行内容，超过 75 字节时在 UTF-8 字符边界处折行
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		// 回退到 UTF-8 字符的起始字节
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// 续行以一个空格开头，占用一个字节
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
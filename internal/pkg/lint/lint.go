@@ -0,0 +1,120 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity 检查结果的严重程度，error 会阻止发布
+type Severity string
+
+const (
+	SeverityOff     Severity = "off"
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// ParseSeverity 解析配置中的严重程度
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(strings.ToLower(s)) {
+	case SeverityOff, SeverityInfo, SeverityWarning, SeverityError:
+		return Severity(strings.ToLower(s)), nil
+	default:
+		return "", fmt.Errorf("unknown lint severity %q", s)
+	}
+}
+
+// Document 待检查的文章
+type Document struct {
+	ID      uint
+	Title   string
+	Excerpt string
+	Content string
+}
+
+// Location 问题所在的字段和行号，行号从 1 开始，0 表示整个字段
+type Location struct {
+	Field string `json:"field" example:"content"`
+	Line  int    `json:"line,omitempty" example:"12"`
+}
+
+// Finding 一条检查结果
+type Finding struct {
+	Rule     string   `json:"rule" example:"image-alt"`
+	Severity Severity `json:"severity" example:"warning"`
+	Message  string   `json:"message" example:"image has no alt text"`
+	Location Location `json:"location"`
+}
+
+// Rule 一条检查规则。规则只需要返回问题的位置和描述，严重程度由引擎按配置填写
+type Rule interface {
+	Name() string
+	DefaultSeverity() Severity
+	Check(doc *Document) ([]Finding, error)
+}
+
+// Engine 按配置的严重程度运行一组规则
+type Engine struct {
+	rules      []Rule
+	severities map[string]Severity
+}
+
+// NewEngine 创建检查引擎，severities 覆盖规则的默认严重程度
+func NewEngine(severities map[string]string, rules ...Rule) (*Engine, error) {
+	e := &Engine{
+		rules:      rules,
+		severities: make(map[string]Severity, len(severities)),
+	}
+
+	known := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		known[rule.Name()] = true
+	}
+	for name, value := range severities {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown lint rule %q", name)
+		}
+		severity, err := ParseSeverity(value)
+		if err != nil {
+			return nil, err
+		}
+		e.severities[name] = severity
+	}
+	return e, nil
+}
+
+// Run 运行所有未关闭的规则
+func (e *Engine) Run(doc *Document) ([]Finding, error) {
+	findings := make([]Finding, 0)
+	for _, rule := range e.rules {
+		severity, ok := e.severities[rule.Name()]
+		if !ok {
+			severity = rule.DefaultSeverity()
+		}
+		if severity == SeverityOff {
+			continue
+		}
+
+		results, err := rule.Check(doc)
+		if err != nil {
+			return nil, fmt.Errorf("lint rule %s: %v", rule.Name(), err)
+		}
+		for _, finding := range results {
+			finding.Rule = rule.Name()
+			finding.Severity = severity
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+// HasErrors 判断是否存在阻止发布的问题
+func HasErrors(findings []Finding) bool {
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
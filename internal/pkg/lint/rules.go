@@ -0,0 +1,154 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultRules 内置的规则，不依赖数据库
+func DefaultRules() []Rule {
+	return []Rule{
+		ExcerptMissing{},
+		TitleLength{Max: 70},
+		ImageAlt{},
+		Readability{MaxSentenceLength: 40},
+	}
+}
+
+// ExcerptMissing 缺少摘要
+type ExcerptMissing struct{}
+
+func (ExcerptMissing) Name() string              { return "excerpt-missing" }
+func (ExcerptMissing) DefaultSeverity() Severity { return SeverityWarning }
+
+func (ExcerptMissing) Check(doc *Document) ([]Finding, error) {
+	if strings.TrimSpace(doc.Excerpt) != "" {
+		return nil, nil
+	}
+	return []Finding{{
+		Message:  "article has no excerpt",
+		Location: Location{Field: "excerpt"},
+	}}, nil
+}
+
+// TitleLength 标题过长，在搜索结果和分享卡片中会被截断
+type TitleLength struct {
+	Max int
+}
+
+func (TitleLength) Name() string              { return "title-length" }
+func (TitleLength) DefaultSeverity() Severity { return SeverityWarning }
+
+func (r TitleLength) Check(doc *Document) ([]Finding, error) {
+	if n := utf8.RuneCountInString(doc.Title); n > r.Max {
+		return []Finding{{
+			Message:  fmt.Sprintf("title is %d characters long, keep it under %d", n, r.Max),
+			Location: Location{Field: "title"},
+		}}, nil
+	}
+	return nil, nil
+}
+
+var (
+	htmlImagePattern     = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+	htmlAltPattern       = regexp.MustCompile(`(?i)\balt\s*=\s*("[^"]*\S[^"]*"|'[^']*\S[^']*'|[^\s"'>]+)`)
+	markdownImagePattern = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+)
+
+// ImageAlt 图片缺少替代文本，支持 HTML 和 Markdown 图片
+type ImageAlt struct{}
+
+func (ImageAlt) Name() string              { return "image-alt" }
+func (ImageAlt) DefaultSeverity() Severity { return SeverityWarning }
+
+func (ImageAlt) Check(doc *Document) ([]Finding, error) {
+	var findings []Finding
+	for i, line := range strings.Split(doc.Content, "\n") {
+		for _, img := range htmlImagePattern.FindAllString(line, -1) {
+			if !htmlAltPattern.MatchString(img) {
+				findings = append(findings, Finding{
+					Message:  "image has no alt text",
+					Location: Location{Field: "content", Line: i + 1},
+				})
+			}
+		}
+		for _, match := range markdownImagePattern.FindAllStringSubmatch(line, -1) {
+			if strings.TrimSpace(match[1]) == "" {
+				findings = append(findings, Finding{
+					Message:  "image has no alt text",
+					Location: Location{Field: "content", Line: i + 1},
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+var (
+	htmlTagPattern    = regexp.MustCompile(`<[^>]+>`)
+	sentenceDelimiter = regexp.MustCompile(`[.!?。！？；;]+|\n\s*\n`)
+)
+
+// Readability 按平均句长给出可读性评分（0-100），句子越短分数越高。
+// 中文按字计算长度，其它语言按词计算
+type Readability struct {
+	MaxSentenceLength int // 平均句长超过该值时给出提示
+}
+
+func (Readability) Name() string              { return "readability" }
+func (Readability) DefaultSeverity() Severity { return SeverityInfo }
+
+func (r Readability) Check(doc *Document) ([]Finding, error) {
+	text := htmlTagPattern.ReplaceAllString(doc.Content, " ")
+
+	sentences, units := 0, 0
+	for _, sentence := range sentenceDelimiter.Split(text, -1) {
+		n := sentenceLength(sentence)
+		if n == 0 {
+			continue
+		}
+		sentences++
+		units += n
+	}
+	if sentences == 0 {
+		return nil, nil
+	}
+
+	average := float64(units) / float64(sentences)
+	score := 100 - int(average*100/float64(2*r.MaxSentenceLength))
+	if score < 0 {
+		score = 0
+	}
+	if average <= float64(r.MaxSentenceLength) {
+		return nil, nil
+	}
+	return []Finding{{
+		Message: fmt.Sprintf("readability score %d/100: sentences average %.0f words, aim for under %d",
+			score, average, r.MaxSentenceLength),
+		Location: Location{Field: "content"},
+	}}, nil
+}
+
+// sentenceLength 句子长度，汉字每个字计 1，其它文字按空白分词
+func sentenceLength(sentence string) int {
+	n := 0
+	inWord := false
+	for _, r := range sentence {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			n++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				n++
+				inWord = true
+			}
+		default:
+			inWord = false
+		}
+	}
+	return n
+}
@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/wuwen/hello-go/internal/pkg/config"
+)
+
+// Message 一封邮件，HTML 为空时只发送纯文本
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender 邮件发送方式
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewSender 按配置创建发送方式：smtp 发送到邮件服务器，file 和 stdout 只写出邮件内容，
+// 便于在没有邮件服务器的环境中开发和调试。
+// stdout 会把验证、重置密码链接写进日志，release 模式下拒绝使用
+func NewSender(cfg *config.MailConfig, release bool) (Sender, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return newSMTPSender(&cfg.SMTP, cfg.From)
+	case "file":
+		return NewFileSender(cfg.FileDir, cfg.From)
+	case "stdout", "":
+		if release {
+			return nil, fmt.Errorf("mail driver stdout is for development only, configure smtp or file in release mode")
+		}
+		return NewWriterSender(os.Stdout, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %s", cfg.Driver)
+	}
+}
+
+// Mailer 用模板渲染邮件并交给 Sender 发送
+type Mailer struct {
+	sender    Sender
+	templates *Templates
+}
+
+func NewMailer(sender Sender, templates *Templates) *Mailer {
+	return &Mailer{sender: sender, templates: templates}
+}
+
+// Send 用 locale 对应语言的模板 name 渲染邮件并发送给 to
+func (m *Mailer) Send(ctx context.Context, to, locale, name string, data interface{}) error {
+	msg, err := m.templates.Render(name, locale, data)
+	if err != nil {
+		return err
+	}
+	msg.To = to
+	return m.sender.Send(ctx, msg)
+}
@@ -0,0 +1,100 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	defaultQueueSize     = 100
+	defaultRetryInterval = 10 * time.Second
+)
+
+var ErrQueueFull = errors.New("mail: queue is full")
+
+type job struct {
+	msg     *Message
+	attempt int
+}
+
+// Queue 异步发送邮件，失败后按 retryInterval、2*retryInterval……的间隔重试 maxRetries 次。
+// Queue 本身实现 Sender，Send 只负责入队，需要随服务启动 Run
+type Queue struct {
+	sender        Sender
+	jobs          chan *job
+	maxRetries    int
+	retryInterval time.Duration
+	done          chan struct{}
+}
+
+func NewQueue(sender Sender, size, maxRetries int, retryInterval time.Duration) *Queue {
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if retryInterval <= 0 {
+		retryInterval = defaultRetryInterval
+	}
+	return &Queue{
+		sender:        sender,
+		jobs:          make(chan *job, size),
+		maxRetries:    maxRetries,
+		retryInterval: retryInterval,
+		done:          make(chan struct{}),
+	}
+}
+
+// Send 将邮件加入发送队列，队列已满时返回 ErrQueueFull
+func (q *Queue) Send(ctx context.Context, msg *Message) error {
+	copied := *msg
+	select {
+	case q.jobs <- &job{msg: &copied}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Run 逐封发送队列中的邮件，ctx 取消后停止，尚未发送的邮件会被丢弃
+func (q *Queue) Run(ctx context.Context) {
+	defer close(q.done)
+	for {
+		select {
+		case <-ctx.Done():
+			if n := len(q.jobs); n > 0 {
+				log.Printf("mail: shutting down with %d unsent messages", n)
+			}
+			return
+		case j := <-q.jobs:
+			q.deliver(ctx, j)
+		}
+	}
+}
+
+func (q *Queue) deliver(ctx context.Context, j *job) {
+	err := q.sender.Send(ctx, j.msg)
+	if err == nil {
+		return
+	}
+	if j.attempt >= q.maxRetries {
+		log.Printf("mail: giving up on message to %s after %d attempts: %v", j.msg.To, j.attempt+1, err)
+		return
+	}
+
+	delay := q.retryInterval << j.attempt
+	j.attempt++
+	log.Printf("mail: failed to send message to %s, retrying in %s: %v", j.msg.To, delay, err)
+	// 等待期间不阻塞其它邮件
+	time.AfterFunc(delay, func() {
+		select {
+		case <-q.done:
+		case q.jobs <- j:
+		default:
+			log.Printf("mail: queue is full, dropping retry of message to %s", j.msg.To)
+		}
+	})
+}
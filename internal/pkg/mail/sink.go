@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileSender 把每封邮件写成 dir 下的一个 .eml 文件，不实际发送。邮件中含有登录相关的链接，文件只有属主可读
+type FileSender struct {
+	dir  string
+	from string
+}
+
+func NewFileSender(dir, from string) (*FileSender, error) {
+	if dir == "" {
+		dir = "mail"
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileSender{dir: dir, from: from}, nil
+}
+
+func (s *FileSender) Send(ctx context.Context, msg *Message) error {
+	id := newMessageID(s.from)
+	data, err := build(s.from, msg, id, time.Now())
+	if err != nil {
+		return err
+	}
+	name := strings.SplitN(id, "@", 2)[0] + ".eml"
+	return os.WriteFile(filepath.Join(s.dir, name), data, 0600)
+}
+
+// WriterSender 把邮件写到 w，例如标准输出，不实际发送
+type WriterSender struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+func NewWriterSender(w io.Writer, from string) *WriterSender {
+	return &WriterSender{w: w, from: from}
+}
+
+// Send 只写出收件人、主题和纯文本正文，便于直接阅读
+func (s *WriterSender) Send(ctx context.Context, msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := fmt.Fprintf(s.w, "----- mail -----\nFrom: %s\nTo: %s\nSubject: %s\n\n%s\n----- end mail -----\n",
+		s.from, msg.To, msg.Subject, msg.Text)
+	return err
+}
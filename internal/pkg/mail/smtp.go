@@ -0,0 +1,130 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/pkg/config"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// SMTPSender 通过 SMTP 服务器发送邮件。
+// tls 为 tls 时直接建立 TLS 连接（通常是 465 端口），starttls 时要求服务器支持 STARTTLS，none 时不加密
+type SMTPSender struct {
+	addr     string
+	host     string
+	username string
+	password string
+	tls      string
+	from     string
+}
+
+func newSMTPSender(cfg *config.SMTPConfig, from string) (*SMTPSender, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("mail: smtp host is required")
+	}
+	if _, err := mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("mail: invalid from address %q: %v", from, err)
+	}
+	mode := cfg.TLS
+	switch mode {
+	case "":
+		mode = "starttls"
+	case "tls", "starttls", "none":
+	default:
+		return nil, fmt.Errorf("mail: unsupported smtp tls mode: %s", cfg.TLS)
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+	return &SMTPSender{
+		addr:     net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		host:     cfg.Host,
+		username: cfg.Username,
+		password: cfg.Password,
+		tls:      mode,
+		from:     from,
+	}, nil
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	sender, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return err
+	}
+	rcpt, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("mail: invalid recipient %q: %v", msg.To, err)
+	}
+	data, err := build(s.from, msg, newMessageID(s.from), time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if s.tls == "starttls" {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("mail: smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(sender.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(rcpt.Address); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (s *SMTPSender) dial(ctx context.Context) (net.Conn, error) {
+	if s.tls == "tls" {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: s.host}}
+		return dialer.DialContext(ctx, "tcp", s.addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", s.addr)
+}
@@ -0,0 +1,184 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// 内置的邮件模板，按语言分目录：templates/<locale>/<name>.txt 及可选的 <name>.html。
+// txt 模板中用 {{define "subject"}} 定义主题
+//
+//go:embed templates
+var defaults embed.FS
+
+const subjectTemplate = "subject"
+
+var funcs = map[string]interface{}{
+	"duration": formatDuration,
+}
+
+type template struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template // 可能为 nil
+}
+
+// Templates 解析好的各语言邮件模板
+type Templates struct {
+	defaultLocale string
+	locales       map[string]map[string]*template
+}
+
+// LoadTemplates 加载内置模板并用 dir 下同样结构的同名文件覆盖，dir 为空时只使用内置模板。
+// 找不到请求的语言时使用 defaultLocale，默认为 en
+func LoadTemplates(dir, defaultLocale string) (*Templates, error) {
+	if defaultLocale == "" {
+		defaultLocale = "en"
+	}
+	sources, err := readSources(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Templates{defaultLocale: defaultLocale, locales: make(map[string]map[string]*template)}
+	for file, content := range sources {
+		locale, name := path.Split(file)
+		locale = strings.TrimSuffix(locale, "/")
+		ext := path.Ext(name)
+		name = strings.TrimSuffix(name, ext)
+
+		if t.locales[locale] == nil {
+			t.locales[locale] = make(map[string]*template)
+		}
+		tmpl := t.locales[locale][name]
+		if tmpl == nil {
+			tmpl = &template{}
+			t.locales[locale][name] = tmpl
+		}
+
+		switch ext {
+		case ".txt":
+			tmpl.text, err = texttemplate.New(name).Funcs(funcs).Parse(content)
+		case ".html":
+			tmpl.html, err = htmltemplate.New(name).Funcs(funcs).Parse(content)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("mail: parse %s: %v", file, err)
+		}
+	}
+
+	for locale, templates := range t.locales {
+		for name, tmpl := range templates {
+			if tmpl.text == nil || tmpl.text.Lookup(subjectTemplate) == nil {
+				return nil, fmt.Errorf("mail: %s/%s.txt with a %q block is required", locale, name, subjectTemplate)
+			}
+		}
+	}
+	if _, ok := t.locales[defaultLocale]; !ok {
+		return nil, fmt.Errorf("mail: no templates for default locale %q", defaultLocale)
+	}
+	return t, nil
+}
+
+// Render 渲染邮件主题和正文。locale 依次尝试完整匹配（zh-CN）、语言部分（zh）和默认语言
+func (t *Templates) Render(name, locale string, data interface{}) (*Message, error) {
+	tmpl := t.lookup(name, locale)
+	if tmpl == nil {
+		return nil, fmt.Errorf("mail: template %q not found", name)
+	}
+
+	var subject, text bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, subjectTemplate, data); err != nil {
+		return nil, err
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	msg := &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}
+
+	if tmpl.html != nil {
+		var html bytes.Buffer
+		if err := tmpl.html.Execute(&html, data); err != nil {
+			return nil, err
+		}
+		msg.HTML = html.String()
+	}
+	return msg, nil
+}
+
+func (t *Templates) lookup(name, locale string) *template {
+	locale = strings.ReplaceAll(locale, "_", "-")
+	candidates := []string{locale}
+	if i := strings.Index(locale, "-"); i > 0 {
+		candidates = append(candidates, locale[:i])
+	}
+	candidates = append(candidates, t.defaultLocale)
+
+	for _, candidate := range candidates {
+		if tmpl, ok := t.locales[candidate][name]; ok {
+			return tmpl
+		}
+	}
+	return nil
+}
+
+// readSources 读取模板文件，键为 <locale>/<文件名>
+func readSources(dir string) (map[string]string, error) {
+	sources := make(map[string]string)
+
+	root, _ := fs.Sub(defaults, "templates")
+	if err := collect(root, sources); err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return sources, nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("mail: template dir: %v", err)
+	}
+	if err := collect(os.DirFS(filepath.Clean(dir)), sources); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+func collect(fsys fs.FS, sources map[string]string) error {
+	files, err := fs.Glob(fsys, "*/*")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if ext := path.Ext(file); ext != ".txt" && ext != ".html" {
+			continue
+		}
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		sources[file] = string(b)
+	}
+	return nil
+}
+
+// formatDuration 去掉时长中多余的零值单位，例如 24h0m0s 显示为 24h
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,161 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// 校验 exp、iat 时允许的时钟偏差
+const clockSkew = time.Minute
+
+var signingHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+}
+
+// IDToken 校验通过的 ID token
+type IDToken struct {
+	Issuer   string
+	Subject  string
+	Audience []string
+	Expiry   time.Time
+	IssuedAt time.Time
+	Claims   map[string]interface{} // 全部声明
+}
+
+// String 返回字符串类型的声明，不存在或类型不符时返回空字符串
+func (t *IDToken) String(name string) string {
+	s, _ := t.Claims[name].(string)
+	return s
+}
+
+// Strings 返回字符串或字符串数组类型的声明
+func (t *IDToken) Strings(name string) []string {
+	switch v := t.Claims[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// Bool 返回布尔类型的声明，ok 表示声明存在且类型正确
+func (t *IDToken) Bool(name string) (value, ok bool) {
+	value, ok = t.Claims[name].(bool)
+	return
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify 校验 ID token 的签名、issuer、audience、有效期及 nonce
+func (c *Client) Verify(ctx context.Context, raw, nonce string) (*IDToken, error) {
+	metadata, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidIDToken
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidIDToken
+	}
+	// 只接受 RSA 签名，拒绝 none 及可被公钥伪造的 HMAC
+	hash, ok := signingHashes[header.Alg]
+	if !ok {
+		return nil, ErrInvalidIDToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	keys, err := c.keys.lookup(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	digest := h.Sum(nil)
+	verified := false
+	for _, key := range keys {
+		if rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrInvalidIDToken
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidIDToken
+	}
+	token := &IDToken{Claims: claims}
+	token.Issuer, _ = claims["iss"].(string)
+	token.Subject, _ = claims["sub"].(string)
+	token.Audience = token.Strings("aud")
+	token.Expiry = numericDate(claims["exp"])
+	token.IssuedAt = numericDate(claims["iat"])
+
+	if token.Issuer != metadata.Issuer || token.Subject == "" || !contains(token.Audience, c.config.ClientID) {
+		return nil, ErrInvalidIDToken
+	}
+	// 有多个 audience 时 azp 必须是当前客户端
+	if azp := token.String("azp"); azp != "" && azp != c.config.ClientID {
+		return nil, ErrInvalidIDToken
+	}
+	now := time.Now()
+	if token.Expiry.IsZero() || now.After(token.Expiry.Add(clockSkew)) || token.IssuedAt.After(now.Add(clockSkew)) {
+		return nil, ErrInvalidIDToken
+	}
+	if subtle.ConstantTimeCompare([]byte(token.String("nonce")), []byte(nonce)) != 1 {
+		return nil, ErrInvalidIDToken
+	}
+	return token, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func numericDate(v interface{}) time.Time {
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(int64(f), 0)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/pkg/oidc"
+	"github.com/wuwen/hello-go/internal/pkg/oidc/oidctest"
+)
+
+const testRedirectURL = "http://localhost/api/v1/auth/oidc/callback"
+
+func newTestClient(t *testing.T) (*oidctest.Provider, *oidc.Client) {
+	t.Helper()
+	provider := oidctest.NewProvider("cms", "secret")
+	t.Cleanup(provider.Close)
+	provider.SetClaims(map[string]interface{}{"sub": "user-1", "email": "alice@example.com"})
+	client := oidc.NewClient(oidc.Config{
+		Issuer:       provider.Issuer(),
+		ClientID:     "cms",
+		ClientSecret: "secret",
+		RedirectURL:  testRedirectURL,
+	}, nil)
+	return provider, client
+}
+
+// authorize 走一遍浏览器跳转，返回授权码
+func authorize(t *testing.T, provider *oidctest.Provider, client *oidc.Client, state, nonce, verifier string) string {
+	t.Helper()
+	authURL, err := client.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback, err := provider.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := callback.Query().Get("state"); got != state {
+		t.Fatalf("callback state = %q, want %q", got, state)
+	}
+	code := callback.Query().Get("code")
+	if code == "" {
+		t.Fatalf("no code in callback %s", callback)
+	}
+	return code
+}
+
+func TestAuthCodeFlowWithPKCE(t *testing.T) {
+	provider, client := newTestClient(t)
+	ctx := context.Background()
+	verifier := oidc.NewVerifier()
+
+	code := authorize(t, provider, client, "state-1", "nonce-1", verifier)
+	token, err := client.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idToken, err := client.Verify(ctx, token.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idToken.Subject != "user-1" || idToken.String("email") != "alice@example.com" {
+		t.Fatalf("id token subject %q email %q", idToken.Subject, idToken.String("email"))
+	}
+
+	// 授权码只能使用一次
+	if _, err := client.Exchange(ctx, code, verifier); err == nil {
+		t.Fatal("reusing an authorization code succeeded")
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	provider, client := newTestClient(t)
+
+	code := authorize(t, provider, client, "state-1", "nonce-1", oidc.NewVerifier())
+	if _, err := client.Exchange(context.Background(), code, oidc.NewVerifier()); err == nil {
+		t.Fatal("exchange with another code verifier succeeded")
+	}
+}
+
+func TestVerifyRejectsNonceMismatch(t *testing.T) {
+	provider, client := newTestClient(t)
+	ctx := context.Background()
+	verifier := oidc.NewVerifier()
+
+	code := authorize(t, provider, client, "state-1", "nonce-1", verifier)
+	token, err := client.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Verify(ctx, token.IDToken, "nonce-2"); err != oidc.ErrInvalidIDToken {
+		t.Fatalf("Verify with another nonce = %v, want ErrInvalidIDToken", err)
+	}
+}
+
+func TestVerifyRejectsForgedTokens(t *testing.T) {
+	provider, client := newTestClient(t)
+	ctx := context.Background()
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":   provider.Issuer(),
+		"aud":   "cms",
+		"sub":   "user-1",
+		"nonce": "nonce-1",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Minute).Unix(),
+	}
+
+	other := oidctest.NewProvider("cms", "secret")
+	defer other.Close()
+
+	valid := provider.Sign(claims)
+	if _, err := client.Verify(ctx, valid, "nonce-1"); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+
+	tests := map[string]string{
+		"alg none":        tokenWithAlg(t, "none", claims, nil),
+		"alg HS256":       tokenWithAlg(t, "HS256", claims, []byte("secret")),
+		"wrong audience":  provider.Sign(with(claims, "aud", "other")),
+		"wrong issuer":    provider.Sign(with(claims, "iss", "https://evil.example.com")),
+		"expired":         provider.Sign(with(claims, "exp", now.Add(-time.Hour).Unix())),
+		"missing subject": provider.Sign(with(claims, "sub", "")),
+		"foreign key":     other.Sign(claims),
+	}
+	for name, raw := range tests {
+		if _, err := client.Verify(ctx, raw, "nonce-1"); err != oidc.ErrInvalidIDToken {
+			t.Errorf("%s: Verify = %v, want ErrInvalidIDToken", name, err)
+		}
+	}
+}
+
+// tokenWithAlg 构造指定 alg 的 token，key 不为空时用 HMAC-SHA256 签名
+func tokenWithAlg(t *testing.T, alg string, claims map[string]interface{}, key []byte) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	if key == nil {
+		return input + "."
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func with(claims map[string]interface{}, name string, value interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(claims))
+	for k, v := range claims {
+		copied[k] = v
+	}
+	copied[name] = value
+	return copied
+}
@@ -33,3 +33,12 @@ func Error(c *gin.Context, code int, message string) {
 		Message: message,
 	})
 }
+
+// ErrorWithData 返回错误并附带详细信息，例如字段级别的校验错误
+func ErrorWithData(c *gin.Context, code int, message string, data interface{}) {
+	c.JSON(code, Response{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	})
+}
@@ -0,0 +1,29 @@
+package theme
+
+// Loader 持有当前使用的主题。开启 reload 后每次获取主题都会重新读取模板，
+// 修改主题文件后刷新页面即可看到效果，仅用于调试
+type Loader struct {
+	dir    string
+	reload bool
+	theme  *Theme
+}
+
+func NewLoader(dir string, reload bool) (*Loader, error) {
+	theme, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Loader{
+		dir:    dir,
+		reload: reload,
+		theme:  theme,
+	}, nil
+}
+
+// Theme 返回当前主题，重新加载失败时返回错误，便于直接看到模板中的问题
+func (l *Loader) Theme() (*Theme, error) {
+	if !l.reload {
+		return l.theme, nil
+	}
+	return Load(l.dir)
+}
@@ -0,0 +1,35 @@
+package theme
+
+import (
+	"html/template"
+
+	"github.com/wuwen/hello-go/internal/model"
+)
+
+// Site 站点信息
+type Site struct {
+	Name    string
+	BaseURL string
+}
+
+// Pagination 列表页的分页信息，链接为空表示没有上一页或下一页
+type Pagination struct {
+	Page       int
+	TotalPages int
+	PrevURL    string
+	NextURL    string
+}
+
+// Page 传给页面模板的数据
+type Page struct {
+	Site       Site
+	Title      string
+	Meta       template.HTML // 放进 <head> 的 meta 标签
+	Article    *model.Article
+	Articles   []*model.Article
+	Pagination *Pagination
+	Author     string // 作者页的用户名
+	Query      string // 搜索页的关键词
+	Message    string // 错误页的提示
+	Dynamic    bool   // 由服务端渲染，可以链接到搜索等动态页面；静态导出时为 false
+}
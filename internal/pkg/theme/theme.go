@@ -0,0 +1,206 @@
+package theme
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 内置的默认主题，主题目录中的同名文件会覆盖它们
+//
+//go:embed all:templates static
+var defaults embed.FS
+
+// layoutTemplate 所有页面共用的布局，以 "_" 开头的模板作为公共片段
+const layoutTemplate = "layout.html"
+
+// Theme 一组解析好的页面模板。每个页面模板与布局和公共片段组合后单独解析，
+// 因此页面之间可以定义同名的 block
+type Theme struct {
+	dir         string
+	pages       map[string]*template.Template
+	fingerprint string
+}
+
+// Load 加载内置模板并用 dir/templates 下的同名文件覆盖，dir 为空时只使用内置主题
+func Load(dir string) (*Theme, error) {
+	sources, err := readSources(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, ok := sources[layoutTemplate]
+	if !ok {
+		return nil, fmt.Errorf("theme: missing %s", layoutTemplate)
+	}
+	base, err := template.New(layoutTemplate).Funcs(funcs).Parse(layout)
+	if err != nil {
+		return nil, fmt.Errorf("theme: parse %s: %v", layoutTemplate, err)
+	}
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if strings.HasPrefix(name, "_") {
+			if _, err := base.New(name).Parse(sources[name]); err != nil {
+				return nil, fmt.Errorf("theme: parse %s: %v", name, err)
+			}
+		}
+	}
+
+	hash := sha256.New()
+	pages := make(map[string]*template.Template)
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s\x00%s\x00", name, sources[name])
+		if name == layoutTemplate || strings.HasPrefix(name, "_") {
+			continue
+		}
+		page, err := base.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := page.New(name).Parse(sources[name]); err != nil {
+			return nil, fmt.Errorf("theme: parse %s: %v", name, err)
+		}
+		pages[strings.TrimSuffix(name, ".html")] = page
+	}
+
+	return &Theme{
+		dir:         dir,
+		pages:       pages,
+		fingerprint: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// Fingerprint 模板内容的摘要，模板变化后需要重新渲染所有页面
+func (t *Theme) Fingerprint() string {
+	return t.fingerprint
+}
+
+// Render 用布局渲染指定页面，page 为不带扩展名的模板名，例如 "article"
+func (t *Theme) Render(w io.Writer, page string, data interface{}) error {
+	tmpl, ok := t.pages[page]
+	if !ok {
+		return fmt.Errorf("theme: page %q not found", page)
+	}
+	return tmpl.ExecuteTemplate(w, layoutTemplate, data)
+}
+
+// CopyStatic 将静态资源复制到 dst/static，主题目录中的文件覆盖内置文件
+func (t *Theme) CopyStatic(dst string) error {
+	static, err := fs.Sub(defaults, "static")
+	if err != nil {
+		return err
+	}
+	if err := copyFS(static, filepath.Join(dst, "static")); err != nil {
+		return err
+	}
+
+	if t.dir == "" {
+		return nil
+	}
+	dir := filepath.Join(t.dir, "static")
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	return copyFS(os.DirFS(dir), filepath.Join(dst, "static"))
+}
+
+// StaticFS 返回合并后的静态资源，主题目录中的文件优先
+func (t *Theme) StaticFS() fs.FS {
+	static, _ := fs.Sub(defaults, "static")
+	if t.dir == "" {
+		return static
+	}
+	return overlayFS{upper: os.DirFS(filepath.Join(t.dir, "static")), lower: static}
+}
+
+func readSources(dir string) (map[string]string, error) {
+	sources := make(map[string]string)
+
+	entries, err := fs.ReadDir(defaults, "templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		b, err := fs.ReadFile(defaults, path.Join("templates", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		sources[entry.Name()] = string(b)
+	}
+
+	if dir == "" {
+		return sources, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "templates", "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		sources[filepath.Base(file)] = string(b)
+	}
+	return sources, nil
+}
+
+func copyFS(src fs.FS, dst string) error {
+	return fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, filepath.FromSlash(name))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		b, err := fs.ReadFile(src, name)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, b, 0644)
+	})
+}
+
+// overlayFS 优先从 upper 读取文件，不存在时回退到 lower
+type overlayFS struct {
+	upper fs.FS
+	lower fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if f, err := o.upper.Open(name); err == nil {
+		return f, nil
+	}
+	return o.lower.Open(name)
+}
+
+var funcs = template.FuncMap{
+	"date": func(t interface{}) string {
+		switch v := t.(type) {
+		case time.Time:
+			return v.Format("2006-01-02")
+		case *time.Time:
+			if v != nil {
+				return v.Format("2006-01-02")
+			}
+		}
+		return ""
+	},
+}
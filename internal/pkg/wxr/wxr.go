@@ -0,0 +1,119 @@
+package wxr
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+)
+
+// Export WXR 文件中的站点数据
+type Export struct {
+	Title      string     `xml:"title"`
+	Link       string     `xml:"link"`
+	BaseURL    string     `xml:"base_site_url"`
+	Authors    []Author   `xml:"author"`
+	Categories []Category `xml:"category"`
+	Tags       []Tag      `xml:"tag"`
+	Items      []Item     `xml:"item"`
+}
+
+type Author struct {
+	ID          int    `xml:"author_id"`
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+type Category struct {
+	TermID   int    `xml:"term_id"`
+	Nicename string `xml:"category_nicename"`
+	Parent   string `xml:"category_parent"`
+	Name     string `xml:"cat_name"`
+}
+
+type Tag struct {
+	TermID int    `xml:"term_id"`
+	Slug   string `xml:"tag_slug"`
+	Name   string `xml:"tag_name"`
+}
+
+// Item 文章、页面、附件等内容
+type Item struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Creator       string    `xml:"creator"`
+	Encoded       []encoded `xml:"encoded"`
+	PostID        int       `xml:"post_id"`
+	PostDate      string    `xml:"post_date"`
+	PostDateGMT   string    `xml:"post_date_gmt"`
+	Status        string    `xml:"status"`
+	PostType      string    `xml:"post_type"`
+	PostPassword  string    `xml:"post_password"`
+	AttachmentURL string    `xml:"attachment_url"`
+	Terms         []Term    `xml:"category"`
+	Comments      []Comment `xml:"comment"`
+}
+
+// encoded content:encoded 和 excerpt:encoded 同名，只能按命名空间区分
+type encoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// Term 内容所属的分类或标签，Domain 为 category 或 post_tag
+type Term struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type Comment struct {
+	ID       int    `xml:"comment_id"`
+	Author   string `xml:"comment_author"`
+	Content  string `xml:"comment_content"`
+	Approved string `xml:"comment_approved"`
+}
+
+// Content 正文
+func (i *Item) Content() string {
+	for _, e := range i.Encoded {
+		if strings.HasPrefix(e.XMLName.Space, "http://purl.org/rss/1.0/modules/content") {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+// Excerpt 摘要
+func (i *Item) Excerpt() string {
+	for _, e := range i.Encoded {
+		if strings.HasSuffix(strings.TrimSuffix(e.XMLName.Space, "/"), "excerpt") {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+// PublishedAt 返回发布时间，优先使用 UTC 时间
+func (i *Item) PublishedAt() *time.Time {
+	for _, value := range []string{i.PostDateGMT, i.PostDate} {
+		if t, err := time.Parse("2006-01-02 15:04:05", value); err == nil && t.Year() > 1 {
+			return &t
+		}
+	}
+	return nil
+}
+
+// Parse 解析 WXR 文件
+func Parse(r io.Reader) (*Export, error) {
+	var doc struct {
+		Channel Export `xml:"channel"`
+	}
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return &doc.Channel, nil
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/wuwen/hello-go/internal/model"
 	"gorm.io/gorm"
 )
@@ -25,16 +27,35 @@ func (r *ArticleRepository) GetByID(id uint) (*model.Article, error) {
 	return &article, nil
 }
 
-func (r *ArticleRepository) List(page, pageSize int) ([]*model.Article, int64, error) {
+func (r *ArticleRepository) ListByIDs(ids []uint) ([]*model.Article, error) {
+	var articles []*model.Article
+	if len(ids) == 0 {
+		return articles, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// List 分页查询已发布且可见性在 visibilities 中的文章，
+// collaboratorID 不为 0 时还包括该用户参与协作的全部文章
+func (r *ArticleRepository) List(page, pageSize int, visibilities []string, collaboratorID uint) ([]*model.Article, int64, error) {
 	var articles []*model.Article
 	var total int64
 
-	if err := r.db.Model(&model.Article{}).Count(&total).Error; err != nil {
+	listed := r.db.Where("status = ? AND visibility IN ?", model.ArticleStatusPublished, visibilities)
+	if collaboratorID != 0 {
+		granted := r.db.Model(&model.ArticleGrant{}).Select("article_id").Where("user_id = ?", collaboratorID)
+		listed = listed.Or("id IN (?)", granted)
+	}
+	query := r.db.Model(&model.Article{}).Where(listed)
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * pageSize
-	if err := r.db.Offset(offset).Limit(pageSize).Find(&articles).Error; err != nil {
+	if err := query.Offset(offset).Limit(pageSize).Find(&articles).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -48,3 +69,61 @@ func (r *ArticleRepository) Update(article *model.Article) error {
 func (r *ArticleRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Article{}, id).Error
 }
+
+// ListPublished 返回全部已发布的文章，按发布时间倒序
+func (r *ArticleRepository) ListPublished(visibilities []string) ([]*model.Article, error) {
+	var articles []*model.Article
+	err := r.db.Where("status = ? AND visibility IN ?", model.ArticleStatusPublished, visibilities).
+		Order("published_at DESC").Order("id DESC").Find(&articles).Error
+	if err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// ListPublishedBetween 返回发布时间在 [from, to) 之间的文章，按发布时间排序
+func (r *ArticleRepository) ListPublishedBetween(from, to time.Time) ([]*model.Article, error) {
+	var articles []*model.Article
+	err := r.db.Where("status = ? AND published_at >= ? AND published_at < ?", model.ArticleStatusPublished, from, to).
+		Order("published_at").Order("id").Find(&articles).Error
+	if err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// PublishedQuery 已发布文章的筛选条件
+type PublishedQuery struct {
+	Visibilities []string
+	Keyword      string // 按标题、摘要和正文模糊搜索
+	OwnerID      uint   // 只返回该用户拥有的文章
+}
+
+// ListPublishedPage 分页查询已发布的文章，按发布时间倒序
+func (r *ArticleRepository) ListPublishedPage(q *PublishedQuery, page, pageSize int) ([]*model.Article, int64, error) {
+	var articles []*model.Article
+	var total int64
+
+	query := r.db.Model(&model.Article{}).
+		Where("status = ? AND visibility IN ?", model.ArticleStatusPublished, q.Visibilities)
+	if q.Keyword != "" {
+		like := "%" + q.Keyword + "%"
+		query = query.Where("title LIKE ? OR excerpt LIKE ? OR content LIKE ?", like, like, like)
+	}
+	if q.OwnerID != 0 {
+		owned := r.db.Model(&model.ArticleGrant{}).Select("article_id").
+			Where("user_id = ? AND role = ?", q.OwnerID, model.GrantRoleOwner)
+		query = query.Where("id IN (?)", owned)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("published_at DESC").Order("id DESC").Offset(offset).Limit(pageSize).Find(&articles).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return articles, total, nil
+}
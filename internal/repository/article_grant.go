@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type ArticleGrantRepository struct {
+	db *gorm.DB
+}
+
+func NewArticleGrantRepository(db *gorm.DB) *ArticleGrantRepository {
+	return &ArticleGrantRepository{db: db}
+}
+
+func (r *ArticleGrantRepository) Find(articleID, userID uint) (*model.ArticleGrant, error) {
+	var grant model.ArticleGrant
+	if err := r.db.Where("article_id = ? AND user_id = ?", articleID, userID).First(&grant).Error; err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (r *ArticleGrantRepository) ListByArticle(articleID uint) ([]*model.ArticleGrant, error) {
+	var grants []*model.ArticleGrant
+	if err := r.db.Where("article_id = ?", articleID).Order("id").Find(&grants).Error; err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// Save 新增或更新用户在文章上的角色
+func (r *ArticleGrantRepository) Save(articleID, userID uint, role string) (*model.ArticleGrant, error) {
+	grant, err := r.Find(articleID, userID)
+	if err != nil {
+		grant = &model.ArticleGrant{ArticleID: articleID, UserID: userID}
+	}
+	grant.Role = role
+	if err := r.db.Save(grant).Error; err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (r *ArticleGrantRepository) Delete(articleID, userID uint) error {
+	return r.db.Where("article_id = ? AND user_id = ?", articleID, userID).Delete(&model.ArticleGrant{}).Error
+}
+
+func (r *ArticleGrantRepository) DeleteByArticle(articleID uint) error {
+	return r.db.Where("article_id = ?", articleID).Delete(&model.ArticleGrant{}).Error
+}
+
+// TransferOwnership 在事务中转移所有权，原所有者降为编辑者
+func (r *ArticleGrantRepository) TransferOwnership(articleID, toUserID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.ArticleGrant{}).
+			Where("article_id = ? AND role = ?", articleID, model.GrantRoleOwner).
+			Update("role", model.GrantRoleEditor).Error; err != nil {
+			return err
+		}
+
+		var grant model.ArticleGrant
+		err := tx.Where("article_id = ? AND user_id = ?", articleID, toUserID).First(&grant).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		grant.ArticleID = articleID
+		grant.UserID = toUserID
+		grant.Role = model.GrantRoleOwner
+		return tx.Save(&grant).Error
+	})
+}
+
+// FindOwner 返回文章的所有者
+func (r *ArticleGrantRepository) FindOwner(articleID uint) (*model.ArticleGrant, error) {
+	var grant model.ArticleGrant
+	err := r.db.Where("article_id = ? AND role = ?", articleID, model.GrantRoleOwner).First(&grant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
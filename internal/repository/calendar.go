@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type CalendarRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarRepository(db *gorm.DB) *CalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+func (r *CalendarRepository) FindFeedByToken(tokenHash string) (*model.CalendarFeed, error) {
+	var feed model.CalendarFeed
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&feed).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// SaveFeed 创建或替换用户的订阅 token
+func (r *CalendarRepository) SaveFeed(userID uint, tokenHash string) (*model.CalendarFeed, error) {
+	var feed model.CalendarFeed
+	if err := r.db.Where("user_id = ?", userID).First(&feed).Error; err != nil {
+		feed = model.CalendarFeed{UserID: userID}
+	}
+	feed.TokenHash = tokenHash
+	feed.LastAccessedAt = nil
+	if err := r.db.Save(&feed).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *CalendarRepository) DeleteFeed(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&model.CalendarFeed{}).Error
+}
+
+func (r *CalendarRepository) TouchFeed(id uint, at time.Time) error {
+	return r.db.Model(&model.CalendarFeed{}).Where("id = ?", id).Update("last_accessed_at", at).Error
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type ContentEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewContentEntryRepository(db *gorm.DB) *ContentEntryRepository {
+	return &ContentEntryRepository{db: db}
+}
+
+func (r *ContentEntryRepository) Create(entry *model.ContentEntry) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *ContentEntryRepository) GetByID(typeID, id uint) (*model.ContentEntry, error) {
+	var entry model.ContentEntry
+	if err := r.db.Where("content_type_id = ?", typeID).First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *ContentEntryRepository) List(typeID uint, page, pageSize int) ([]*model.ContentEntry, int64, error) {
+	var entries []*model.ContentEntry
+	var total int64
+
+	query := r.db.Model(&model.ContentEntry{}).Where("content_type_id = ?", typeID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+func (r *ContentEntryRepository) CountByType(typeID uint) (int64, error) {
+	var total int64
+	err := r.db.Model(&model.ContentEntry{}).Where("content_type_id = ?", typeID).Count(&total).Error
+	return total, err
+}
+
+func (r *ContentEntryRepository) Update(entry *model.ContentEntry) error {
+	return r.db.Save(entry).Error
+}
+
+func (r *ContentEntryRepository) Delete(typeID, id uint) error {
+	return r.db.Where("content_type_id = ?", typeID).Delete(&model.ContentEntry{}, id).Error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type ContentTypeRepository struct {
+	db *gorm.DB
+}
+
+func NewContentTypeRepository(db *gorm.DB) *ContentTypeRepository {
+	return &ContentTypeRepository{db: db}
+}
+
+func (r *ContentTypeRepository) Create(contentType *model.ContentType) error {
+	return r.db.Create(contentType).Error
+}
+
+func (r *ContentTypeRepository) GetByID(id uint) (*model.ContentType, error) {
+	var contentType model.ContentType
+	if err := r.db.First(&contentType, id).Error; err != nil {
+		return nil, err
+	}
+	return &contentType, nil
+}
+
+func (r *ContentTypeRepository) GetByName(name string) (*model.ContentType, error) {
+	var contentType model.ContentType
+	if err := r.db.Where("name = ?", name).First(&contentType).Error; err != nil {
+		return nil, err
+	}
+	return &contentType, nil
+}
+
+func (r *ContentTypeRepository) List() ([]*model.ContentType, error) {
+	var contentTypes []*model.ContentType
+	if err := r.db.Order("name").Find(&contentTypes).Error; err != nil {
+		return nil, err
+	}
+	return contentTypes, nil
+}
+
+func (r *ContentTypeRepository) Update(contentType *model.ContentType) error {
+	return r.db.Save(contentType).Error
+}
+
+func (r *ContentTypeRepository) Delete(id uint) error {
+	return r.db.Delete(&model.ContentType{}, id).Error
+}
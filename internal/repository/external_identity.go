@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type ExternalIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewExternalIdentityRepository(db *gorm.DB) *ExternalIdentityRepository {
+	return &ExternalIdentityRepository{db: db}
+}
+
+func (r *ExternalIdentityRepository) Find(issuer, subject string) (*model.ExternalIdentity, error) {
+	var identity model.ExternalIdentity
+	if err := r.db.Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *ExternalIdentityRepository) Create(identity *model.ExternalIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *ExternalIdentityRepository) Touch(id uint, at time.Time) error {
+	return r.db.Model(&model.ExternalIdentity{}).Where("id = ?", id).Update("last_login_at", at).Error
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type ImportRepository struct {
+	db *gorm.DB
+}
+
+func NewImportRepository(db *gorm.DB) *ImportRepository {
+	return &ImportRepository{db: db}
+}
+
+func (r *ImportRepository) Find(source, kind, externalID string) (*model.ImportMapping, error) {
+	var mapping model.ImportMapping
+	err := r.db.Where("source = ? AND kind = ? AND external_id = ?", source, kind, externalID).First(&mapping).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+func (r *ImportRepository) Create(mapping *model.ImportMapping) error {
+	return r.db.Create(mapping).Error
+}
+
+// CreateUser 在一个事务中创建用户及其导入记录，mapping.TargetID 由新用户 ID 填充
+func (r *ImportRepository) CreateUser(user *model.User, mapping *model.ImportMapping) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		mapping.TargetID = user.ID
+		return tx.Create(mapping).Error
+	})
+}
+
+// CreateArticle 在一个事务中创建文章、所有者权限及导入记录，mapping.TargetID 由新文章 ID 填充
+func (r *ImportRepository) CreateArticle(article *model.Article, ownerID uint, mapping *model.ImportMapping) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(article).Error; err != nil {
+			return err
+		}
+		grant := &model.ArticleGrant{ArticleID: article.ID, UserID: ownerID, Role: model.GrantRoleOwner}
+		if err := tx.Create(grant).Error; err != nil {
+			return err
+		}
+		mapping.TargetID = article.ID
+		return tx.Create(mapping).Error
+	})
+}
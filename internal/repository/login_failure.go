@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginFailureRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginFailureRepository(db *gorm.DB) *LoginFailureRepository {
+	return &LoginFailureRepository{db: db}
+}
+
+// FindByTargets 查询多个对象的失败记录，没有记录的不返回
+func (r *LoginFailureRepository) FindByTargets(targets []string) ([]*model.LoginFailure, error) {
+	var failures []*model.LoginFailure
+	if err := r.db.Where("target IN ?", targets).Find(&failures).Error; err != nil {
+		return nil, err
+	}
+	return failures, nil
+}
+
+// Increment 原子地为 target 记一次失败并返回更新后的记录。
+// 最后一次失败早于 windowStart 且未处于锁定中时从 1 重新计数
+func (r *LoginFailureRepository) Increment(target string, now, windowStart time.Time) (*model.LoginFailure, error) {
+	var failure model.LoginFailure
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.LoginFailure{Target: target, LastFailedAt: now}).Error; err != nil {
+			return err
+		}
+		// MySQL 按顺序执行 SET，failures 需要在 last_failed_at 之前计算，map 的列按名称排序
+		if err := tx.Model(&model.LoginFailure{}).Where("target = ?", target).Updates(map[string]interface{}{
+			"failures": gorm.Expr("CASE WHEN last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?) "+
+				"THEN 1 ELSE failures + 1 END", windowStart, now),
+			"last_failed_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("target = ?", target).First(&failure).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &failure, nil
+}
+
+// Lock 锁定 target 到 until，已处于锁定中时不修改并返回 false
+func (r *LoginFailureRepository) Lock(target string, until, now time.Time) (bool, error) {
+	result := r.db.Model(&model.LoginFailure{}).
+		Where("target = ? AND (locked_until IS NULL OR locked_until < ?)", target, now).
+		Update("locked_until", until)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *LoginFailureRepository) Delete(target string) (int64, error) {
+	result := r.db.Where("target = ?", target).Delete(&model.LoginFailure{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteStale 删除最后一次失败早于 before 且未处于锁定中的记录
+func (r *LoginFailureRepository) DeleteStale(before, now time.Time) (int64, error) {
+	result := r.db.Where("last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, now).
+		Delete(&model.LoginFailure{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+func (r *PasswordResetRepository) Create(reset *model.PasswordReset) error {
+	return r.db.Create(reset).Error
+}
+
+func (r *PasswordResetRepository) FindByHash(tokenHash string) (*model.PasswordReset, error) {
+	var reset model.PasswordReset
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&reset).Error; err != nil {
+		return nil, err
+	}
+	return &reset, nil
+}
+
+// LatestByUser 返回用户最近一次申请的 token
+func (r *PasswordResetRepository) LatestByUser(userID uint) (*model.PasswordReset, error) {
+	var reset model.PasswordReset
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&reset).Error; err != nil {
+		return nil, err
+	}
+	return &reset, nil
+}
+
+// MarkUsed 将 token 标记为已使用，只有第一次调用返回 true
+func (r *PasswordResetRepository) MarkUsed(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&model.PasswordReset{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteUnusedByUser 删除用户尚未使用的 token，新申请或重置成功后旧链接随之失效
+func (r *PasswordResetRepository) DeleteUnusedByUser(userID uint) error {
+	return r.db.Where("user_id = ? AND used_at IS NULL", userID).Delete(&model.PasswordReset{}).Error
+}
+
+// DeleteExpired 删除过期时间早于 before 的 token
+func (r *PasswordResetRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&model.PasswordReset{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type PersonalTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalTokenRepository(db *gorm.DB) *PersonalTokenRepository {
+	return &PersonalTokenRepository{db: db}
+}
+
+func (r *PersonalTokenRepository) Create(token *model.PersonalToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *PersonalTokenRepository) FindByHash(tokenHash string) (*model.PersonalToken, error) {
+	var token model.PersonalToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *PersonalTokenRepository) ListByUser(userID uint) ([]model.PersonalToken, error) {
+	var tokens []model.PersonalToken
+	err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// Delete 删除用户的 token，返回是否删除了记录
+func (r *PersonalTokenRepository) Delete(userID, id uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.PersonalToken{})
+	return result.RowsAffected == 1, result.Error
+}
+
+// DeleteByUser 删除用户的全部 token
+func (r *PersonalTokenRepository) DeleteByUser(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&model.PersonalToken{}).Error
+}
+
+// Touch 记录最近使用时间，距上次记录不足 interval 时不更新，避免每个请求都写库
+func (r *PersonalTokenRepository) Touch(id uint, at time.Time, interval time.Duration) error {
+	return r.db.Model(&model.PersonalToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, at.Add(-interval)).
+		Update("last_used_at", at).Error
+}
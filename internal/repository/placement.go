@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type PlacementRepository struct {
+	db *gorm.DB
+}
+
+func NewPlacementRepository(db *gorm.DB) *PlacementRepository {
+	return &PlacementRepository{db: db}
+}
+
+// ListAll 返回所有展示位的文章，按展示位和顺序排列
+func (r *PlacementRepository) ListAll() ([]*model.PlacementItem, error) {
+	var items []*model.PlacementItem
+	if err := r.db.Order("placement").Order("position").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListActive 按顺序返回展示位上尚未过期的文章
+func (r *PlacementRepository) ListActive(placement string, now time.Time) ([]*model.PlacementItem, error) {
+	var items []*model.PlacementItem
+	err := r.db.Where("placement = ? AND (expires_at IS NULL OR expires_at > ?)", placement, now).
+		Order("position").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Replace 用新的文章列表整体替换展示位
+func (r *PlacementRepository) Replace(placement string, items []*model.PlacementItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("placement = ?", placement).Delete(&model.PlacementItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Create(&items).Error
+	})
+}
+
+func (r *PlacementRepository) Delete(placement string) error {
+	return r.db.Where("placement = ?", placement).Delete(&model.PlacementItem{}).Error
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type PreviewRepository struct {
+	db *gorm.DB
+}
+
+func NewPreviewRepository(db *gorm.DB) *PreviewRepository {
+	return &PreviewRepository{db: db}
+}
+
+func (r *PreviewRepository) Create(link *model.PreviewLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *PreviewRepository) GetByID(id uint) (*model.PreviewLink, error) {
+	var link model.PreviewLink
+	if err := r.db.First(&link, id).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *PreviewRepository) ListByArticle(articleID uint) ([]*model.PreviewLink, error) {
+	var links []*model.PreviewLink
+	if err := r.db.Where("article_id = ?", articleID).Order("id DESC").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *PreviewRepository) Revoke(id uint, at time.Time) error {
+	return r.db.Model(&model.PreviewLink{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+// RecordAccess 记录一次访问并更新链接的访问统计
+func (r *PreviewRepository) RecordAccess(access *model.PreviewAccess) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(access).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.PreviewLink{}).
+			Where("id = ?", access.PreviewLinkID).
+			Updates(map[string]interface{}{
+				"access_count":     gorm.Expr("access_count + 1"),
+				"last_accessed_at": access.CreatedAt,
+			}).Error
+	})
+}
+
+func (r *PreviewRepository) ListAccesses(linkID uint, page, pageSize int) ([]*model.PreviewAccess, int64, error) {
+	var accesses []*model.PreviewAccess
+	var total int64
+
+	query := r.db.Model(&model.PreviewAccess{}).Where("preview_link_id = ?", linkID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&accesses).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return accesses, total, nil
+}
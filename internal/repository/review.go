@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+func (r *ReviewRepository) Find(articleID, reviewerID uint) (*model.ArticleReview, error) {
+	var review model.ArticleReview
+	if err := r.db.Where("article_id = ? AND reviewer_id = ?", articleID, reviewerID).First(&review).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *ReviewRepository) ListByArticle(articleID uint) ([]*model.ArticleReview, error) {
+	var reviews []*model.ArticleReview
+	if err := r.db.Where("article_id = ?", articleID).Order("id").Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// ListPendingByReviewer 返回审核人尚未给出结论、且文章仍在审核中的记录
+func (r *ReviewRepository) ListPendingByReviewer(reviewerID uint) ([]*model.ArticleReview, error) {
+	var reviews []*model.ArticleReview
+	inReview := r.db.Model(&model.Article{}).Select("id").Where("status = ?", model.ArticleStatusInReview)
+	err := r.db.Where("reviewer_id = ? AND decision = ? AND article_id IN (?)", reviewerID, model.ReviewDecisionPending, inReview).
+		Order("due_at IS NULL").Order("due_at").Order("id").Find(&reviews).Error
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// ListPendingDueBetween 返回截止时间在 [from, to) 之间、尚未给出结论的记录
+func (r *ReviewRepository) ListPendingDueBetween(from, to time.Time) ([]*model.ArticleReview, error) {
+	var reviews []*model.ArticleReview
+	inReview := r.db.Model(&model.Article{}).Select("id").Where("status = ?", model.ArticleStatusInReview)
+	err := r.db.Where("decision = ? AND due_at >= ? AND due_at < ? AND article_id IN (?)", model.ReviewDecisionPending, from, to, inReview).
+		Order("due_at").Order("id").Find(&reviews).Error
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// ListSubmittedBetween 返回仍在审核中、于 [from, to) 之间提交审核的文章的审核记录
+func (r *ReviewRepository) ListSubmittedBetween(from, to time.Time) ([]*model.ArticleReview, error) {
+	var reviews []*model.ArticleReview
+	inReview := r.db.Model(&model.Article{}).Select("id").Where("status = ?", model.ArticleStatusInReview)
+	err := r.db.Where("created_at >= ? AND created_at < ? AND article_id IN (?)", from, to, inReview).
+		Order("created_at").Order("id").Find(&reviews).Error
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (r *ReviewRepository) CountByDecision(articleID uint, decision string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ArticleReview{}).Where("article_id = ? AND decision = ?", articleID, decision).Count(&count).Error
+	return count, err
+}
+
+// Replace 在事务中用新指派的审核人替换文章原有的审核记录
+func (r *ReviewRepository) Replace(articleID uint, reviews []*model.ArticleReview) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("article_id = ?", articleID).Delete(&model.ArticleReview{}).Error; err != nil {
+			return err
+		}
+		if len(reviews) == 0 {
+			return nil
+		}
+		return tx.Create(&reviews).Error
+	})
+}
+
+func (r *ReviewRepository) Update(review *model.ArticleReview) error {
+	return r.db.Save(review).Error
+}
+
+func (r *ReviewRepository) DeleteByArticle(articleID uint) error {
+	return r.db.Where("article_id = ?", articleID).Delete(&model.ArticleReview{}).Error
+}
@@ -36,6 +36,15 @@ func (r *RoleRepository) FindByName(name string) (*model.Role, error) {
 	return &role, nil
 }
 
+// ListRequiringTwoFactor 列出要求两步验证的角色
+func (r *RoleRepository) ListRequiringTwoFactor() ([]*model.Role, error) {
+	var roles []*model.Role
+	if err := r.db.Where("require_two_factor = ?", true).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
 func (r *RoleRepository) Update(role *model.Role) (*model.Role, error) {
 	if err := r.db.Save(role).Error; err != nil {
 		return nil, err
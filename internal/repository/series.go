@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type SeriesRepository struct {
+	db *gorm.DB
+}
+
+func NewSeriesRepository(db *gorm.DB) *SeriesRepository {
+	return &SeriesRepository{db: db}
+}
+
+// Create 创建系列及其文章列表
+func (r *SeriesRepository) Create(series *model.Series, articleIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(series).Error; err != nil {
+			return err
+		}
+		return createParts(tx, series.ID, articleIDs)
+	})
+}
+
+func (r *SeriesRepository) GetByID(id uint) (*model.Series, error) {
+	var series model.Series
+	if err := r.db.First(&series, id).Error; err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (r *SeriesRepository) List(page, pageSize int) ([]*model.Series, int64, error) {
+	var series []*model.Series
+	var total int64
+
+	if err := r.db.Model(&model.Series{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := r.db.Order("id DESC").Offset(offset).Limit(pageSize).Find(&series).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return series, total, nil
+}
+
+// UpdateInfo 只更新标题和描述，不影响版本号
+func (r *SeriesRepository) UpdateInfo(id uint, title, description string) error {
+	return r.db.Model(&model.Series{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"title":       title,
+		"description": description,
+		"updated_at":  time.Now(),
+	}).Error
+}
+
+func (r *SeriesRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("series_id = ?", id).Delete(&model.SeriesPart{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Series{}, id).Error
+	})
+}
+
+// ListParts 按顺序返回系列中的文章
+func (r *SeriesRepository) ListParts(seriesID uint) ([]*model.SeriesPart, error) {
+	var parts []*model.SeriesPart
+	if err := r.db.Where("series_id = ?", seriesID).Order("position").Find(&parts).Error; err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
+
+// FindPartByArticle 查找文章所在的系列条目，文章不属于任何系列时返回 nil
+func (r *SeriesRepository) FindPartByArticle(articleID uint) (*model.SeriesPart, error) {
+	var part model.SeriesPart
+	err := r.db.Where("article_id = ?", articleID).First(&part).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &part, nil
+}
+
+// FindPartsInOtherSeries 查找已属于其他系列的文章
+func (r *SeriesRepository) FindPartsInOtherSeries(seriesID uint, articleIDs []uint) ([]*model.SeriesPart, error) {
+	var parts []*model.SeriesPart
+	if len(articleIDs) == 0 {
+		return parts, nil
+	}
+	err := r.db.Where("article_id IN ? AND series_id <> ?", articleIDs, seriesID).Find(&parts).Error
+	return parts, err
+}
+
+// ReplaceParts 在事务中以乐观锁替换系列的文章顺序。
+// 版本号不匹配时返回 false，表示系列已被其他人修改
+func (r *SeriesRepository) ReplaceParts(seriesID uint, version int, articleIDs []uint) (bool, error) {
+	updated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Series{}).
+			Where("id = ? AND version = ?", seriesID, version).
+			Updates(map[string]interface{}{
+				"version":    gorm.Expr("version + 1"),
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Where("series_id = ?", seriesID).Delete(&model.SeriesPart{}).Error; err != nil {
+			return err
+		}
+		if err := createParts(tx, seriesID, articleIDs); err != nil {
+			return err
+		}
+		updated = true
+		return nil
+	})
+	return updated, err
+}
+
+// RemoveArticle 将文章从所属系列中移除
+func (r *SeriesRepository) RemoveArticle(articleID uint) error {
+	return r.db.Where("article_id = ?", articleID).Delete(&model.SeriesPart{}).Error
+}
+
+func createParts(tx *gorm.DB, seriesID uint, articleIDs []uint) error {
+	if len(articleIDs) == 0 {
+		return nil
+	}
+	parts := make([]*model.SeriesPart, 0, len(articleIDs))
+	for i, articleID := range articleIDs {
+		parts = append(parts, &model.SeriesPart{
+			SeriesID:  seriesID,
+			Position:  i + 1,
+			ArticleID: articleID,
+		})
+	}
+	return tx.Create(&parts).Error
+}
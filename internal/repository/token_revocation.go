@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type TokenRevocationRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRevocationRepository(db *gorm.DB) *TokenRevocationRepository {
+	return &TokenRevocationRepository{db: db}
+}
+
+func (r *TokenRevocationRepository) Create(revocation *model.TokenRevocation) error {
+	return r.db.Create(revocation).Error
+}
+
+// ExistsJTI 判断单个 token 是否已被撤销
+func (r *TokenRevocationRepository) ExistsJTI(jti string, now time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.TokenRevocation{}).
+		Where("jti = ? AND expires_at > ?", jti, now).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// LatestCutoff 返回用户最近一次撤销全部 token 的时间，没有时返回零值
+func (r *TokenRevocationRepository) LatestCutoff(userID uint, now time.Time) (time.Time, error) {
+	var revocation model.TokenRevocation
+	err := r.db.Where("user_id = ? AND jti = '' AND expires_at > ?", userID, now).
+		Order("issued_before DESC").First(&revocation).Error
+	if err == gorm.ErrRecordNotFound {
+		return time.Time{}, nil
+	}
+	if err != nil || revocation.IssuedBefore == nil {
+		return time.Time{}, err
+	}
+	return *revocation.IssuedBefore, nil
+}
+
+func (r *TokenRevocationRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&model.TokenRevocation{})
+	return result.RowsAffected, result.Error
+}
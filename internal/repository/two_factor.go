@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+func (r *TwoFactorRepository) Find(userID uint) (*model.TwoFactor, error) {
+	var tf model.TwoFactor
+	if err := r.db.Where("user_id = ?", userID).First(&tf).Error; err != nil {
+		return nil, err
+	}
+	return &tf, nil
+}
+
+// SaveSecret 保存新生成的密钥，覆盖尚未确认的旧密钥
+func (r *TwoFactorRepository) SaveSecret(userID uint, secret string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.TwoFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.TwoFactor{UserID: userID, Secret: secret}).Error
+	})
+}
+
+// Enable 确认启用两步验证并替换备用恢复码
+func (r *TwoFactorRepository) Enable(userID uint, at time.Time, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.TwoFactor{}).Where("user_id = ?", userID).
+			Update("enabled_at", at).Error; err != nil {
+			return err
+		}
+		return replaceBackupCodes(tx, userID, codeHashes)
+	})
+}
+
+// Delete 关闭两步验证，同时删除备用恢复码
+func (r *TwoFactorRepository) Delete(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.BackupCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.TwoFactor{}).Error
+	})
+}
+
+// UseCounter 记录验证码的时间步，时间步不晚于已使用的时间步时返回 false
+func (r *TwoFactorRepository) UseCounter(userID uint, counter int64) (bool, error) {
+	result := r.db.Model(&model.TwoFactor{}).
+		Where("user_id = ? AND last_counter < ?", userID, counter).
+		Updates(map[string]interface{}{"last_counter": counter, "failed_attempts": 0})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RecordFailure 记录一次验证失败，lockedUntil 不为空时同时锁定并清零失败次数
+func (r *TwoFactorRepository) RecordFailure(userID uint, lockedUntil *time.Time) error {
+	updates := map[string]interface{}{"failed_attempts": gorm.Expr("failed_attempts + 1")}
+	if lockedUntil != nil {
+		updates = map[string]interface{}{"failed_attempts": 0, "locked_until": lockedUntil}
+	}
+	return r.db.Model(&model.TwoFactor{}).Where("user_id = ?", userID).Updates(updates).Error
+}
+
+func (r *TwoFactorRepository) ResetFailures(userID uint) error {
+	return r.db.Model(&model.TwoFactor{}).Where("user_id = ?", userID).
+		Update("failed_attempts", 0).Error
+}
+
+func (r *TwoFactorRepository) ReplaceBackupCodes(userID uint, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceBackupCodes(tx, userID, codeHashes)
+	})
+}
+
+// UseBackupCode 将恢复码标记为已使用，只有第一次调用返回 true
+func (r *TwoFactorRepository) UseBackupCode(userID uint, codeHash string, at time.Time) (bool, error) {
+	result := r.db.Model(&model.BackupCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TwoFactorRepository) CountUnusedBackupCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.BackupCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func replaceBackupCodes(tx *gorm.DB, userID uint, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.BackupCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]*model.BackupCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, &model.BackupCode{UserID: userID, CodeHash: hash})
+	}
+	return tx.Create(&codes).Error
+}
@@ -14,9 +14,16 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 }
 
 func (r *UserRepository) Create(user *model.User) (*model.User, error) {
+	// status 字段有默认值，零值（未激活）在插入时会被忽略并回填为默认值，需要单独写入
+	status := user.Status
 	if err := r.db.Create(user).Error; err != nil {
 		return nil, err
 	}
+	if status == model.UserStatusInactive {
+		if err := r.db.Model(user).Update("status", status).Error; err != nil {
+			return nil, err
+		}
+	}
 	return user, nil
 }
 
@@ -28,6 +35,14 @@ func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
 	return &user, nil
 }
 
+func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
+	var user model.User
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *UserRepository) FindById(id uint) (*model.User, error) {
 	var user model.User
 	if err := r.db.First(&user, id).Error; err != nil {
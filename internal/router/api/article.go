@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/wuwen/hello-go/internal/handler"
+	"github.com/wuwen/hello-go/internal/middleware"
 )
 
 type ArticleRouter struct {
@@ -21,11 +22,16 @@ func (r *ArticleRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin
 		// 所有接口都需要认证，因为使用的是 authGroup
 		authArticles.POST("", r.handler.Create)
 		authArticles.PUT("/:id", r.handler.Update)
+		authArticles.GET("/:id/lint", r.handler.Lint)
+		authArticles.POST("/:id/submit", r.handler.Submit)
 		authArticles.DELETE("/:id", r.handler.Delete)
 	}
-	publicArticles := publicGroup.Group("/articles")
+	// 公开接口可选登录，用于判断会员可见的文章
+	publicArticles := publicGroup.Group("/articles", middleware.OptionalAuthMiddleware())
 	{
 		publicArticles.GET("/:id", r.handler.Get)
+		publicArticles.GET("/:id/meta", r.handler.Meta)
 		publicArticles.GET("", r.handler.List)
+		publicArticles.POST("/:id/unlock", r.handler.Unlock)
 	}
 }
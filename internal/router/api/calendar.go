@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+)
+
+type CalendarRouter struct {
+	handler *handler.CalendarHandler
+}
+
+func NewCalendarRouter(handler *handler.CalendarHandler) *CalendarRouter {
+	return &CalendarRouter{
+		handler: handler,
+	}
+}
+
+func (r *CalendarRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	authCalendar := privateGroup.Group("/calendar")
+	{
+		authCalendar.GET("", r.handler.Get)
+		authCalendar.POST("/feed-token", r.handler.CreateFeedToken)
+		authCalendar.DELETE("/feed-token", r.handler.DeleteFeedToken)
+	}
+
+	// 日历应用无法携带 Authorization 头，订阅源凭个人 token 访问
+	publicGroup.GET("/calendar.ics", r.handler.Feed)
+}
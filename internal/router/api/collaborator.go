@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+)
+
+type CollaboratorRouter struct {
+	handler *handler.CollaboratorHandler
+}
+
+func NewCollaboratorRouter(handler *handler.CollaboratorHandler) *CollaboratorRouter {
+	return &CollaboratorRouter{
+		handler: handler,
+	}
+}
+
+func (r *CollaboratorRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	collaborators := privateGroup.Group("/articles/:id/collaborators")
+	{
+		collaborators.GET("", r.handler.List)
+		collaborators.PUT("", r.handler.Set)
+		collaborators.DELETE("/:user_id", r.handler.Remove)
+	}
+}
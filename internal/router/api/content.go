@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+)
+
+type ContentRouter struct {
+	handler *handler.ContentHandler
+}
+
+func NewContentRouter(handler *handler.ContentHandler) *ContentRouter {
+	return &ContentRouter{
+		handler: handler,
+	}
+}
+
+// Register 内容类型在运行时创建，路由按类型名参数匹配，权限由每个类型的 casbin 策略控制
+func (r *ContentRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	authContent := privateGroup.Group("/content/:type")
+	{
+		authContent.POST("", r.handler.Create)
+		authContent.GET("", r.handler.List)
+		authContent.GET("/:id", r.handler.Get)
+		authContent.PUT("/:id", r.handler.Update)
+		authContent.DELETE("/:id", r.handler.Delete)
+	}
+}
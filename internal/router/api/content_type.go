@@ -0,0 +1,27 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+)
+
+type ContentTypeRouter struct {
+	handler *handler.ContentTypeHandler
+}
+
+func NewContentTypeRouter(handler *handler.ContentTypeHandler) *ContentTypeRouter {
+	return &ContentTypeRouter{
+		handler: handler,
+	}
+}
+
+func (r *ContentTypeRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	authTypes := privateGroup.Group("/content-types")
+	{
+		authTypes.POST("", r.handler.Create)
+		authTypes.GET("", r.handler.List)
+		authTypes.GET("/:id", r.handler.Get)
+		authTypes.PUT("/:id", r.handler.Update)
+		authTypes.DELETE("/:id", r.handler.Delete)
+	}
+}
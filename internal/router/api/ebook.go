@@ -0,0 +1,20 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+)
+
+type EbookRouter struct {
+	handler *handler.EbookHandler
+}
+
+func NewEbookRouter(handler *handler.EbookHandler) *EbookRouter {
+	return &EbookRouter{
+		handler: handler,
+	}
+}
+
+func (r *EbookRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	privateGroup.POST("/ebooks", r.handler.Export)
+}
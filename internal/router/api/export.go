@@ -0,0 +1,20 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+)
+
+type ExportRouter struct {
+	handler *handler.ExportHandler
+}
+
+func NewExportRouter(handler *handler.ExportHandler) *ExportRouter {
+	return &ExportRouter{
+		handler: handler,
+	}
+}
+
+func (r *ExportRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	privateGroup.POST("/export", r.handler.Export)
+}
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+)
+
+type ImportRouter struct {
+	handler *handler.ImportHandler
+}
+
+func NewImportRouter(handler *handler.ImportHandler) *ImportRouter {
+	return &ImportRouter{
+		handler: handler,
+	}
+}
+
+func (r *ImportRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	privateGroup.POST("/imports/wordpress", r.handler.WordPress)
+}
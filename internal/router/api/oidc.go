@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+)
+
+type OIDCRouter struct {
+	handler *handler.OIDCHandler
+}
+
+func NewOIDCRouter(handler *handler.OIDCHandler) *OIDCRouter {
+	return &OIDCRouter{
+		handler: handler,
+	}
+}
+
+func (r *OIDCRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	oidc := publicGroup.Group("/users/oidc")
+	{
+		oidc.GET("/login", r.handler.Login)
+		oidc.GET("/callback", r.handler.Callback)
+	}
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+	"github.com/wuwen/hello-go/internal/middleware"
+)
+
+type PersonalTokenRouter struct {
+	handler *handler.PersonalTokenHandler
+}
+
+func NewPersonalTokenRouter(handler *handler.PersonalTokenHandler) *PersonalTokenRouter {
+	return &PersonalTokenRouter{
+		handler: handler,
+	}
+}
+
+func (r *PersonalTokenRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	authTokens := privateGroup.Group("/users/tokens", middleware.SessionMiddleware())
+	{
+		authTokens.GET("", r.handler.List)
+		authTokens.POST("", r.handler.Create)
+		authTokens.DELETE("/:id", r.handler.Revoke)
+	}
+}
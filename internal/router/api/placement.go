@@ -0,0 +1,31 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+	"github.com/wuwen/hello-go/internal/middleware"
+)
+
+type PlacementRouter struct {
+	handler *handler.PlacementHandler
+}
+
+func NewPlacementRouter(handler *handler.PlacementHandler) *PlacementRouter {
+	return &PlacementRouter{
+		handler: handler,
+	}
+}
+
+func (r *PlacementRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	authPlacements := privateGroup.Group("/placements")
+	{
+		authPlacements.GET("", r.handler.List)
+		authPlacements.PUT("/:name", r.handler.Set)
+		authPlacements.DELETE("/:name", r.handler.Delete)
+	}
+	// 公开接口可选登录，用于判断会员可见的文章
+	publicPlacements := publicGroup.Group("/placements", middleware.OptionalAuthMiddleware())
+	{
+		publicPlacements.GET("/:name", r.handler.Get)
+	}
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+)
+
+type PreviewRouter struct {
+	handler *handler.PreviewHandler
+}
+
+func NewPreviewRouter(handler *handler.PreviewHandler) *PreviewRouter {
+	return &PreviewRouter{
+		handler: handler,
+	}
+}
+
+func (r *PreviewRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	authLinks := privateGroup.Group("/articles/:id/preview-links")
+	{
+		authLinks.POST("", r.handler.Create)
+		authLinks.GET("", r.handler.List)
+		authLinks.DELETE("/:link_id", r.handler.Revoke)
+		authLinks.GET("/:link_id/accesses", r.handler.ListAccesses)
+	}
+
+	// 预览链接对外公开，凭签名 token 访问
+	publicGroup.GET("/preview/:token", r.handler.View)
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+)
+
+type ReviewRouter struct {
+	handler *handler.ReviewHandler
+}
+
+func NewReviewRouter(handler *handler.ReviewHandler) *ReviewRouter {
+	return &ReviewRouter{
+		handler: handler,
+	}
+}
+
+func (r *ReviewRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	authReviews := privateGroup.Group("/articles/:id/reviews")
+	{
+		authReviews.GET("", r.handler.List)
+		authReviews.POST("/decision", r.handler.Decide)
+	}
+	privateGroup.GET("/reviews/mine", r.handler.Mine)
+}
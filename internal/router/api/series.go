@@ -0,0 +1,32 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+	"github.com/wuwen/hello-go/internal/middleware"
+)
+
+type SeriesRouter struct {
+	handler *handler.SeriesHandler
+}
+
+func NewSeriesRouter(handler *handler.SeriesHandler) *SeriesRouter {
+	return &SeriesRouter{
+		handler: handler,
+	}
+}
+
+func (r *SeriesRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	authSeries := privateGroup.Group("/series")
+	{
+		authSeries.POST("", r.handler.Create)
+		authSeries.PUT("/:id", r.handler.Update)
+		authSeries.PUT("/:id/articles", r.handler.Reorder)
+		authSeries.DELETE("/:id", r.handler.Delete)
+	}
+	publicSeries := publicGroup.Group("/series", middleware.OptionalAuthMiddleware())
+	{
+		publicSeries.GET("/:id", r.handler.Get)
+		publicSeries.GET("", r.handler.List)
+	}
+}
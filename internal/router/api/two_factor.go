@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+	"github.com/wuwen/hello-go/internal/middleware"
+)
+
+type TwoFactorRouter struct {
+	handler *handler.TwoFactorHandler
+}
+
+func NewTwoFactorRouter(handler *handler.TwoFactorHandler) *TwoFactorRouter {
+	return &TwoFactorRouter{
+		handler: handler,
+	}
+}
+
+func (r *TwoFactorRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	authTwoFactor := privateGroup.Group("/users/2fa", middleware.SessionMiddleware())
+	{
+		authTwoFactor.GET("", r.handler.Status)
+		authTwoFactor.POST("/setup", r.handler.Setup)
+		authTwoFactor.POST("/confirm", r.handler.Confirm)
+		authTwoFactor.POST("/disable", r.handler.Disable)
+		authTwoFactor.POST("/backup-codes", r.handler.RegenerateBackupCodes)
+	}
+}
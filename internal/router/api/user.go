@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/wuwen/hello-go/internal/handler"
+	"github.com/wuwen/hello-go/internal/middleware"
 )
 
 type UserRouter struct {
@@ -21,12 +22,22 @@ func (r *UserRouter) Register(public, auth *gin.RouterGroup) {
 	{
 		users.POST("/register", r.userHandler.Register)
 		users.POST("/login", r.userHandler.Login)
+		users.POST("/login/2fa", r.userHandler.LoginTwoFactor)
+		users.POST("/login/2fa/setup", r.userHandler.SetupTwoFactorLogin)
+		users.POST("/token/refresh", r.userHandler.RefreshToken)
+		users.GET("/verify", r.userHandler.VerifyEmail)
+		users.POST("/verify/resend", r.userHandler.ResendVerification)
+		users.POST("/password/forgot", r.userHandler.ForgotPassword)
+		users.POST("/password/reset", r.userHandler.ResetPassword)
 	}
 
 	// 需要认证的路由组
 	authUsers := auth.Group("/users")
 	{
-		authUsers.PUT("/:id", r.userHandler.Update)
+		authUsers.POST("/logout", r.userHandler.Logout)
+		authUsers.PUT("/:id", middleware.SessionMiddleware(), r.userHandler.Update)
+		authUsers.DELETE("/:id/sessions", middleware.SessionMiddleware(), r.userHandler.RevokeSessions)
+		authUsers.DELETE("/:id/lockout", r.userHandler.UnlockLogin)
 		authUsers.PUT("/:id/role", r.userHandler.UpdateUserRole)
 	}
 }
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+)
+
+type WebhookRouter struct {
+	handler *handler.WebhookHandler
+}
+
+func NewWebhookRouter(handler *handler.WebhookHandler) *WebhookRouter {
+	return &WebhookRouter{
+		handler: handler,
+	}
+}
+
+func (r *WebhookRouter) Register(publicGroup *gin.RouterGroup, privateGroup *gin.RouterGroup) {
+	authWebhooks := privateGroup.Group("/webhooks")
+	{
+		authWebhooks.POST("", r.handler.Create)
+		authWebhooks.GET("", r.handler.List)
+		authWebhooks.GET("/:id", r.handler.Get)
+		authWebhooks.PUT("/:id", r.handler.Update)
+		authWebhooks.DELETE("/:id", r.handler.Delete)
+		authWebhooks.GET("/:id/deliveries", r.handler.ListDeliveries)
+		authWebhooks.POST("/:id/deliveries/:delivery_id/replay", r.handler.Replay)
+	}
+}
@@ -0,0 +1,25 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wuwen/hello-go/internal/handler"
+)
+
+// SiteRouter 公开站点的页面路由，挂在根路径下
+type SiteRouter struct {
+	handler *handler.SiteHandler
+}
+
+func NewSiteRouter(handler *handler.SiteHandler) *SiteRouter {
+	return &SiteRouter{
+		handler: handler,
+	}
+}
+
+func (r *SiteRouter) Register(root *gin.RouterGroup) {
+	root.GET("/", r.handler.Home)
+	root.GET("/articles/:id", r.handler.Article)
+	root.GET("/authors/:username", r.handler.Author)
+	root.GET("/search", r.handler.Search)
+	root.GET("/static/*filepath", r.handler.Static)
+}
@@ -2,37 +2,107 @@ package service
 
 import (
 	"errors"
+	"strconv"
+	"time"
 
 	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+	"github.com/wuwen/hello-go/internal/pkg/lint"
 	"github.com/wuwen/hello-go/internal/repository"
 )
 
 var (
-	ErrTitleRequired   = errors.New("title is required")
-	ErrContentRequired = errors.New("content is required")
-	ErrArticleNotFound = errors.New("article not found")
+	ErrTitleRequired           = errors.New("title is required")
+	ErrContentRequired         = errors.New("content is required")
+	ErrArticleNotFound         = errors.New("article not found")
+	ErrInvalidVisibility       = errors.New("visibility must be one of public, members, password, private")
+	ErrArticlePasswordRequired = errors.New("password is required for password-protected articles")
+	ErrArticleMembersOnly      = errors.New("article is only available to logged-in members")
+	ErrArticleLocked           = errors.New("article is password protected")
+	ErrInvalidArticlePassword  = errors.New("invalid article password")
+	ErrSubmitForReview         = errors.New("use submit to send an article to review")
+	ErrArticleAlreadyPublished = errors.New("article is already published")
+	ErrArticleInReview         = errors.New("article is in review, move it back to draft before editing")
+)
+
+const (
+	articleUnlockAudience = "article-unlock"
+	articleUnlockExpire   = 30 * time.Minute
 )
 
 type ArticleService struct {
-	repo *repository.ArticleRepository
+	repo          *repository.ArticleRepository
+	series        *SeriesService
+	collaborators *CollaboratorService
+	reviews       *ReviewService
+	seo           *SEOService
+	linter        *lint.Engine
+	webhooks      *WebhookService
 }
 
-func NewArticleService(repo *repository.ArticleRepository) *ArticleService {
-	return &ArticleService{repo: repo}
+func NewArticleService(repo *repository.ArticleRepository, series *SeriesService,
+	collaborators *CollaboratorService, reviews *ReviewService, seo *SEOService, linter *lint.Engine,
+	webhooks *WebhookService) *ArticleService {
+	return &ArticleService{
+		repo:          repo,
+		series:        series,
+		collaborators: collaborators,
+		reviews:       reviews,
+		seo:           seo,
+		linter:        linter,
+		webhooks:      webhooks,
+	}
 }
 
 type CreateArticleRequest struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Title      string            `json:"title"`
+	Excerpt    string            `json:"excerpt"`
+	Content    string            `json:"content"`
+	SEO        *model.ArticleSEO `json:"seo"`
+	Visibility string            `json:"visibility" example:"public"` // public、members、password、private，默认 public
+	Password   string            `json:"password"`                    // visibility 为 password 时必填
 }
 
 type UpdateArticleRequest struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
-	Status  int    `json:"status"`
+	Title      string            `json:"title"`
+	Excerpt    *string           `json:"excerpt"`
+	Content    string            `json:"content"`
+	SEO        *model.ArticleSEO `json:"seo"` // 传入时整体替换
+	Status     int               `json:"status"`
+	Visibility string            `json:"visibility" example:"members"`
+	Password   string            `json:"password"`
+}
+
+// changesContent 请求是否修改了状态以外的字段
+func (r *UpdateArticleRequest) changesContent() bool {
+	return r.Title != "" || r.Excerpt != nil || r.Content != "" || r.SEO != nil ||
+		r.Visibility != "" || r.Password != ""
+}
+
+// ArticleDetail 文章详情，附带补全默认值后的元数据，文章属于某个系列时附带系列导航
+type ArticleDetail struct {
+	*model.Article
+	Meta   *SEOMeta          `json:"meta"`
+	Series *SeriesNavigation `json:"series,omitempty"`
+}
+
+// Viewer 公开接口的访问者，匿名访问时 UserID 为 0
+type Viewer struct {
+	UserID      uint
+	UnlockToken string
+}
+
+type UnlockArticleRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type UnlockArticleResponse struct {
+	Token     string `json:"token"`
+	ExpiresIn int    `json:"expires_in" example:"1800"` // 秒
 }
 
-func (s *ArticleService) Create(req *CreateArticleRequest) (*model.Article, error) {
+// Create 创建文章，创建者成为文章的所有者
+func (s *ArticleService) Create(userID uint, req *CreateArticleRequest) (*model.Article, error) {
 	if req.Title == "" {
 		return nil, ErrTitleRequired
 	}
@@ -41,48 +111,163 @@ func (s *ArticleService) Create(req *CreateArticleRequest) (*model.Article, erro
 	}
 
 	article := &model.Article{
-		Title:   req.Title,
-		Content: req.Content,
-		Status:  1, // 默认为草稿状态
+		Title:      req.Title,
+		Excerpt:    req.Excerpt,
+		Content:    req.Content,
+		Status:     model.ArticleStatusDraft, // 默认为草稿状态
+		Visibility: model.ArticleVisibilityPublic,
+	}
+	if req.SEO != nil {
+		article.SEO = *req.SEO
+	}
+	if err := applyVisibility(article, req.Visibility, req.Password); err != nil {
+		return nil, err
+	}
+	if err := s.seo.Validate(article); err != nil {
+		return nil, err
 	}
 
 	if err := s.repo.Create(article); err != nil {
 		return nil, err
 	}
+	if userID != 0 {
+		if err := s.collaborators.GrantOwner(article.ID, userID); err != nil {
+			return nil, err
+		}
+	}
 
+	s.webhooks.Publish(EventArticleCreated, article)
 	return article, nil
 }
 
-func (s *ArticleService) Get(id uint) (*model.Article, error) {
+// Get 按访问者身份返回文章详情
+func (s *ArticleService) Get(id uint, viewer *Viewer) (*ArticleDetail, error) {
 	article, err := s.repo.GetByID(id)
 	if err != nil {
 		return nil, ErrArticleNotFound
 	}
-	return article, nil
+
+	// 未发布和私有的文章只有协作者可以查看
+	if article.Status != model.ArticleStatusPublished || article.Visibility == model.ArticleVisibilityPrivate {
+		if !s.collaborators.HasGrant(viewer.UserID, article.ID) {
+			return nil, ErrArticleNotFound
+		}
+	} else if err := checkVisibility(article, viewer); err != nil {
+		return nil, err
+	}
+
+	nav, err := s.series.Navigation(article.ID, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArticleDetail{
+		Article: article,
+		Meta:    s.seo.Resolve(article),
+		Series:  nav,
+	}, nil
 }
 
-func (s *ArticleService) List(page, pageSize int) ([]*model.Article, int64, error) {
+// MetaTags 返回文章的 meta 标签，访问权限与查看文章一致
+func (s *ArticleService) MetaTags(id uint, viewer *Viewer) (*SEOMetaTags, error) {
+	detail, err := s.Get(id, viewer)
+	if err != nil {
+		return nil, err
+	}
+	return s.seo.Tags(detail.Article), nil
+}
+
+// List 返回访问者可见的已发布文章及其参与协作的文章，密码保护的文章只展示标题等信息，不包含正文
+func (s *ArticleService) List(page, pageSize int, viewer *Viewer) ([]*model.Article, int64, error) {
 	if page < 1 {
 		page = 1
 	}
 	if pageSize < 1 {
 		pageSize = 10
 	}
-	return s.repo.List(page, pageSize)
+
+	articles, total, err := s.repo.List(page, pageSize, listedVisibilities(viewer), viewer.UserID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, article := range articles {
+		redactListed(article)
+	}
+	return articles, total, nil
+}
+
+// Unlock 校验查看密码，成功后签发短期有效的解锁 token
+func (s *ArticleService) Unlock(id uint, req *UnlockArticleRequest) (*UnlockArticleResponse, error) {
+	article, err := s.repo.GetByID(id)
+	if err != nil || article.Visibility != model.ArticleVisibilityPassword {
+		return nil, ErrArticleNotFound
+	}
+
+	if !article.ValidatePassword(req.Password) {
+		return nil, ErrInvalidArticlePassword
+	}
+
+	token, err := auth.GenerateScopedToken(articleUnlockAudience, strconv.FormatUint(uint64(article.ID), 10), articleUnlockExpire)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UnlockArticleResponse{
+		Token:     token,
+		ExpiresIn: int(articleUnlockExpire.Seconds()),
+	}, nil
 }
 
-func (s *ArticleService) Update(id uint, req *UpdateArticleRequest) (*model.Article, error) {
+// Update 更新文章，设置了协作者的文章需要 editor 及以上权限
+func (s *ArticleService) Update(userID, id uint, req *UpdateArticleRequest) (*model.Article, error) {
 	article, err := s.repo.GetByID(id)
 	if err != nil {
 		return nil, ErrArticleNotFound
 	}
+	if err := s.collaborators.Authorize(userID, id, model.GrantRoleEditor); err != nil {
+		return nil, err
+	}
+	// 审核中的内容不能修改，否则已有的通过结论会对应到审核人没看过的内容
+	if article.Status == model.ArticleStatusInReview && req.changesContent() {
+		return nil, ErrArticleInReview
+	}
 
 	if req.Title != "" {
 		article.Title = req.Title
 	}
+	if req.Excerpt != nil {
+		article.Excerpt = *req.Excerpt
+	}
 	if req.Content != "" {
 		article.Content = req.Content
 	}
+	if req.SEO != nil {
+		article.SEO = *req.SEO
+	}
+	if req.Visibility != "" || req.Password != "" {
+		if err := applyVisibility(article, req.Visibility, req.Password); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.seo.Validate(article); err != nil {
+		return nil, err
+	}
+	if req.Status == model.ArticleStatusInReview && article.Status != model.ArticleStatusInReview {
+		return nil, ErrSubmitForReview
+	}
+	published := req.Status == model.ArticleStatusPublished && article.Status != model.ArticleStatusPublished
+	if published {
+		// 发布前需要通过审核，且没有 error 级别的检查问题
+		if err := s.reviews.CheckApproved(article); err != nil {
+			return nil, err
+		}
+		if err := s.checkLint(article); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		article.PublishedAt = &now
+	}
 	if req.Status != 0 {
 		article.Status = req.Status
 	}
@@ -91,12 +276,154 @@ func (s *ArticleService) Update(id uint, req *UpdateArticleRequest) (*model.Arti
 		return nil, err
 	}
 
+	s.webhooks.Publish(EventArticleUpdated, article)
+	if published {
+		s.webhooks.Publish(EventArticlePublished, article)
+	}
 	return article, nil
 }
 
-func (s *ArticleService) Delete(id uint) error {
-	if _, err := s.repo.GetByID(id); err != nil {
+// Submit 提交文章审核并指派审核人，需要 editor 及以上权限。
+// 审核中的文章可以重新提交，上一轮的审核结论会被清空
+func (s *ArticleService) Submit(userID, id uint, req *SubmitArticleRequest) (*model.Article, error) {
+	article, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrArticleNotFound
+	}
+	if err := s.collaborators.Authorize(userID, id, model.GrantRoleEditor); err != nil {
+		return nil, err
+	}
+	if article.Status == model.ArticleStatusPublished {
+		return nil, ErrArticleAlreadyPublished
+	}
+	if err := s.checkLint(article); err != nil {
+		return nil, err
+	}
+
+	if err := s.reviews.Assign(id, userID, req); err != nil {
+		return nil, err
+	}
+	article.Status = model.ArticleStatusInReview
+	if err := s.repo.Update(article); err != nil {
+		return nil, err
+	}
+
+	s.webhooks.Publish(EventArticleSubmitted, article)
+	return article, nil
+}
+
+// Lint 对文章运行发布前检查，需要 viewer 及以上权限
+func (s *ArticleService) Lint(userID, id uint) ([]lint.Finding, error) {
+	article, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrArticleNotFound
+	}
+	if err := s.collaborators.Authorize(userID, id, model.GrantRoleViewer); err != nil {
+		return nil, err
+	}
+	return s.runLint(article)
+}
+
+// checkLint 运行检查，存在 error 级别的问题时返回 *ArticleLintError
+func (s *ArticleService) checkLint(article *model.Article) error {
+	findings, err := s.runLint(article)
+	if err != nil {
+		return err
+	}
+	if lint.HasErrors(findings) {
+		return &ArticleLintError{Findings: findings}
+	}
+	return nil
+}
+
+func (s *ArticleService) runLint(article *model.Article) ([]lint.Finding, error) {
+	return s.linter.Run(&lint.Document{
+		ID:      article.ID,
+		Title:   article.Title,
+		Excerpt: article.Excerpt,
+		Content: article.Content,
+	})
+}
+
+// Delete 删除文章，设置了协作者的文章只有所有者可以删除
+func (s *ArticleService) Delete(userID, id uint) error {
+	article, err := s.repo.GetByID(id)
+	if err != nil {
+		return ErrArticleNotFound
+	}
+	if err := s.collaborators.Authorize(userID, id, model.GrantRoleOwner); err != nil {
+		return err
+	}
+	if err := s.series.RemoveArticle(id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	if err := s.collaborators.RemoveArticle(id); err != nil {
+		return err
+	}
+	if err := s.reviews.RemoveArticle(id); err != nil {
+		return err
+	}
+
+	s.webhooks.Publish(EventArticleDeleted, article)
+	return nil
+}
+
+// applyVisibility 设置可见性和查看密码，visibility 为空时保持原值
+func applyVisibility(article *model.Article, visibility, password string) error {
+	if visibility != "" {
+		if !model.ValidVisibility(visibility) {
+			return ErrInvalidVisibility
+		}
+		article.Visibility = visibility
+	}
+
+	if article.Visibility != model.ArticleVisibilityPassword {
+		article.PasswordHash = ""
+		return nil
+	}
+
+	if password != "" {
+		return article.SetPassword(password)
+	}
+	if article.PasswordHash == "" {
+		return ErrArticlePasswordRequired
+	}
+	return nil
+}
+
+// listedVisibilities 返回访问者在列表中可以看到的可见性
+func listedVisibilities(viewer *Viewer) []string {
+	visibilities := []string{model.ArticleVisibilityPublic, model.ArticleVisibilityPassword}
+	if viewer.UserID != 0 {
+		visibilities = append(visibilities, model.ArticleVisibilityMembers)
+	}
+	return visibilities
+}
+
+// redactListed 列表中的密码保护文章不包含正文
+func redactListed(article *model.Article) {
+	if article.Visibility == model.ArticleVisibilityPassword {
+		article.Content = ""
+	}
+}
+
+// checkVisibility 判断访问者能否在公开接口中查看文章
+func checkVisibility(article *model.Article, viewer *Viewer) error {
+	switch article.Visibility {
+	case model.ArticleVisibilityMembers:
+		if viewer.UserID == 0 {
+			return ErrArticleMembersOnly
+		}
+	case model.ArticleVisibilityPassword:
+		subject, err := auth.ParseScopedToken(articleUnlockAudience, viewer.UnlockToken)
+		if err != nil || subject != strconv.FormatUint(uint64(article.ID), 10) {
+			return ErrArticleLocked
+		}
+	case model.ArticleVisibilityPrivate:
 		return ErrArticleNotFound
 	}
-	return s.repo.Delete(id)
+	return nil
 }
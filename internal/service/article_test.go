@@ -0,0 +1,48 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+func TestUnpublishedArticlesVisibleToCollaboratorsOnly(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	svc := newTestArticleService(t, db, policy)
+
+	author := createTestUser(t, db, policy, "author", "user")
+	stranger := createTestUser(t, db, policy, "stranger", "user")
+	draft, err := svc.Create(author.ID, &CreateArticleRequest{Title: "draft", Content: "body"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	published := &model.Article{Title: "published", Content: "body", Status: model.ArticleStatusPublished,
+		Visibility: model.ArticleVisibilityPublic, PublishedAt: &now}
+	if err := repository.NewArticleRepository(db).Create(published); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, viewer := range map[string]*Viewer{"anonymous": {}, "stranger": {UserID: stranger.ID}} {
+		if _, err := svc.Get(draft.ID, viewer); err != ErrArticleNotFound {
+			t.Errorf("%s reading draft = %v, want ErrArticleNotFound", name, err)
+		}
+		articles, total, err := svc.List(1, 10, viewer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 1 || len(articles) != 1 || articles[0].ID != published.ID {
+			t.Errorf("%s list = %d articles (total %d), want only the published one", name, len(articles), total)
+		}
+	}
+
+	if _, err := svc.Get(draft.ID, &Viewer{UserID: author.ID}); err != nil {
+		t.Fatalf("author reading own draft: %v", err)
+	}
+	if _, total, err := svc.List(1, 10, &Viewer{UserID: author.ID}); err != nil || total != 2 {
+		t.Fatalf("author list total = %d, %v, want published article and own draft", total, err)
+	}
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/ical"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+// 日历条目类型。文章目前没有定时发布功能（导入的定时文章会转为草稿），
+// 因此没有计划发布时间的条目，添加定时发布后应在此增加对应类型
+const (
+	CalendarEntryPublished = "published"  // 发布时间
+	CalendarEntryInReview  = "in_review"  // 提交审核时间，文章仍在审核中
+	CalendarEntryReviewDue = "review_due" // 审核截止时间
+)
+
+const (
+	calendarDateLayout = "2006-01-02"
+	maxCalendarDays    = 366
+	// 订阅源包含过去 30 天到未来 90 天的条目
+	calendarFeedPast    = 30 * 24 * time.Hour
+	calendarFeedFuture  = 90 * 24 * time.Hour
+	calendarTokenPrefix = "cal_"
+)
+
+var (
+	ErrInvalidCalendarRange = errors.New("from and to must be dates (YYYY-MM-DD), from <= to, at most 366 days apart")
+	ErrInvalidFeedToken     = errors.New("invalid calendar feed token")
+)
+
+type CalendarEntry struct {
+	Kind       string    `json:"kind" example:"published"` // published、in_review、review_due
+	ArticleID  uint      `json:"article_id" example:"1"`
+	Title      string    `json:"title" example:"文章标题"`
+	Status     int       `json:"status" example:"2"`
+	At         time.Time `json:"at" example:"2024-07-20T10:00:00Z"`
+	ReviewerID uint      `json:"reviewer_id,omitempty" example:"2"` // 仅 review_due
+}
+
+type CalendarDay struct {
+	Date    string           `json:"date" example:"2024-07-20"`
+	Entries []*CalendarEntry `json:"entries"`
+}
+
+// Calendar 按天（UTC）汇总的编辑日历，只包含有条目的日期
+type Calendar struct {
+	From string         `json:"from" example:"2024-07-01"`
+	To   string         `json:"to" example:"2024-07-31"`
+	Days []*CalendarDay `json:"days"`
+}
+
+type CalendarFeedToken struct {
+	Token string `json:"token"`
+	URL   string `json:"url" example:"http://localhost:8080/api/v1/calendar.ics?token=cal_xxx"`
+}
+
+// CalendarService 汇总文章的发布时间等日程，并提供 iCalendar 订阅源
+type CalendarService struct {
+	repo          *repository.CalendarRepository
+	articleRepo   *repository.ArticleRepository
+	reviewRepo    *repository.ReviewRepository
+	collaborators *CollaboratorService
+	siteName      string
+	baseURL       string
+}
+
+func NewCalendarService(repo *repository.CalendarRepository, articleRepo *repository.ArticleRepository,
+	reviewRepo *repository.ReviewRepository, collaborators *CollaboratorService, siteName, baseURL string) *CalendarService {
+	return &CalendarService{
+		repo:          repo,
+		articleRepo:   articleRepo,
+		reviewRepo:    reviewRepo,
+		collaborators: collaborators,
+		siteName:      siteName,
+		baseURL:       strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Range 返回 [from, to] 日期范围内的日历，默认为当月
+func (s *CalendarService) Range(userID uint, from, to string) (*Calendar, error) {
+	start, end, err := parseCalendarRange(from, to, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.entries(userID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &Calendar{
+		From: start.Format(calendarDateLayout),
+		To:   end.Format(calendarDateLayout),
+		Days: make([]*CalendarDay, 0),
+	}
+	for _, entry := range entries {
+		date := entry.At.UTC().Format(calendarDateLayout)
+		if n := len(calendar.Days); n == 0 || calendar.Days[n-1].Date != date {
+			calendar.Days = append(calendar.Days, &CalendarDay{Date: date})
+		}
+		day := calendar.Days[len(calendar.Days)-1]
+		day.Entries = append(day.Entries, entry)
+	}
+	return calendar, nil
+}
+
+// Feed 校验订阅 token 并以 iCalendar 格式返回该用户的日历
+func (s *CalendarService) Feed(token string) ([]byte, error) {
+	if !strings.HasPrefix(token, calendarTokenPrefix) {
+		return nil, ErrInvalidFeedToken
+	}
+	feed, err := s.repo.FindFeedByToken(hashToken(token))
+	if err != nil {
+		return nil, ErrInvalidFeedToken
+	}
+
+	now := time.Now()
+	if err := s.repo.TouchFeed(feed.ID, now); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.entries(feed.UserID, now.Add(-calendarFeedPast), now.Add(calendarFeedFuture))
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ical.Calendar{Name: strings.TrimSpace(s.siteName + " editorial calendar")}
+	for _, entry := range entries {
+		cal.Events = append(cal.Events, &ical.Event{
+			UID:        fmt.Sprintf("%s-%d-%d@%s", entry.Kind, entry.ArticleID, entry.ReviewerID, s.host()),
+			Start:      entry.At,
+			Summary:    fmt.Sprintf("[%s] %s", entry.Kind, entry.Title),
+			URL:        fmt.Sprintf("%s/articles/%d", s.baseURL, entry.ArticleID),
+			Categories: []string{entry.Kind},
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Write(&buf, cal); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CreateFeedToken 生成新的订阅 token，原有 token 随即失效。token 只在此时返回一次
+func (s *CalendarService) CreateFeedToken(userID uint) (*CalendarFeedToken, error) {
+	token := newOpaqueToken(calendarTokenPrefix)
+	if _, err := s.repo.SaveFeed(userID, hashToken(token)); err != nil {
+		return nil, err
+	}
+	return &CalendarFeedToken{
+		Token: token,
+		URL:   s.baseURL + "/api/v1/calendar.ics?token=" + token,
+	}, nil
+}
+
+// DeleteFeedToken 撤销用户的订阅 token
+func (s *CalendarService) DeleteFeedToken(userID uint) error {
+	return s.repo.DeleteFeed(userID)
+}
+
+// entries 汇总 [from, to) 内用户可见的发布时间、提交审核时间和审核截止时间，按时间排序。
+// 私有和未发布的文章只对协作者和管理员可见
+func (s *CalendarService) entries(userID uint, from, to time.Time) ([]*CalendarEntry, error) {
+	articles, err := s.articleRepo.ListPublishedBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*CalendarEntry, 0, len(articles))
+	for _, article := range articles {
+		if !s.visible(userID, article) {
+			continue
+		}
+		entries = append(entries, &CalendarEntry{
+			Kind:      CalendarEntryPublished,
+			ArticleID: article.ID,
+			Title:     article.Title,
+			Status:    article.Status,
+			At:        *article.PublishedAt,
+		})
+	}
+
+	// 同一次提交的审核记录一起创建，每篇文章只取第一条作为提交时间
+	submitted, err := s.reviewRepo.ListSubmittedBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := s.reviewRepo.ListPendingDueBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	byID, err := s.reviewedArticles(append(submitted, reviews...))
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[uint]bool, len(submitted))
+	for _, review := range submitted {
+		article, ok := byID[review.ArticleID]
+		if !ok || seen[article.ID] || !s.visible(userID, article) {
+			continue
+		}
+		seen[article.ID] = true
+		entries = append(entries, &CalendarEntry{
+			Kind:      CalendarEntryInReview,
+			ArticleID: article.ID,
+			Title:     article.Title,
+			Status:    article.Status,
+			At:        review.CreatedAt,
+		})
+	}
+	for _, review := range reviews {
+		article, ok := byID[review.ArticleID]
+		if !ok || !s.visible(userID, article) {
+			continue
+		}
+		entries = append(entries, &CalendarEntry{
+			Kind:       CalendarEntryReviewDue,
+			ArticleID:  article.ID,
+			Title:      article.Title,
+			Status:     article.Status,
+			At:         *review.DueAt,
+			ReviewerID: review.ReviewerID,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].At.Before(entries[j].At)
+	})
+	return entries, nil
+}
+
+// reviewedArticles 按 ID 返回审核记录对应的文章
+func (s *CalendarService) reviewedArticles(reviews []*model.ArticleReview) (map[uint]*model.Article, error) {
+	ids := make([]uint, 0, len(reviews))
+	for _, review := range reviews {
+		ids = append(ids, review.ArticleID)
+	}
+	articles, err := s.articleRepo.ListByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+	return byID, nil
+}
+
+func (s *CalendarService) visible(userID uint, article *model.Article) bool {
+	if article.Status == model.ArticleStatusPublished && article.Visibility != model.ArticleVisibilityPrivate {
+		return true
+	}
+	return s.collaborators.HasGrant(userID, article.ID)
+}
+
+// host 用于生成全局唯一的事件 UID
+func (s *CalendarService) host() string {
+	host := s.baseURL
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if host == "" {
+		return "localhost"
+	}
+	return host
+}
+
+// parseCalendarRange 解析日期范围，from 和 to 都为空时返回 now 所在的月份
+func parseCalendarRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	if from == "" && to == "" {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1), nil
+	}
+
+	start, err := time.Parse(calendarDateLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidCalendarRange
+	}
+	end, err := time.Parse(calendarDateLayout, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidCalendarRange
+	}
+	if end.Before(start) || end.Sub(start) >= maxCalendarDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrInvalidCalendarRange
+	}
+	return start, end, nil
+}
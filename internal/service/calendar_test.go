@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+func TestCalendarListsArticlesInReviewToCollaborators(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	articles := newTestArticleService(t, db, policy)
+	collaborators := NewCollaboratorService(repository.NewArticleGrantRepository(db),
+		repository.NewArticleRepository(db), repository.NewUserRepository(db), policy)
+	svc := NewCalendarService(repository.NewCalendarRepository(db), repository.NewArticleRepository(db),
+		repository.NewReviewRepository(db), collaborators, "test", "http://localhost")
+
+	author := createTestUser(t, db, policy, "author", "user")
+	reviewer := createTestUser(t, db, policy, "reviewer", "user", "reviewer")
+	stranger := createTestUser(t, db, policy, "stranger", "user")
+	article, err := articles.Create(author.ID, &CreateArticleRequest{Title: "draft", Content: "body"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := time.Now().UTC().Add(48 * time.Hour)
+	if _, err := articles.Submit(author.ID, article.ID, &SubmitArticleRequest{ReviewerIDs: []uint{reviewer.ID}, DueAt: &due}); err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Now().UTC().Format(calendarDateLayout)
+	to := due.Format(calendarDateLayout)
+	kinds := func(userID uint) map[string]int {
+		calendar, err := svc.Range(userID, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := make(map[string]int)
+		for _, day := range calendar.Days {
+			for _, entry := range day.Entries {
+				found[entry.Kind]++
+			}
+		}
+		return found
+	}
+
+	for _, userID := range []uint{author.ID, reviewer.ID} {
+		if got := kinds(userID); got[CalendarEntryInReview] != 1 || got[CalendarEntryReviewDue] != 1 {
+			t.Errorf("user %d calendar = %v, want one in_review and one review_due entry", userID, got)
+		}
+	}
+	if got := kinds(stranger.ID); len(got) != 0 {
+		t.Errorf("stranger calendar = %v, want no entries for someone else's draft", got)
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+var (
+	ErrArticleForbidden      = errors.New("you do not have permission on this article")
+	ErrInvalidGrantRole      = errors.New("role must be one of viewer, editor, owner")
+	ErrCollaboratorNotFound  = errors.New("collaborator not found")
+	ErrCannotRemoveOwner     = errors.New("the owner cannot be removed, transfer ownership first")
+	ErrCannotChangeOwnerRole = errors.New("the owner's role can only change by transferring ownership")
+)
+
+// CollaboratorService 管理文章级别的协作权限，作为角色策略之外的补充
+type CollaboratorService struct {
+	repo          *repository.ArticleGrantRepository
+	articleRepo   *repository.ArticleRepository
+	userRepo      *repository.UserRepository
+	policyService *PolicyService
+}
+
+func NewCollaboratorService(repo *repository.ArticleGrantRepository, articleRepo *repository.ArticleRepository,
+	userRepo *repository.UserRepository, policyService *PolicyService) *CollaboratorService {
+	return &CollaboratorService{
+		repo:          repo,
+		articleRepo:   articleRepo,
+		userRepo:      userRepo,
+		policyService: policyService,
+	}
+}
+
+type SetCollaboratorRequest struct {
+	UserID uint   `json:"user_id" binding:"required" example:"2"`
+	Role   string `json:"role" binding:"required" example:"editor"` // 设置为 owner 即转移所有权
+}
+
+type Collaborator struct {
+	UserID    uint      `json:"user_id" example:"2"`
+	Username  string    `json:"username" example:"testuser"`
+	Role      string    `json:"role" example:"editor"`
+	CreatedAt time.Time `json:"created_at" example:"2024-07-20T10:00:00Z"`
+}
+
+// Authorize 判断用户在文章上是否至少拥有 role 权限。
+// 管理员不受限制；没有任何协作者的文章（如导入时作者未知）只有管理员可以管理
+func (s *CollaboratorService) Authorize(userID, articleID uint, role string) error {
+	grant, err := s.repo.Find(articleID, userID)
+	if err == nil && model.GrantRoleRank(grant.Role) >= model.GrantRoleRank(role) {
+		return nil
+	}
+
+	if s.isAdmin(userID) {
+		return nil
+	}
+	return ErrArticleForbidden
+}
+
+// HasGrant 判断用户是否是文章的协作者
+func (s *CollaboratorService) HasGrant(userID, articleID uint) bool {
+	if userID == 0 {
+		return false
+	}
+	if _, err := s.repo.Find(articleID, userID); err == nil {
+		return true
+	}
+	return s.isAdmin(userID)
+}
+
+// GrantOwner 文章创建者成为所有者
+func (s *CollaboratorService) GrantOwner(articleID, userID uint) error {
+	_, err := s.repo.Save(articleID, userID, model.GrantRoleOwner)
+	return err
+}
+
+// GrantViewer 确保用户至少可以查看文章，已有权限时不做修改
+func (s *CollaboratorService) GrantViewer(articleID, userID uint) error {
+	if _, err := s.repo.Find(articleID, userID); err == nil {
+		return nil
+	}
+	_, err := s.repo.Save(articleID, userID, model.GrantRoleViewer)
+	return err
+}
+
+// RemoveArticle 文章删除时清理协作者
+func (s *CollaboratorService) RemoveArticle(articleID uint) error {
+	return s.repo.DeleteByArticle(articleID)
+}
+
+func (s *CollaboratorService) List(articleID, actorID uint) ([]*Collaborator, error) {
+	if _, err := s.articleRepo.GetByID(articleID); err != nil {
+		return nil, ErrArticleNotFound
+	}
+	if err := s.Authorize(actorID, articleID, model.GrantRoleViewer); err != nil {
+		return nil, err
+	}
+
+	grants, err := s.repo.ListByArticle(articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	collaborators := make([]*Collaborator, 0, len(grants))
+	for _, grant := range grants {
+		collaborator := &Collaborator{
+			UserID:    grant.UserID,
+			Role:      grant.Role,
+			CreatedAt: grant.CreatedAt,
+		}
+		if user, err := s.userRepo.FindById(grant.UserID); err == nil {
+			collaborator.Username = user.Username
+		}
+		collaborators = append(collaborators, collaborator)
+	}
+	return collaborators, nil
+}
+
+// Set 添加协作者或修改其角色，只有所有者可以操作。角色为 owner 时转移所有权
+func (s *CollaboratorService) Set(articleID, actorID uint, req *SetCollaboratorRequest) error {
+	if model.GrantRoleRank(req.Role) == 0 {
+		return ErrInvalidGrantRole
+	}
+	if _, err := s.articleRepo.GetByID(articleID); err != nil {
+		return ErrArticleNotFound
+	}
+	if err := s.Authorize(actorID, articleID, model.GrantRoleOwner); err != nil {
+		return err
+	}
+	if _, err := s.userRepo.FindById(req.UserID); err != nil {
+		return ErrUserNotFound
+	}
+
+	if req.Role == model.GrantRoleOwner {
+		return s.repo.TransferOwnership(articleID, req.UserID)
+	}
+
+	if grant, err := s.repo.Find(articleID, req.UserID); err == nil && grant.Role == model.GrantRoleOwner {
+		return ErrCannotChangeOwnerRole
+	}
+	_, err := s.repo.Save(articleID, req.UserID, req.Role)
+	return err
+}
+
+// Remove 移除协作者，只有所有者可以操作
+func (s *CollaboratorService) Remove(articleID, actorID, userID uint) error {
+	if _, err := s.articleRepo.GetByID(articleID); err != nil {
+		return ErrArticleNotFound
+	}
+	if err := s.Authorize(actorID, articleID, model.GrantRoleOwner); err != nil {
+		return err
+	}
+
+	grant, err := s.repo.Find(articleID, userID)
+	if err != nil {
+		return ErrCollaboratorNotFound
+	}
+	if grant.Role == model.GrantRoleOwner {
+		return ErrCannotRemoveOwner
+	}
+	return s.repo.Delete(articleID, userID)
+}
+
+func (s *CollaboratorService) isAdmin(userID uint) bool {
+	user, err := s.userRepo.FindById(userID)
+	if err != nil {
+		return false
+	}
+	ok, err := s.policyService.HasRoleForUser(user.Username, "admin")
+	return err == nil && ok
+}
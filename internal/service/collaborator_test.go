@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+func TestCollaboratorUngrantedArticleIsAdminManaged(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	articles := repository.NewArticleRepository(db)
+	svc := NewCollaboratorService(repository.NewArticleGrantRepository(db), articles,
+		repository.NewUserRepository(db), policy)
+
+	admin := createTestUser(t, db, policy, "admin", "admin")
+	mallory := createTestUser(t, db, policy, "mallory", "user")
+	article := &model.Article{Title: "imported", Content: "x", Visibility: model.ArticleVisibilityPublic}
+	if err := articles.Create(article); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, role := range []string{model.GrantRoleViewer, model.GrantRoleEditor, model.GrantRoleOwner} {
+		if err := svc.Authorize(mallory.ID, article.ID, role); err != ErrArticleForbidden {
+			t.Errorf("Authorize(%s) on ungranted article = %v, want ErrArticleForbidden", role, err)
+		}
+	}
+	err := svc.Set(article.ID, mallory.ID, &SetCollaboratorRequest{UserID: mallory.ID, Role: model.GrantRoleOwner})
+	if err != ErrArticleForbidden {
+		t.Fatalf("taking ownership of ungranted article = %v, want ErrArticleForbidden", err)
+	}
+
+	if err := svc.Set(article.ID, admin.ID, &SetCollaboratorRequest{UserID: mallory.ID, Role: model.GrantRoleOwner}); err != nil {
+		t.Fatalf("admin assigning owner: %v", err)
+	}
+	if err := svc.Authorize(mallory.ID, article.ID, model.GrantRoleOwner); err != nil {
+		t.Fatalf("assigned owner: %v", err)
+	}
+}
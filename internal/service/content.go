@@ -0,0 +1,215 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+var ErrContentEntryNotFound = errors.New("content entry not found")
+
+const maxStringFieldLength = 255
+
+type ContentService struct {
+	typeRepo    *repository.ContentTypeRepository
+	repo        *repository.ContentEntryRepository
+	articleRepo *repository.ArticleRepository
+}
+
+func NewContentService(typeRepo *repository.ContentTypeRepository, repo *repository.ContentEntryRepository, articleRepo *repository.ArticleRepository) *ContentService {
+	return &ContentService{
+		typeRepo:    typeRepo,
+		repo:        repo,
+		articleRepo: articleRepo,
+	}
+}
+
+type ContentEntryRequest struct {
+	Data model.ContentData `json:"data" binding:"required"`
+}
+
+func (s *ContentService) Create(typeName string, req *ContentEntryRequest) (*model.ContentEntry, error) {
+	contentType, err := s.contentType(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validate(contentType, req.Data); err != nil {
+		return nil, err
+	}
+
+	entry := &model.ContentEntry{
+		ContentTypeID: contentType.ID,
+		Data:          req.Data,
+	}
+	if err := s.repo.Create(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *ContentService) Get(typeName string, id uint) (*model.ContentEntry, error) {
+	contentType, err := s.contentType(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.repo.GetByID(contentType.ID, id)
+	if err != nil {
+		return nil, ErrContentEntryNotFound
+	}
+	return entry, nil
+}
+
+func (s *ContentService) List(typeName string, page, pageSize int) ([]*model.ContentEntry, int64, error) {
+	contentType, err := s.contentType(typeName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	return s.repo.List(contentType.ID, page, pageSize)
+}
+
+// Update 整体替换条目数据，新数据需通过当前结构的校验
+func (s *ContentService) Update(typeName string, id uint, req *ContentEntryRequest) (*model.ContentEntry, error) {
+	contentType, err := s.contentType(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.repo.GetByID(contentType.ID, id)
+	if err != nil {
+		return nil, ErrContentEntryNotFound
+	}
+
+	if err := s.validate(contentType, req.Data); err != nil {
+		return nil, err
+	}
+
+	entry.Data = req.Data
+	if err := s.repo.Update(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *ContentService) Delete(typeName string, id uint) error {
+	contentType, err := s.contentType(typeName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.repo.GetByID(contentType.ID, id); err != nil {
+		return ErrContentEntryNotFound
+	}
+	return s.repo.Delete(contentType.ID, id)
+}
+
+func (s *ContentService) contentType(name string) (*model.ContentType, error) {
+	contentType, err := s.typeRepo.GetByName(name)
+	if err != nil {
+		return nil, ErrContentTypeNotFound
+	}
+	return contentType, nil
+}
+
+// validate 按内容类型定义校验条目数据
+func (s *ContentService) validate(contentType *model.ContentType, data model.ContentData) error {
+	verr := &ValidationError{}
+
+	for name := range data {
+		if _, ok := contentType.Field(name); !ok {
+			verr.Add(name, "unknown", fmt.Sprintf("%s is not a field of %s", name, contentType.Name))
+		}
+	}
+
+	for _, field := range contentType.Fields {
+		value, ok := data[field.Name]
+		if !ok || value == nil {
+			if field.Required {
+				verr.Add(field.Name, "required", fmt.Sprintf("%s is required", field.Name))
+			}
+			continue
+		}
+		if msg := s.checkValue(field, value); msg != "" {
+			verr.Add(field.Name, string(field.Type), fmt.Sprintf("%s %s", field.Name, msg))
+		}
+	}
+
+	return verr.Err()
+}
+
+// checkValue 校验单个字段的值，返回空字符串表示合法
+func (s *ContentService) checkValue(field model.ContentField, value interface{}) string {
+	switch field.Type {
+	case model.FieldTypeString:
+		str, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		if len([]rune(str)) > maxStringFieldLength {
+			return fmt.Sprintf("must be at most %d characters", maxStringFieldLength)
+		}
+	case model.FieldTypeText:
+		if _, ok := value.(string); !ok {
+			return "must be a string"
+		}
+	case model.FieldTypeNumber:
+		if _, ok := value.(float64); !ok {
+			return "must be a number"
+		}
+	case model.FieldTypeBool:
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+	case model.FieldTypeDate:
+		str, ok := value.(string)
+		if !ok {
+			return "must be a date string"
+		}
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			if _, err := time.Parse(time.DateOnly, str); err != nil {
+				return "must be a date in YYYY-MM-DD or RFC 3339 format"
+			}
+		}
+	case model.FieldTypeMedia:
+		// 媒体字段保存资源地址
+		str, ok := value.(string)
+		if !ok || str == "" {
+			return "must be a non-empty media URL"
+		}
+	case model.FieldTypeReference:
+		id, ok := value.(float64)
+		if !ok || id < 1 || id != math.Trunc(id) {
+			return "must be a positive integer id"
+		}
+		if !s.referenceExists(field.Reference, uint(id)) {
+			return fmt.Sprintf("references a missing %s", field.Reference)
+		}
+	}
+	return ""
+}
+
+func (s *ContentService) referenceExists(target string, id uint) bool {
+	if target == model.ReferenceArticle {
+		_, err := s.articleRepo.GetByID(id)
+		return err == nil
+	}
+
+	contentType, err := s.typeRepo.GetByName(target)
+	if err != nil {
+		return false
+	}
+	_, err = s.repo.GetByID(contentType.ID, id)
+	return err == nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+var (
+	ErrContentTypeExist    = errors.New("content type already exists")
+	ErrContentTypeNotFound = errors.New("content type not found")
+	ErrContentTypeInUse    = errors.New("content type still has entries")
+)
+
+var (
+	contentTypeNamePattern  = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+	contentFieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+)
+
+const contentPathPrefix = "/api/v1/content/"
+
+type ContentTypeService struct {
+	repo          *repository.ContentTypeRepository
+	entryRepo     *repository.ContentEntryRepository
+	policyService *PolicyService
+}
+
+func NewContentTypeService(repo *repository.ContentTypeRepository, entryRepo *repository.ContentEntryRepository, policyService *PolicyService) *ContentTypeService {
+	return &ContentTypeService{
+		repo:          repo,
+		entryRepo:     entryRepo,
+		policyService: policyService,
+	}
+}
+
+type CreateContentTypeRequest struct {
+	Name        string               `json:"name" binding:"required" example:"event"`
+	Label       string               `json:"label" example:"活动"`
+	Description string               `json:"description" example:"线下活动"`
+	Fields      []model.ContentField `json:"fields" binding:"required"`
+}
+
+type UpdateContentTypeRequest struct {
+	Label       string               `json:"label" example:"活动"`
+	Description string               `json:"description" example:"线下活动"`
+	Fields      []model.ContentField `json:"fields"`
+}
+
+// Create 创建内容类型，并为其生成 casbin 权限策略
+func (s *ContentTypeService) Create(req *CreateContentTypeRequest) (*model.ContentType, error) {
+	verr := &ValidationError{}
+	if !contentTypeNamePattern.MatchString(req.Name) {
+		verr.Add("name", "format", "name must start with a lowercase letter and contain only a-z, 0-9, '_' or '-'")
+	}
+	s.validateFields(req.Name, req.Fields, verr)
+	if err := verr.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetByName(req.Name); err == nil {
+		return nil, ErrContentTypeExist
+	}
+
+	contentType := &model.ContentType{
+		Name:        req.Name,
+		Label:       req.Label,
+		Description: req.Description,
+		Fields:      req.Fields,
+	}
+	if err := s.repo.Create(contentType); err != nil {
+		return nil, err
+	}
+
+	if err := s.addPolicies(contentType.Name); err != nil {
+		return nil, err
+	}
+
+	return contentType, nil
+}
+
+func (s *ContentTypeService) List() ([]*model.ContentType, error) {
+	return s.repo.List()
+}
+
+func (s *ContentTypeService) Get(id uint) (*model.ContentType, error) {
+	contentType, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrContentTypeNotFound
+	}
+	return contentType, nil
+}
+
+// Update 更新内容类型。名称不可修改，已有条目会在下次写入时按新结构校验
+func (s *ContentTypeService) Update(id uint, req *UpdateContentTypeRequest) (*model.ContentType, error) {
+	contentType, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrContentTypeNotFound
+	}
+
+	if req.Fields != nil {
+		verr := &ValidationError{}
+		s.validateFields(contentType.Name, req.Fields, verr)
+		if err := verr.Err(); err != nil {
+			return nil, err
+		}
+		contentType.Fields = req.Fields
+	}
+	if req.Label != "" {
+		contentType.Label = req.Label
+	}
+	if req.Description != "" {
+		contentType.Description = req.Description
+	}
+
+	if err := s.repo.Update(contentType); err != nil {
+		return nil, err
+	}
+	return contentType, nil
+}
+
+// Delete 删除内容类型及其权限策略，仍有条目时拒绝删除
+func (s *ContentTypeService) Delete(id uint) error {
+	contentType, err := s.repo.GetByID(id)
+	if err != nil {
+		return ErrContentTypeNotFound
+	}
+
+	count, err := s.entryRepo.CountByType(contentType.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrContentTypeInUse
+	}
+
+	for _, path := range contentTypePaths(contentType.Name) {
+		if err := s.policyService.RemovePoliciesForPath(path); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.Delete(contentType.ID)
+}
+
+func (s *ContentTypeService) validateFields(typeName string, fields []model.ContentField, verr *ValidationError) {
+	if len(fields) == 0 {
+		verr.Add("fields", "required", "at least one field is required")
+		return
+	}
+
+	seen := make(map[string]bool, len(fields))
+	for i, f := range fields {
+		key := fmt.Sprintf("fields[%d]", i)
+		if !contentFieldNamePattern.MatchString(f.Name) {
+			verr.Add(key+".name", "format", fmt.Sprintf("field name %q must start with a lowercase letter and contain only a-z, 0-9 or '_'", f.Name))
+		}
+		if seen[f.Name] {
+			verr.Add(key+".name", "unique", fmt.Sprintf("field name %q is duplicated", f.Name))
+		}
+		seen[f.Name] = true
+
+		if !f.Type.Valid() {
+			verr.Add(key+".type", "oneof", fmt.Sprintf("field %q has unsupported type %q", f.Name, f.Type))
+			continue
+		}
+
+		if f.Type != model.FieldTypeReference {
+			continue
+		}
+		switch f.Reference {
+		case "":
+			verr.Add(key+".reference", "required", fmt.Sprintf("reference field %q must name its target type", f.Name))
+		case model.ReferenceArticle, typeName:
+		default:
+			if _, err := s.repo.GetByName(f.Reference); err != nil {
+				verr.Add(key+".reference", "exists", fmt.Sprintf("reference field %q targets unknown type %q", f.Name, f.Reference))
+			}
+		}
+	}
+}
+
+// addPolicies 管理员拥有完整权限，普通用户只读
+func (s *ContentTypeService) addPolicies(name string) error {
+	paths := contentTypePaths(name)
+	rules := []struct {
+		role, path, method string
+	}{
+		{"admin", paths[0], "GET"},
+		{"admin", paths[0], "POST"},
+		{"admin", paths[1], "GET"},
+		{"admin", paths[1], "PUT"},
+		{"admin", paths[1], "DELETE"},
+		{"user", paths[0], "GET"},
+		{"user", paths[1], "GET"},
+	}
+
+	for _, rule := range rules {
+		if err := s.policyService.AddPolicy(rule.role, rule.path, rule.method); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func contentTypePaths(name string) []string {
+	return []string{
+		contentPathPrefix + name,
+		contentPathPrefix + name + "/:id",
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/ebook"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+const (
+	EbookFormatEPUB = "epub"
+	EbookFormatHTML = "html" // 适合打印及转换 PDF 的单页 HTML
+)
+
+var (
+	ErrEbookSource       = errors.New("exactly one of series_id or article_ids is required")
+	ErrEbookFormat       = errors.New("format must be epub or html")
+	ErrEbookEmpty        = errors.New("none of the selected articles are published")
+	ErrEbookArticleCount = errors.New("an e-book can contain at most 200 articles")
+)
+
+const maxEbookArticles = 200
+
+var unsafeFilenameChars = regexp.MustCompile(`[\\/:*?"<>|\s]+`)
+
+// EbookService 将一组已发布的文章打包成电子书
+type EbookService struct {
+	articleRepo *repository.ArticleRepository
+	seriesRepo  *repository.SeriesRepository
+	siteName    string
+}
+
+func NewEbookService(articleRepo *repository.ArticleRepository, seriesRepo *repository.SeriesRepository, siteName string) *EbookService {
+	return &EbookService{
+		articleRepo: articleRepo,
+		seriesRepo:  seriesRepo,
+		siteName:    siteName,
+	}
+}
+
+// EbookRequest 按系列或文章列表生成电子书，未发布或非公开的文章会被略过
+type EbookRequest struct {
+	SeriesID    uint   `json:"series_id" example:"1"`
+	ArticleIDs  []uint `json:"article_ids" example:"3,1,2"` // 按给定顺序编排
+	Title       string `json:"title" example:"Go 入门教程"`     // 默认取系列标题
+	Author      string `json:"author" example:"CMS 编辑部"`
+	Description string `json:"description" example:"从零开始学习 Go"`
+	Language    string `json:"language" example:"zh-CN"`
+	Format      string `json:"format" example:"epub"` // epub 或 html，默认 epub
+}
+
+type EbookFile struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+func (s *EbookService) Build(req *EbookRequest) (*EbookFile, error) {
+	if (req.SeriesID == 0) == (len(req.ArticleIDs) == 0) {
+		return nil, ErrEbookSource
+	}
+	if req.Format == "" {
+		req.Format = EbookFormatEPUB
+	}
+	if req.Format != EbookFormatEPUB && req.Format != EbookFormatHTML {
+		return nil, ErrEbookFormat
+	}
+
+	book := &ebook.Book{
+		Title:       req.Title,
+		Author:      req.Author,
+		Publisher:   s.siteName,
+		Language:    req.Language,
+		Description: req.Description,
+	}
+
+	ids := req.ArticleIDs
+	if req.SeriesID != 0 {
+		series, err := s.seriesRepo.GetByID(req.SeriesID)
+		if err != nil {
+			return nil, ErrSeriesNotFound
+		}
+		parts, err := s.seriesRepo.ListParts(series.ID)
+		if err != nil {
+			return nil, err
+		}
+		ids = make([]uint, 0, len(parts))
+		for _, part := range parts {
+			ids = append(ids, part.ArticleID)
+		}
+		if book.Title == "" {
+			book.Title = series.Title
+		}
+		if book.Description == "" {
+			book.Description = series.Description
+		}
+	}
+	if len(ids) > maxEbookArticles {
+		return nil, ErrEbookArticleCount
+	}
+	if book.Title == "" {
+		book.Title = s.siteName
+	}
+
+	articles, err := s.articleRepo.ListByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+	for _, id := range ids {
+		// 只收录公开文章，会员可见和密码保护的文章不能借导出绕过访问限制
+		article, ok := byID[id]
+		if !ok || article.Status != model.ArticleStatusPublished || article.Visibility != model.ArticleVisibilityPublic {
+			continue
+		}
+		book.Chapters = append(book.Chapters, &ebook.Chapter{
+			Title:     article.Title,
+			Published: article.PublishedAt,
+			Body:      article.Content,
+		})
+		if book.Modified.Before(article.UpdatedAt) {
+			book.Modified = article.UpdatedAt
+		}
+	}
+	if len(book.Chapters) == 0 {
+		return nil, ErrEbookEmpty
+	}
+
+	var buf bytes.Buffer
+	file := &EbookFile{Name: ebookFilename(book.Title) + "." + req.Format}
+	switch req.Format {
+	case EbookFormatEPUB:
+		file.ContentType = "application/epub+zip"
+		err = ebook.WriteEPUB(&buf, book)
+	case EbookFormatHTML:
+		file.ContentType = "text/html; charset=utf-8"
+		err = ebook.WritePrintHTML(&buf, book)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to build e-book: %v", err)
+	}
+	file.Data = buf.Bytes()
+	return file, nil
+}
+
+func ebookFilename(title string) string {
+	name := strings.Trim(unsafeFilenameChars.ReplaceAllString(title, "-"), "-")
+	if name == "" {
+		return "ebook"
+	}
+	return name
+}
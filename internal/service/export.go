@@ -0,0 +1,330 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/theme"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+var ErrExportRunning = errors.New("an export is already running")
+
+const (
+	exportManifestFile = ".export-manifest.json"
+	exportFeedSize     = 20
+)
+
+// ExportService 将已发布的公开文章导出为静态站点
+type ExportService struct {
+	articleRepo *repository.ArticleRepository
+	seo         *SEOService
+	site        theme.Site
+	themeDir    string
+	dir         string
+	pageSize    int
+	running     sync.Mutex
+}
+
+func NewExportService(articleRepo *repository.ArticleRepository, seo *SEOService, site theme.Site,
+	themeDir, dir string, pageSize int) *ExportService {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	return &ExportService{
+		articleRepo: articleRepo,
+		seo:         seo,
+		site:        site,
+		themeDir:    themeDir,
+		dir:         dir,
+		pageSize:    pageSize,
+	}
+}
+
+type ExportRequest struct {
+	Full bool `json:"full" example:"false"` // 忽略上次导出的记录，重新渲染所有文章
+}
+
+type ExportResult struct {
+	Dir      string `json:"dir" example:"public"`
+	Articles int    `json:"articles" example:"42"`
+	Rendered int    `json:"rendered" example:"3"` // 本次重新渲染的文章数
+	Skipped  int    `json:"skipped" example:"39"` // 未变化而跳过的文章数
+	Removed  int    `json:"removed" example:"1"`  // 已下线而删除的文章数
+	Pages    int    `json:"pages" example:"5"`
+	Duration string `json:"duration" example:"120ms"`
+}
+
+// exportManifest 记录上次导出时每篇文章的更新时间，用于增量导出
+type exportManifest struct {
+	Theme    string               `json:"theme"`
+	Site     theme.Site           `json:"site"`
+	Articles map[string]time.Time `json:"articles"`
+}
+
+// Export 渲染文章页、首页分页、RSS 和 sitemap。模板和站点信息未变时，
+// 只重新渲染上次导出后有更新的文章
+func (s *ExportService) Export(req *ExportRequest) (*ExportResult, error) {
+	if !s.running.TryLock() {
+		return nil, ErrExportRunning
+	}
+	defer s.running.Unlock()
+
+	start := time.Now()
+	th, err := theme.Load(s.themeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	articles, err := s.articleRepo.ListPublished([]string{model.ArticleVisibilityPublic})
+	if err != nil {
+		return nil, err
+	}
+
+	// 全量导出时不跳过任何文章，但删除下线文章仍需要上次导出的文章列表
+	previous := s.readManifest()
+	exported := previous.Articles
+	if req.Full || previous.Theme != th.Fingerprint() || previous.Site != s.site {
+		previous.Articles = map[string]time.Time{}
+	}
+	manifest := &exportManifest{
+		Theme:    th.Fingerprint(),
+		Site:     s.site,
+		Articles: make(map[string]time.Time, len(articles)),
+	}
+
+	result := &ExportResult{Dir: s.dir, Articles: len(articles)}
+	for _, article := range articles {
+		key := strconv.FormatUint(uint64(article.ID), 10)
+		manifest.Articles[key] = article.UpdatedAt
+
+		file := filepath.Join(s.dir, "articles", key, "index.html")
+		if updatedAt, ok := previous.Articles[key]; ok && updatedAt.Equal(article.UpdatedAt) && fileExists(file) {
+			result.Skipped++
+			continue
+		}
+
+		page := &theme.Page{
+			Site:    s.site,
+			Title:   article.Title,
+			Meta:    template.HTML(s.seo.Tags(article).HTML),
+			Article: article,
+		}
+		if err := s.renderFile(th, file, "article", page); err != nil {
+			return nil, err
+		}
+		result.Rendered++
+	}
+
+	for key := range exported {
+		if _, ok := manifest.Articles[key]; !ok {
+			if err := os.RemoveAll(filepath.Join(s.dir, "articles", key)); err != nil {
+				return nil, err
+			}
+			result.Removed++
+		}
+	}
+
+	if result.Pages, err = s.renderIndex(th, articles); err != nil {
+		return nil, err
+	}
+	if err := s.writeFeed(articles); err != nil {
+		return nil, err
+	}
+	if err := s.writeSitemap(articles); err != nil {
+		return nil, err
+	}
+	if err := th.CopyStatic(s.dir); err != nil {
+		return nil, err
+	}
+	if err := s.writeManifest(manifest); err != nil {
+		return nil, err
+	}
+
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	return result, nil
+}
+
+// renderIndex 渲染首页及分页，第一页为 /index.html，之后为 /page/<n>/index.html
+func (s *ExportService) renderIndex(th *theme.Theme, articles []*model.Article) (int, error) {
+	totalPages := (len(articles) + s.pageSize - 1) / s.pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	if err := os.RemoveAll(filepath.Join(s.dir, "page")); err != nil {
+		return 0, err
+	}
+	for page := 1; page <= totalPages; page++ {
+		end := page * s.pageSize
+		if end > len(articles) {
+			end = len(articles)
+		}
+
+		pagination := &theme.Pagination{Page: page, TotalPages: totalPages}
+		if page > 1 {
+			pagination.PrevURL = indexURL(page - 1)
+		}
+		if page < totalPages {
+			pagination.NextURL = indexURL(page + 1)
+		}
+
+		file := filepath.Join(s.dir, "index.html")
+		if page > 1 {
+			file = filepath.Join(s.dir, "page", strconv.Itoa(page), "index.html")
+		}
+		data := &theme.Page{
+			Site:       s.site,
+			Articles:   articles[(page-1)*s.pageSize : end],
+			Pagination: pagination,
+		}
+		if err := s.renderFile(th, file, "index", data); err != nil {
+			return 0, err
+		}
+	}
+	return totalPages, nil
+}
+
+func indexURL(page int) string {
+	if page == 1 {
+		return "/"
+	}
+	return fmt.Sprintf("/page/%d/", page)
+}
+
+func (s *ExportService) renderFile(th *theme.Theme, file, page string, data *theme.Page) error {
+	var buf bytes.Buffer
+	if err := th.Render(&buf, page, data); err != nil {
+		return err
+	}
+	return writeFile(file, buf.Bytes())
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	PubDate     string `xml:"pubDate,omitempty"`
+}
+
+func (s *ExportService) writeFeed(articles []*model.Article) error {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         s.site.Name,
+			Link:          s.site.BaseURL + "/",
+			Description:   s.site.Name,
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	for i, article := range articles {
+		if i == exportFeedSize {
+			break
+		}
+		meta := s.seo.Resolve(article)
+		item := rssItem{
+			Title:       article.Title,
+			Link:        meta.CanonicalURL,
+			GUID:        meta.CanonicalURL,
+			Description: meta.Description,
+		}
+		if article.PublishedAt != nil {
+			item.PubDate = article.PublishedAt.UTC().Format(time.RFC1123Z)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+	return writeXML(filepath.Join(s.dir, "rss.xml"), feed)
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func (s *ExportService) writeSitemap(articles []*model.Article) error {
+	urlset := sitemapURLSet{
+		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  []sitemapURL{{Loc: s.site.BaseURL + "/"}},
+	}
+	for _, article := range articles {
+		meta := s.seo.Resolve(article)
+		if noIndex(meta.Robots) {
+			continue
+		}
+		urlset.URLs = append(urlset.URLs, sitemapURL{
+			Loc:     meta.CanonicalURL,
+			LastMod: article.UpdatedAt.UTC().Format("2006-01-02"),
+		})
+	}
+	return writeXML(filepath.Join(s.dir, "sitemap.xml"), urlset)
+}
+
+func (s *ExportService) readManifest() *exportManifest {
+	manifest := &exportManifest{}
+	if b, err := os.ReadFile(filepath.Join(s.dir, exportManifestFile)); err == nil {
+		_ = json.Unmarshal(b, manifest)
+	}
+	if manifest.Articles == nil {
+		manifest.Articles = map[string]time.Time{}
+	}
+	return manifest
+}
+
+func (s *ExportService) writeManifest(manifest *exportManifest) error {
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(s.dir, exportManifestFile), b)
+}
+
+func writeXML(file string, v interface{}) error {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(file, append([]byte(xml.Header), b...))
+}
+
+func writeFile(file string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, b, 0644)
+}
+
+func fileExists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
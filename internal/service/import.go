@@ -0,0 +1,307 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/wxr"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+var (
+	ErrInvalidWXR    = errors.New("file is not a valid WordPress WXR export")
+	ErrImportRunning = errors.New("an import is already running")
+)
+
+// ImportCounts 某类对象的导入结果
+type ImportCounts struct {
+	Created  int `json:"created" example:"10"`
+	Existing int `json:"existing" example:"2"` // 之前已导入或与现有对象匹配
+	Skipped  int `json:"skipped" example:"1"`
+	Failed   int `json:"failed" example:"0"` // 写入失败，已回滚，重新导入时会再次尝试
+}
+
+// ImportUnsupported 本系统暂无对应模型而未导入的对象数量，同时在 warnings 中列出
+type ImportUnsupported struct {
+	Categories  int `json:"categories" example:"5"`
+	Tags        int `json:"tags" example:"12"`
+	Comments    int `json:"comments" example:"30"`
+	Attachments int `json:"attachments" example:"8"`
+}
+
+type ImportReport struct {
+	DryRun      bool              `json:"dry_run" example:"true"`
+	Source      string            `json:"source" example:"wordpress:https://blog.example.com"`
+	Users       ImportCounts      `json:"users"`
+	Articles    ImportCounts      `json:"articles"`
+	Unsupported ImportUnsupported `json:"unsupported"`
+	Warnings    []string          `json:"warnings"`
+}
+
+func (r *ImportReport) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// ImportService 从 WordPress 导出文件导入用户和文章。
+// 每个导入的对象都会记录原始 ID，重复导入同一站点时跳过已导入的对象
+type ImportService struct {
+	repo          *repository.ImportRepository
+	userRepo      *repository.UserRepository
+	policyService *PolicyService
+	running       sync.Mutex
+}
+
+func NewImportService(repo *repository.ImportRepository, userRepo *repository.UserRepository,
+	policyService *PolicyService) *ImportService {
+	return &ImportService{
+		repo:          repo,
+		userRepo:      userRepo,
+		policyService: policyService,
+	}
+}
+
+// FindOwner 按用户名查找导入文章的默认所有者，供命令行导入使用
+func (s *ImportService) FindOwner(username string) (uint, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return 0, ErrUserNotFound
+	}
+	return user.ID, nil
+}
+
+// ImportWordPress 导入 WXR 文件，作者未知的文章归 ownerID 所有。dryRun 时只生成报告，不写入任何数据
+func (s *ImportService) ImportWordPress(r io.Reader, ownerID uint, dryRun bool) (*ImportReport, error) {
+	if !s.running.TryLock() {
+		return nil, ErrImportRunning
+	}
+	defer s.running.Unlock()
+
+	export, err := wxr.Parse(r)
+	if err != nil {
+		return nil, ErrInvalidWXR
+	}
+
+	site := export.BaseURL
+	if site == "" {
+		site = export.Link
+	}
+	report := &ImportReport{
+		DryRun:   dryRun,
+		Source:   "wordpress:" + strings.TrimRight(site, "/"),
+		Warnings: []string{},
+	}
+	if _, err := s.userRepo.FindById(ownerID); err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	users, err := s.importUsers(export, report)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.importArticles(export, users, ownerID, report); err != nil {
+		return nil, err
+	}
+	countUnsupported(export, report)
+	return report, nil
+}
+
+// countUnsupported 统计没有对应模型而未导入的对象，包括只出现在文章上、未在站点级别声明的分类和标签
+func countUnsupported(export *wxr.Export, report *ImportReport) {
+	categories := make(map[string]bool)
+	tags := make(map[string]bool)
+	for _, c := range export.Categories {
+		categories[c.Nicename] = true
+	}
+	for _, t := range export.Tags {
+		tags[t.Slug] = true
+	}
+	for i := range export.Items {
+		for _, term := range export.Items[i].Terms {
+			switch term.Domain {
+			case "category":
+				categories[term.Nicename] = true
+			case "post_tag":
+				tags[term.Nicename] = true
+			}
+		}
+	}
+	report.Unsupported.Categories = len(categories)
+	report.Unsupported.Tags = len(tags)
+
+	u := report.Unsupported
+	if u.Categories+u.Tags+u.Comments+u.Attachments > 0 {
+		report.warn("not imported, no equivalent in this CMS: %d categories, %d tags, %d comments, %d attachments",
+			u.Categories, u.Tags, u.Comments, u.Attachments)
+	}
+}
+
+// importUsers 导入作者，返回 author_login 到本地用户 ID 的映射。
+// 用户名或邮箱已存在时直接关联到现有用户；新用户使用随机密码，需要重置密码后登录
+func (s *ImportService) importUsers(export *wxr.Export, report *ImportReport) (map[string]uint, error) {
+	users := make(map[string]uint, len(export.Authors))
+	for _, author := range export.Authors {
+		if author.Login == "" {
+			report.Users.Skipped++
+			continue
+		}
+		externalID := strconv.Itoa(author.ID)
+		if author.ID == 0 {
+			externalID = author.Login
+		}
+
+		if mapping, err := s.repo.Find(report.Source, model.ImportKindUser, externalID); err == nil {
+			users[author.Login] = mapping.TargetID
+			report.Users.Existing++
+			continue
+		}
+
+		user, err := s.userRepo.FindByUsername(author.Login)
+		if err != nil && author.Email != "" {
+			user, err = s.userRepo.FindByEmail(author.Email)
+		}
+		if err == nil {
+			if !report.DryRun {
+				if err := s.repo.Create(s.mapping(report, model.ImportKindUser, externalID, user.ID)); err != nil {
+					report.Users.Failed++
+					report.warn("author %s: failed to record import: %v", author.Login, err)
+					continue
+				}
+			}
+			report.Users.Existing++
+			users[author.Login] = user.ID
+			continue
+		}
+
+		if report.DryRun {
+			report.Users.Created++
+			continue
+		}
+
+		user = &model.User{
+			Username: author.Login,
+			Email:    author.Email,
+			Status:   model.UserStatusActive,
+		}
+		if err := user.SetPassword(randomHex(24)); err != nil {
+			return nil, err
+		}
+		if err := s.repo.CreateUser(user, s.mapping(report, model.ImportKindUser, externalID, 0)); err != nil {
+			report.Users.Failed++
+			report.warn("author %s: failed to import: %v", author.Login, err)
+			continue
+		}
+		if err := s.policyService.AddRoleForUser(user.Username, "user"); err != nil {
+			return nil, fmt.Errorf("failed to assign default role: %v", err)
+		}
+		report.Users.Created++
+		users[author.Login] = user.ID
+	}
+	return users, nil
+}
+
+// importArticles 导入文章和页面，每篇文章连同所有者权限和导入记录在一个事务中写入
+func (s *ImportService) importArticles(export *wxr.Export, users map[string]uint, ownerID uint, report *ImportReport) error {
+	for i := range export.Items {
+		item := &export.Items[i]
+		report.Unsupported.Comments += len(item.Comments)
+
+		switch item.PostType {
+		case "post", "page":
+		case "attachment":
+			report.Unsupported.Attachments++
+			continue
+		default:
+			continue
+		}
+
+		externalID := strconv.Itoa(item.PostID)
+		if _, err := s.repo.Find(report.Source, model.ImportKindArticle, externalID); err == nil {
+			report.Articles.Existing++
+			continue
+		}
+
+		article, ok := mapArticle(item, report)
+		if !ok {
+			report.Articles.Skipped++
+			continue
+		}
+
+		owner, ok := users[item.Creator]
+		if !ok {
+			owner = ownerID
+			report.warn("post %d: author %q not found, article is owned by the importing user", item.PostID, item.Creator)
+		}
+
+		if report.DryRun {
+			report.Articles.Created++
+			continue
+		}
+		if err := s.repo.CreateArticle(article, owner, s.mapping(report, model.ImportKindArticle, externalID, 0)); err != nil {
+			report.Articles.Failed++
+			report.warn("post %d: failed to import: %v", item.PostID, err)
+			continue
+		}
+		report.Articles.Created++
+	}
+	return nil
+}
+
+// mapArticle 将 WordPress 文章转换为本地文章，返回 false 表示跳过
+func mapArticle(item *wxr.Item, report *ImportReport) (*model.Article, bool) {
+	title := strings.TrimSpace(item.Title)
+	content := item.Content()
+	if title == "" || content == "" {
+		report.warn("post %d: skipped, title or content is empty", item.PostID)
+		return nil, false
+	}
+
+	article := &model.Article{
+		Title:      truncateRunes(title, 200),
+		Excerpt:    truncateRunes(strings.TrimSpace(item.Excerpt()), maxExcerptLength),
+		Content:    content,
+		Status:     model.ArticleStatusDraft,
+		Visibility: model.ArticleVisibilityPublic,
+	}
+
+	switch item.Status {
+	case "publish":
+		article.Status = model.ArticleStatusPublished
+		article.PublishedAt = item.PublishedAt()
+	case "private":
+		article.Status = model.ArticleStatusPublished
+		article.PublishedAt = item.PublishedAt()
+		article.Visibility = model.ArticleVisibilityPrivate
+	case "future":
+		report.warn("post %d: scheduled post imported as draft", item.PostID)
+	case "draft", "pending":
+	default:
+		// auto-draft、trash、inherit 等
+		return nil, false
+	}
+
+	if item.PostPassword != "" && article.Visibility == model.ArticleVisibilityPublic {
+		article.Visibility = model.ArticleVisibilityPassword
+		if err := article.SetPassword(item.PostPassword); err != nil {
+			report.warn("post %d: failed to keep password protection: %v", item.PostID, err)
+			return nil, false
+		}
+	}
+	if t := item.PublishedAt(); t != nil {
+		article.CreatedAt = *t
+	}
+	return article, true
+}
+
+func (s *ImportService) mapping(report *ImportReport, kind, externalID string, targetID uint) *model.ImportMapping {
+	return &model.ImportMapping{
+		Source:     report.Source,
+		Kind:       kind,
+		ExternalID: externalID,
+		TargetID:   targetID,
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/wuwen/hello-go/internal/pkg/lint"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+// ArticleLintError 发布前检查发现了阻止发布的问题
+type ArticleLintError struct {
+	Findings []lint.Finding `json:"findings"`
+}
+
+func (e *ArticleLintError) Error() string {
+	return "article has lint errors that block publishing"
+}
+
+// internalLinkPattern 匹配站内文章链接，如 /articles/42 或 https://example.com/articles/42
+var internalLinkPattern = regexp.MustCompile(`(?:^|[\s("'=\[])([^\s()"'\[\]]*?)/articles/(\d+)\b`)
+
+// BrokenLinkRule 检查指向已删除文章的站内链接
+type BrokenLinkRule struct {
+	articleRepo *repository.ArticleRepository
+	baseURL     string
+}
+
+func NewBrokenLinkRule(articleRepo *repository.ArticleRepository, baseURL string) *BrokenLinkRule {
+	return &BrokenLinkRule{
+		articleRepo: articleRepo,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (r *BrokenLinkRule) Name() string                   { return "broken-link" }
+func (r *BrokenLinkRule) DefaultSeverity() lint.Severity { return lint.SeverityError }
+
+func (r *BrokenLinkRule) Check(doc *lint.Document) ([]lint.Finding, error) {
+	type link struct {
+		id   uint
+		line int
+	}
+	var links []link
+	ids := make([]uint, 0)
+	for i, line := range strings.Split(doc.Content, "\n") {
+		for _, match := range internalLinkPattern.FindAllStringSubmatch(line, -1) {
+			// 只检查相对链接和本站链接
+			if prefix := match[1]; prefix != "" && prefix != r.baseURL {
+				continue
+			}
+			id, err := strconv.ParseUint(match[2], 10, 32)
+			if err != nil {
+				continue
+			}
+			links = append(links, link{id: uint(id), line: i + 1})
+			ids = append(ids, uint(id))
+		}
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	articles, err := r.articleRepo.ListByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[uint]bool, len(articles))
+	for _, article := range articles {
+		exists[article.ID] = true
+	}
+
+	var findings []lint.Finding
+	for _, l := range links {
+		if !exists[l.id] {
+			findings = append(findings, lint.Finding{
+				Message:  fmt.Sprintf("link to article %d points to a deleted article", l.id),
+				Location: lint.Location{Field: "content", Line: l.line},
+			})
+		}
+	}
+	return findings, nil
+}
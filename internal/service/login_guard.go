@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+const loginGuardPruneInterval = time.Hour
+
+// LockoutPolicy 登录失败的限制策略
+type LockoutPolicy struct {
+	MaxAttempts   int           // 同一用户名连续失败多少次后锁定
+	IPMaxAttempts int           // 同一 IP 连续失败多少次后锁定
+	Window        time.Duration // 超过该时间没有新的失败时重新计数
+	Duration      time.Duration // 锁定时长
+	BaseDelay     time.Duration // 第一次失败后需要等待的时间，之后每次失败翻倍
+	MaxDelay      time.Duration
+}
+
+// LoginBlockedError 登录因失败次数过多被拒绝，RetryAfter 后才能再次尝试
+type LoginBlockedError struct {
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	wait := e.RetryAfter.Round(time.Second)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	if e.Locked {
+		return fmt.Sprintf("too many failed login attempts, login is locked for %s", wait)
+	}
+	return fmt.Sprintf("too many failed login attempts, try again in %s", wait)
+}
+
+// LoginLockEvent 锁定及解锁的安全事件
+type LoginLockEvent struct {
+	Target      string     `json:"target" example:"user:testuser"` // user:<用户名> 或 ip:<地址>
+	Failures    int        `json:"failures,omitempty" example:"5"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	UnlockedBy  uint       `json:"unlocked_by,omitempty" example:"1"`
+}
+
+// LoginGuardService 按用户名和 IP 统计连续的登录失败。失败后需要等待逐次翻倍的时间才能再次尝试，
+// 达到阈值后锁定一段时间。不存在的用户名同样计数，避免通过锁定行为判断账号是否存在
+type LoginGuardService struct {
+	repo     *repository.LoginFailureRepository
+	webhooks *WebhookService
+	policy   LockoutPolicy
+}
+
+func NewLoginGuardService(repo *repository.LoginFailureRepository, webhooks *WebhookService, policy LockoutPolicy) *LoginGuardService {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 5
+	}
+	if policy.IPMaxAttempts <= 0 {
+		policy.IPMaxAttempts = 20
+	}
+	if policy.Window <= 0 {
+		policy.Window = 15 * time.Minute
+	}
+	if policy.Duration <= 0 {
+		policy.Duration = 15 * time.Minute
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = time.Second
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		policy.MaxDelay = 30 * time.Second
+	}
+	return &LoginGuardService{
+		repo:     repo,
+		webhooks: webhooks,
+		policy:   policy,
+	}
+}
+
+// Check 在校验密码前调用，用户名或 IP 处于锁定或等待中时返回 *LoginBlockedError
+func (s *LoginGuardService) Check(username, ip string) error {
+	failures, err := s.repo.FindByTargets(loginTargets(username, ip))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var blocked *LoginBlockedError
+	for _, failure := range failures {
+		var wait time.Duration
+		locked := false
+		switch {
+		case failure.LockedUntil != nil && now.Before(*failure.LockedUntil):
+			wait, locked = failure.LockedUntil.Sub(now), true
+		case now.Sub(failure.LastFailedAt) < s.policy.Window:
+			wait = failure.LastFailedAt.Add(s.delay(failure.Failures)).Sub(now)
+		}
+		if wait > 0 && (blocked == nil || wait > blocked.RetryAfter) {
+			blocked = &LoginBlockedError{Locked: locked, RetryAfter: wait}
+		}
+	}
+	if blocked != nil {
+		return blocked
+	}
+	return nil
+}
+
+// Fail 记录一次登录失败，达到阈值时锁定并发出安全事件。
+// 计数在数据库中原子递增，并发的失败不会互相覆盖，每次锁定只发出一次事件
+func (s *LoginGuardService) Fail(username, ip string) error {
+	now := time.Now()
+	for _, target := range loginTargets(username, ip) {
+		failure, err := s.repo.Increment(target, now, now.Add(-s.policy.Window))
+		if err != nil {
+			return err
+		}
+		if failure.Failures < s.threshold(target) || s.locked(failure, now) {
+			continue
+		}
+
+		until := now.Add(s.policy.Duration)
+		locked, err := s.repo.Lock(target, until, now)
+		if err != nil {
+			return err
+		}
+		if locked {
+			log.Printf("security: login locked for %s after %d failed attempts", target, failure.Failures)
+			s.webhooks.Publish(EventLoginLocked, &LoginLockEvent{
+				Target:      target,
+				Failures:    failure.Failures,
+				LockedUntil: &until,
+			})
+		}
+	}
+	return nil
+}
+
+// Succeed 登录成功后清除用户名的失败记录。IP 的记录保留，避免用一个可登录的账号重置 IP 计数
+func (s *LoginGuardService) Succeed(username string) error {
+	_, err := s.repo.Delete(userLoginTarget(username))
+	return err
+}
+
+// Unlock 管理员解除用户名的锁定
+func (s *LoginGuardService) Unlock(username string, adminID uint) error {
+	target := userLoginTarget(username)
+	n, err := s.repo.Delete(target)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("security: login unlocked for %s by user %d", target, adminID)
+		s.webhooks.Publish(EventLoginUnlocked, &LoginLockEvent{Target: target, UnlockedBy: adminID})
+	}
+	return nil
+}
+
+// Run 定期清理过期的失败记录，随服务启动
+func (s *LoginGuardService) Run(ctx context.Context) {
+	ticker := time.NewTicker(loginGuardPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			if _, err := s.repo.DeleteStale(now.Add(-s.policy.Window), now); err != nil {
+				log.Printf("security: failed to prune login failures: %v", err)
+			}
+		}
+	}
+}
+
+// delay 第 n 次失败后需要等待的时间
+func (s *LoginGuardService) delay(n int) time.Duration {
+	delay := s.policy.BaseDelay
+	for i := 1; i < n && delay < s.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.policy.MaxDelay {
+		delay = s.policy.MaxDelay
+	}
+	return delay
+}
+
+func (s *LoginGuardService) threshold(target string) int {
+	if strings.HasPrefix(target, "ip:") {
+		return s.policy.IPMaxAttempts
+	}
+	return s.policy.MaxAttempts
+}
+
+func (s *LoginGuardService) locked(failure *model.LoginFailure, now time.Time) bool {
+	return failure.LockedUntil != nil && now.Before(*failure.LockedUntil)
+}
+
+func loginTargets(username, ip string) []string {
+	targets := []string{userLoginTarget(username)}
+	if ip != "" {
+		targets = append(targets, "ip:"+ip)
+	}
+	return targets
+}
+
+// userLoginTarget 用户名不区分大小写计数
+func userLoginTarget(username string) string {
+	return "user:" + strings.ToLower(username)
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+func TestLoginGuardLocksAtThreshold(t *testing.T) {
+	db := newTestDB(t)
+	guard := NewLoginGuardService(repository.NewLoginFailureRepository(db),
+		NewWebhookService(repository.NewWebhookRepository(db)),
+		LockoutPolicy{MaxAttempts: 3, IPMaxAttempts: 10, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	for i := 0; i < 3; i++ {
+		if err := guard.Fail("Alice", "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var failure model.LoginFailure
+	if err := db.Where("target = ?", "user:alice").First(&failure).Error; err != nil {
+		t.Fatal(err)
+	}
+	if failure.Failures != 3 || failure.LockedUntil == nil {
+		t.Fatalf("after 3 failures: failures = %d, locked until %v", failure.Failures, failure.LockedUntil)
+	}
+	var blocked *LoginBlockedError
+	if err := guard.Check("alice", "10.0.0.2"); !errors.As(err, &blocked) || !blocked.Locked {
+		t.Fatalf("Check on locked user = %v, want locked", err)
+	}
+
+	var ip model.LoginFailure
+	if err := db.Where("target = ?", "ip:10.0.0.1").First(&ip).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ip.Failures != 3 || ip.LockedUntil != nil {
+		t.Fatalf("ip below threshold: failures = %d, locked until %v", ip.Failures, ip.LockedUntil)
+	}
+}
+
+func TestLoginGuardRestartsCountAfterWindow(t *testing.T) {
+	db := newTestDB(t)
+	repo := repository.NewLoginFailureRepository(db)
+	now := time.Now()
+	if err := db.Create(&model.LoginFailure{Target: "user:bob", Failures: 4, LastFailedAt: now.Add(-time.Hour)}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	failure, err := repo.Increment("user:bob", now, now.Add(-15*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failure.Failures != 1 || !failure.LastFailedAt.Equal(now) {
+		t.Fatalf("stale record: failures = %d, last failed %v", failure.Failures, failure.LastFailedAt)
+	}
+	if failure, err = repo.Increment("user:bob", now, now.Add(-15*time.Minute)); err != nil || failure.Failures != 2 {
+		t.Fatalf("second failure = %v, %v, want 2", failure, err)
+	}
+}
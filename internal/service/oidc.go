@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+	"github.com/wuwen/hello-go/internal/pkg/oidc"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+const (
+	oidcStateAudience = "oidc-state"
+	// OIDCStateExpire 从跳转到身份提供方到回调之间允许的最长时间
+	OIDCStateExpire = 10 * time.Minute
+)
+
+var (
+	ErrOIDCInvalidState   = errors.New("invalid or expired login state, please start the login again")
+	ErrOIDCDenied         = errors.New("login was denied by the identity provider")
+	ErrOIDCAuthFailed     = errors.New("failed to authenticate with the identity provider")
+	ErrOIDCMissingEmail   = errors.New("the identity provider did not return a verified email address")
+	ErrOIDCNotProvisioned = errors.New("no account is linked to this identity")
+	ErrOIDCEmailConflict  = errors.New("a local account already uses this email address")
+)
+
+var invalidUsernameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// OIDCRoleMapping 声明值到角色的映射：role 声明中包含 Value 时授予 Role
+type OIDCRoleMapping struct {
+	Value string
+	Role  string
+}
+
+// OIDCOptions OIDC 登录的用户映射规则
+type OIDCOptions struct {
+	UsernameClaim string // 默认 preferred_username
+	EmailClaim    string // 默认 email
+	RoleClaim     string // 默认 groups
+	RoleMappings  []OIDCRoleMapping
+	DefaultRole   string // 新用户没有映射到任何角色时分配的角色，默认 user
+	AutoCreate    bool   // 首次登录时自动创建用户
+}
+
+// OIDCAuthorization 开始登录时返回，State 需要原样带回回调请求，通常保存在 cookie 中
+type OIDCAuthorization struct {
+	URL   string
+	State string
+}
+
+type OIDCCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state" binding:"required"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// OIDCService 通过外部 OIDC 身份提供方登录，首次登录时创建用户，每次登录按声明同步角色
+type OIDCService struct {
+	client        *oidc.Client
+	repo          *repository.ExternalIdentityRepository
+	userRepo      *repository.UserRepository
+	policyService *PolicyService
+	users         *UserService
+	webhooks      *WebhookService
+	options       OIDCOptions
+}
+
+func NewOIDCService(client *oidc.Client, repo *repository.ExternalIdentityRepository, userRepo *repository.UserRepository,
+	policyService *PolicyService, users *UserService, webhooks *WebhookService, options OIDCOptions) *OIDCService {
+	if options.UsernameClaim == "" {
+		options.UsernameClaim = "preferred_username"
+	}
+	if options.EmailClaim == "" {
+		options.EmailClaim = "email"
+	}
+	if options.RoleClaim == "" {
+		options.RoleClaim = "groups"
+	}
+	if options.DefaultRole == "" {
+		options.DefaultRole = "user"
+	}
+	return &OIDCService{
+		client:        client,
+		repo:          repo,
+		userRepo:      userRepo,
+		policyService: policyService,
+		users:         users,
+		webhooks:      webhooks,
+		options:       options,
+	}
+}
+
+// Begin 生成 state、nonce 及 PKCE code verifier，返回身份提供方的登录地址。
+// 三者签名后放在 State 中，回调时校验
+func (s *OIDCService) Begin(ctx context.Context) (*OIDCAuthorization, error) {
+	state, nonce, verifier := oidc.RandomString(16), oidc.RandomString(16), oidc.NewVerifier()
+
+	authURL, err := s.client.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := auth.GenerateScopedToken(oidcStateAudience, strings.Join([]string{state, nonce, verifier}, "."), OIDCStateExpire)
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCAuthorization{URL: authURL, State: signed}, nil
+}
+
+// Callback 处理身份提供方的回调：校验 state，用授权码换取并校验 ID token，然后登录对应的用户
+func (s *OIDCService) Callback(ctx context.Context, req *OIDCCallbackRequest, signedState string) (*LoginResponse, error) {
+	subject, err := auth.ParseScopedToken(oidcStateAudience, signedState)
+	if err != nil {
+		return nil, ErrOIDCInvalidState
+	}
+	parts := strings.Split(subject, ".")
+	if len(parts) != 3 || parts[0] != req.State {
+		return nil, ErrOIDCInvalidState
+	}
+	nonce, verifier := parts[1], parts[2]
+
+	if req.Error != "" {
+		log.Printf("oidc: provider returned %s: %s", req.Error, req.ErrorDescription)
+		return nil, ErrOIDCDenied
+	}
+	if req.Code == "" {
+		return nil, ErrOIDCAuthFailed
+	}
+
+	token, err := s.client.Exchange(ctx, req.Code, verifier)
+	if err != nil {
+		log.Printf("oidc: code exchange failed: %v", err)
+		return nil, ErrOIDCAuthFailed
+	}
+	idToken, err := s.client.Verify(ctx, token.IDToken, nonce)
+	if err != nil {
+		log.Printf("oidc: id token rejected: %v", err)
+		return nil, ErrOIDCAuthFailed
+	}
+
+	user, err := s.userFor(idToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.syncRoles(user, idToken); err != nil {
+		return nil, err
+	}
+	return s.users.LoginExternal(user)
+}
+
+// userFor 返回外部身份关联的用户，不存在时按配置创建
+func (s *OIDCService) userFor(idToken *oidc.IDToken) (*model.User, error) {
+	identity, err := s.repo.Find(idToken.Issuer, idToken.Subject)
+	if err == nil {
+		if err := s.repo.Touch(identity.ID, time.Now()); err != nil {
+			return nil, err
+		}
+		user, err := s.userRepo.FindById(identity.UserID)
+		if err != nil {
+			return nil, ErrOIDCNotProvisioned
+		}
+		return user, nil
+	}
+	if !s.options.AutoCreate {
+		return nil, ErrOIDCNotProvisioned
+	}
+
+	// 只使用身份提供方确认过的邮箱（email_verified 为 true，缺少该声明视为未确认），
+	// 且不自动关联已有的本地账号，避免借助邮箱接管账号
+	email := idToken.String(s.options.EmailClaim)
+	if verified, _ := idToken.Bool("email_verified"); email == "" || !verified {
+		return nil, ErrOIDCMissingEmail
+	}
+	if _, err := s.userRepo.FindByEmail(email); err == nil {
+		return nil, ErrOIDCEmailConflict
+	}
+
+	username, err := s.availableUsername(idToken, email)
+	if err != nil {
+		return nil, err
+	}
+	user := &model.User{
+		Username: username,
+		Email:    email,
+		Locale:   idToken.String("locale"),
+		Status:   model.UserStatusActive,
+	}
+	// 外部账号没有本地密码，设置一个不会被使用的随机密码
+	if err := user.SetPassword(randomHex(32)); err != nil {
+		return nil, err
+	}
+	if user, err = s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.repo.Create(&model.ExternalIdentity{
+		UserID:      user.ID,
+		Issuer:      idToken.Issuer,
+		Subject:     idToken.Subject,
+		LastLoginAt: &now,
+	}); err != nil {
+		return nil, err
+	}
+
+	// 没有映射到任何角色时分配默认角色
+	if len(s.mappedRoles(idToken)) == 0 {
+		if err := s.policyService.AddRoleForUser(user.Username, s.options.DefaultRole); err != nil {
+			return nil, fmt.Errorf("failed to assign default role: %v", err)
+		}
+	}
+
+	s.webhooks.Publish(EventUserRegistered, user)
+	return user, nil
+}
+
+// availableUsername 由用户名声明或邮箱生成本地用户名，已被占用时追加序号
+func (s *OIDCService) availableUsername(idToken *oidc.IDToken, email string) (string, error) {
+	base := invalidUsernameChars.ReplaceAllString(idToken.String(s.options.UsernameClaim), "")
+	if base == "" {
+		base = invalidUsernameChars.ReplaceAllString(strings.SplitN(email, "@", 2)[0], "")
+	}
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	for i := 1; i <= 20; i++ {
+		username := base
+		if i > 1 {
+			username = fmt.Sprintf("%s-%d", base, i)
+		}
+		if _, err := s.userRepo.FindByUsername(username); err != nil {
+			return username, nil
+		}
+	}
+	return base + "-" + randomHex(4), nil
+}
+
+// mappedRoles 返回 ID token 中的声明映射到的角色
+func (s *OIDCService) mappedRoles(idToken *oidc.IDToken) map[string]bool {
+	values := make(map[string]bool)
+	for _, v := range idToken.Strings(s.options.RoleClaim) {
+		values[v] = true
+	}
+	roles := make(map[string]bool)
+	for _, m := range s.options.RoleMappings {
+		if values[m.Value] {
+			roles[m.Role] = true
+		}
+	}
+	return roles
+}
+
+// syncRoles 按声明授予或移除映射中出现的角色，不在映射中的角色保持不变
+func (s *OIDCService) syncRoles(user *model.User, idToken *oidc.IDToken) error {
+	granted := s.mappedRoles(idToken)
+	managed := make(map[string]bool)
+	for _, m := range s.options.RoleMappings {
+		if managed[m.Role] {
+			continue
+		}
+		managed[m.Role] = true
+
+		has, err := s.policyService.HasRoleForUser(user.Username, m.Role)
+		if err != nil {
+			return fmt.Errorf("failed to get user roles: %v", err)
+		}
+		switch {
+		case granted[m.Role] && !has:
+			if err := s.policyService.AddRoleForUser(user.Username, m.Role); err != nil {
+				return err
+			}
+		case !granted[m.Role] && has:
+			if err := s.policyService.RemoveRoleForUser(user.Username, m.Role); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+	"github.com/wuwen/hello-go/internal/pkg/oidc"
+	"github.com/wuwen/hello-go/internal/pkg/oidc/oidctest"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+func newTestOIDCService(t *testing.T, db *gorm.DB, policy *PolicyService, options OIDCOptions) (*OIDCService, *oidctest.Provider) {
+	t.Helper()
+	auth.Initialize("test-secret", time.Minute)
+	provider := oidctest.NewProvider("cms", "")
+	t.Cleanup(provider.Close)
+	client := oidc.NewClient(oidc.Config{
+		Issuer:      provider.Issuer(),
+		ClientID:    "cms",
+		RedirectURL: "http://localhost/api/v1/auth/oidc/callback",
+	}, nil)
+
+	userRepo := repository.NewUserRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	users := NewUserService(userRepo, roleRepo, policy,
+		NewTokenService(repository.NewRefreshTokenRepository(db), userRepo, time.Minute, time.Hour),
+		nil, nil, nil, nil, NewTwoFactorService(repository.NewTwoFactorRepository(db), userRepo, roleRepo, policy, "test"),
+		nil, nil, nil)
+	svc := NewOIDCService(client, repository.NewExternalIdentityRepository(db), userRepo, policy, users,
+		NewWebhookService(repository.NewWebhookRepository(db)), options)
+	return svc, provider
+}
+
+// oidcLogin 以 provider 当前设置的身份完成一次登录
+func oidcLogin(t *testing.T, svc *OIDCService, provider *oidctest.Provider) (*LoginResponse, error) {
+	t.Helper()
+	ctx := context.Background()
+	begin, err := svc.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback, err := provider.Authorize(begin.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := callback.Query()
+	return svc.Callback(ctx, &OIDCCallbackRequest{Code: q.Get("code"), State: q.Get("state")}, begin.State)
+}
+
+func TestOIDCProvisionsUserAndSyncsRoles(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	svc, provider := newTestOIDCService(t, db, policy, OIDCOptions{
+		AutoCreate:   true,
+		RoleMappings: []OIDCRoleMapping{{Value: "cms-admins", Role: "admin"}},
+	})
+	claims := map[string]interface{}{
+		"sub":                "idp-1",
+		"email":              "jdoe@example.com",
+		"email_verified":     true,
+		"preferred_username": "jdoe",
+		"groups":             []string{"cms-admins"},
+	}
+	provider.SetClaims(claims)
+
+	first, err := oidcLogin(t, svc, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.User == nil || first.User.Username != "jdoe" || first.TokenPair == nil {
+		t.Fatalf("first login = %+v, want tokens for new user jdoe", first)
+	}
+	if ok, _ := policy.HasRoleForUser("jdoe", "admin"); !ok {
+		t.Fatal("mapped admin role not granted on first login")
+	}
+	if ok, _ := policy.HasRoleForUser("jdoe", "user"); ok {
+		t.Fatal("default role granted although a mapped role applied")
+	}
+
+	// 身份提供方移除分组后，下次登录撤销对应角色，并登录到同一个用户
+	claims["groups"] = []string{}
+	provider.SetClaims(claims)
+	second, err := oidcLogin(t, svc, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.User.ID != first.User.ID {
+		t.Fatalf("second login user %d, want %d", second.User.ID, first.User.ID)
+	}
+	if ok, _ := policy.HasRoleForUser("jdoe", "admin"); ok {
+		t.Fatal("admin role kept after the group was removed")
+	}
+}
+
+func TestOIDCRejectsUnverifiedEmailAndUnknownIdentity(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	svc, provider := newTestOIDCService(t, db, policy, OIDCOptions{AutoCreate: true})
+	provider.SetClaims(map[string]interface{}{"sub": "idp-1", "email": "jdoe@example.com", "email_verified": false})
+	if _, err := oidcLogin(t, svc, provider); err != ErrOIDCMissingEmail {
+		t.Fatalf("unverified email = %v, want ErrOIDCMissingEmail", err)
+	}
+	provider.SetClaims(map[string]interface{}{"sub": "idp-1", "email": "jdoe@example.com"})
+	if _, err := oidcLogin(t, svc, provider); err != ErrOIDCMissingEmail {
+		t.Fatalf("email without email_verified = %v, want ErrOIDCMissingEmail", err)
+	}
+	if _, err := repository.NewUserRepository(db).FindByEmail("jdoe@example.com"); err == nil {
+		t.Fatal("user created from an unverified email")
+	}
+
+	svc, provider = newTestOIDCService(t, db, policy, OIDCOptions{})
+	provider.SetClaims(map[string]interface{}{"sub": "idp-2", "email": "other@example.com"})
+	if _, err := oidcLogin(t, svc, provider); err != ErrOIDCNotProvisioned {
+		t.Fatalf("unknown identity without auto-create = %v, want ErrOIDCNotProvisioned", err)
+	}
+}
+
+func TestOIDCRejectsStateMismatch(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	svc, provider := newTestOIDCService(t, db, policy, OIDCOptions{AutoCreate: true})
+	provider.SetClaims(map[string]interface{}{"sub": "idp-1", "email": "jdoe@example.com"})
+	ctx := context.Background()
+
+	begin, err := svc.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback, err := provider.Authorize(begin.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := callback.Query().Get("code")
+
+	// 另一次登录的 state，例如攻击者诱导受害者完成自己发起的登录
+	other, err := svc.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Callback(ctx, &OIDCCallbackRequest{Code: code, State: callback.Query().Get("state")}, other.State); err != ErrOIDCInvalidState {
+		t.Fatalf("callback with another login's state cookie = %v, want ErrOIDCInvalidState", err)
+	}
+	if _, err := svc.Callback(ctx, &OIDCCallbackRequest{Code: code, State: "forged"}, begin.State); err != ErrOIDCInvalidState {
+		t.Fatalf("callback with forged state = %v, want ErrOIDCInvalidState", err)
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/wuwen/hello-go/internal/pkg/breach"
+)
+
+// bcrypt 只使用密码的前 72 个字节
+const maxPasswordBytes = 72
+
+// PasswordRules 密码需要满足的规则
+type PasswordRules struct {
+	MinLength     int
+	RequireLower  bool
+	RequireUpper  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	CheckBreached bool // 检查是否出现在泄露密码列表中
+}
+
+// PasswordPolicy 校验新密码，违反的规则以 password 字段的错误返回
+type PasswordPolicy struct {
+	rules    PasswordRules
+	breached breach.Source
+}
+
+func NewPasswordPolicy(rules PasswordRules, breached breach.Source) *PasswordPolicy {
+	if rules.MinLength <= 0 {
+		rules.MinLength = 8
+	}
+	return &PasswordPolicy{rules: rules, breached: breached}
+}
+
+// Validate 校验密码，username 和 email 为账号当前或将要使用的值，密码不能与它们相同
+func (p *PasswordPolicy) Validate(password, username, email string) error {
+	verr := &ValidationError{}
+
+	if n := len([]rune(password)); n < p.rules.MinLength {
+		verr.Add("password", "min_length", fmt.Sprintf("password must be at least %d characters", p.rules.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		verr.Add("password", "max_length", fmt.Sprintf("password must be at most %d bytes", maxPasswordBytes))
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r):
+			symbol = true
+		}
+	}
+	if p.rules.RequireLower && !lower {
+		verr.Add("password", "lowercase", "password must contain a lowercase letter")
+	}
+	if p.rules.RequireUpper && !upper {
+		verr.Add("password", "uppercase", "password must contain an uppercase letter")
+	}
+	if p.rules.RequireDigit && !digit {
+		verr.Add("password", "digit", "password must contain a digit")
+	}
+	if p.rules.RequireSymbol && !symbol {
+		verr.Add("password", "symbol", "password must contain a symbol")
+	}
+
+	if sameAsAccount(password, username, email) {
+		verr.Add("password", "not_account", "password must not be the same as the username or email")
+	}
+
+	if p.rules.CheckBreached && p.breached != nil {
+		breached, err := breach.Breached(p.breached, password)
+		if err != nil {
+			// 列表不可用时不阻止修改密码
+			log.Printf("password policy: breached password check failed: %v", err)
+		} else if breached {
+			verr.Add("password", "breached", "password appears in a list of breached passwords, choose a different one")
+		}
+	}
+
+	return verr.Err()
+}
+
+func sameAsAccount(password, username, email string) bool {
+	candidates := []string{username, email}
+	if i := strings.LastIndex(email, "@"); i > 0 {
+		candidates = append(candidates, email[:i])
+	}
+	for _, candidate := range candidates {
+		if candidate != "" && strings.EqualFold(password, candidate) {
+			return true
+		}
+	}
+	return false
+}
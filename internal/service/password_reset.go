@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/mail"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+const (
+	passwordResetPrefix        = "pwr_"
+	defaultPasswordResetExpire = time.Hour
+	passwordResetInterval      = time.Minute // 同一用户两次申请的最小间隔
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// resetPasswordData 找回密码邮件模板 reset_password 的数据
+type resetPasswordData struct {
+	SiteName string
+	Username string
+	Token    string
+	Expire   time.Duration
+}
+
+// PasswordResetService 找回密码：向用户邮箱发送一次性的重置链接
+type PasswordResetService struct {
+	repo     *repository.PasswordResetRepository
+	userRepo *repository.UserRepository
+	mailer   *mail.Mailer
+	expire   time.Duration
+	siteName string
+}
+
+func NewPasswordResetService(repo *repository.PasswordResetRepository, userRepo *repository.UserRepository,
+	mailer *mail.Mailer, expire time.Duration, siteName string) *PasswordResetService {
+	if expire <= 0 {
+		expire = defaultPasswordResetExpire
+	}
+	return &PasswordResetService{
+		repo:     repo,
+		userRepo: userRepo,
+		mailer:   mailer,
+		expire:   expire,
+		siteName: siteName,
+	}
+}
+
+// Request 为邮箱对应的用户签发重置 token 并发送邮件，之前未使用的 token 随之失效。
+// 邮箱不存在、用户已封禁或申请过于频繁时静默忽略，调用方对外的响应始终一致
+func (s *PasswordResetService) Request(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil || user.Status == model.UserStatusBanned {
+		return nil
+	}
+	now := time.Now()
+	if latest, err := s.repo.LatestByUser(user.ID); err == nil &&
+		latest.UsedAt == nil && now.Sub(latest.CreatedAt) < passwordResetInterval {
+		return nil
+	}
+
+	if err := s.repo.DeleteUnusedByUser(user.ID); err != nil {
+		return err
+	}
+	token := newOpaqueToken(passwordResetPrefix)
+	if err := s.repo.Create(&model.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(s.expire),
+	}); err != nil {
+		return err
+	}
+
+	data := &resetPasswordData{
+		SiteName: s.siteName,
+		Username: user.Username,
+		Token:    token,
+		Expire:   s.expire,
+	}
+	if err := s.mailer.Send(context.Background(), user.Email, user.Locale, "reset_password", data); err != nil {
+		log.Printf("password reset: failed to send email to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// Consume 校验并作废重置 token，返回对应的用户。check 在作废前调用，
+// 返回错误时 token 保持可用，例如新密码不符合要求。并发使用同一 token 时只有一个请求成功
+func (s *PasswordResetService) Consume(token string, check func(user *model.User) error) (*model.User, error) {
+	if !strings.HasPrefix(token, passwordResetPrefix) {
+		return nil, ErrInvalidResetToken
+	}
+	reset, err := s.repo.FindByHash(hashToken(token))
+	if err != nil {
+		return nil, ErrInvalidResetToken
+	}
+
+	now := time.Now()
+	if reset.UsedAt != nil || now.After(reset.ExpiresAt) {
+		return nil, ErrInvalidResetToken
+	}
+	user, err := s.userRepo.FindById(reset.UserID)
+	if err != nil || user.Status == model.UserStatusBanned {
+		return nil, ErrInvalidResetToken
+	}
+	if err := check(user); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.MarkUsed(reset.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidResetToken
+	}
+	// 同一用户其余未使用的链接一并失效
+	if err := s.repo.DeleteUnusedByUser(user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Run 定期清理过期的重置 token，随服务启动
+func (s *PasswordResetService) Run(ctx context.Context) {
+	ticker := time.NewTicker(tokenPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.repo.DeleteExpired(time.Now()); err != nil {
+				log.Printf("password reset: failed to prune expired tokens: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+// 最近使用时间的记录精度，同一 token 在这段时间内的多次使用只写一次库
+const personalTokenTouchInterval = time.Minute
+
+var (
+	ErrPersonalTokenNotFound = errors.New("personal access token not found")
+	ErrSessionRequired       = errors.New("this action requires a login session, personal access tokens are not accepted")
+)
+
+type CreatePersonalTokenRequest struct {
+	Name      string       `json:"name" binding:"required,max=100" example:"ci-publish"`
+	Scopes    []PolicyRule `json:"scopes" binding:"required,min=1,dive"`                      // 必须是当前用户拥有的权限
+	ExpiresIn int          `json:"expires_in" binding:"omitempty,min=1,max=365" example:"90"` // 有效期（天），不填表示不过期
+}
+
+// PersonalTokenCreated 新建的 token，明文只在创建时返回一次
+type PersonalTokenCreated struct {
+	*model.PersonalToken
+	Token string `json:"token" example:"pat_3f2a…"`
+}
+
+// PersonalTokenService 管理个人访问 token，并为 auth.Authenticate 校验以 pat_ 开头的 token
+type PersonalTokenService struct {
+	repo          *repository.PersonalTokenRepository
+	userRepo      *repository.UserRepository
+	policyService *PolicyService
+}
+
+func NewPersonalTokenService(repo *repository.PersonalTokenRepository, userRepo *repository.UserRepository,
+	policyService *PolicyService) *PersonalTokenService {
+	return &PersonalTokenService{
+		repo:          repo,
+		userRepo:      userRepo,
+		policyService: policyService,
+	}
+}
+
+// Create 为用户创建 token，scopes 不能超出用户当前的权限
+func (s *PersonalTokenService) Create(userID uint, req *CreatePersonalTokenRequest) (*PersonalTokenCreated, error) {
+	user, err := s.userRepo.FindById(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	scopes, err := s.scopesFor(user.Username, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	token := newOpaqueToken(auth.PersonalTokenPrefix)
+	pat := &model.PersonalToken{
+		UserID:    userID,
+		Name:      req.Name,
+		TokenHash: hashToken(token),
+		Hint:      token[:len(auth.PersonalTokenPrefix)+4] + "…" + token[len(token)-4:],
+		Scopes:    scopes,
+	}
+	if req.ExpiresIn > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresIn)
+		pat.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.Create(pat); err != nil {
+		return nil, err
+	}
+
+	return &PersonalTokenCreated{PersonalToken: pat, Token: token}, nil
+}
+
+// scopesFor 校验请求的权限都在用户的权限之内，并转换为 "METHOD path" 格式
+func (s *PersonalTokenService) scopesFor(username string, rules []PolicyRule) (model.StringList, error) {
+	permissions, err := s.policyService.GetPermissionsForUser(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user permissions: %v", err)
+	}
+	granted := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		if len(p) >= 3 {
+			granted[strings.ToUpper(p[2])+" "+p[1]] = true
+		}
+	}
+
+	verr := &ValidationError{}
+	scopes := make(model.StringList, 0, len(rules))
+	seen := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		scope := strings.ToUpper(rule.Method) + " " + rule.Path
+		if !granted[scope] {
+			verr.Add(fmt.Sprintf("scopes[%d]", i), "not_granted", fmt.Sprintf("%s is not one of your permissions", scope))
+			continue
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if err := verr.Err(); err != nil {
+		return nil, err
+	}
+	return scopes, nil
+}
+
+// List 列出用户的全部 token，包括已过期的
+func (s *PersonalTokenService) List(userID uint) ([]model.PersonalToken, error) {
+	return s.repo.ListByUser(userID)
+}
+
+// Revoke 删除用户的 token，立即失效
+func (s *PersonalTokenService) Revoke(userID, id uint) error {
+	deleted, err := s.repo.Delete(userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrPersonalTokenNotFound
+	}
+	return nil
+}
+
+// RevokeUser 删除用户的全部 token，用于重置密码或撤销全部会话
+func (s *PersonalTokenService) RevokeUser(userID uint) error {
+	return s.repo.DeleteByUser(userID)
+}
+
+// AuthenticatePersonalToken 实现 auth.PersonalTokenStore
+func (s *PersonalTokenService) AuthenticatePersonalToken(token string) (*auth.Claims, error) {
+	pat, err := s.repo.FindByHash(hashToken(token))
+	if err != nil {
+		return nil, auth.ErrInvalidToken
+	}
+	now := time.Now()
+	if pat.Expired(now) {
+		return nil, auth.ErrInvalidToken
+	}
+
+	// 被封禁或尚未激活的用户不能继续使用已有的 token
+	user, err := s.userRepo.FindById(pat.UserID)
+	if err != nil || user.Status != model.UserStatusActive {
+		return nil, auth.ErrInvalidToken
+	}
+
+	if err := s.repo.Touch(pat.ID, now, personalTokenTouchInterval); err != nil {
+		return nil, err
+	}
+
+	claims := &auth.Claims{
+		UserID:          pat.UserID,
+		IssuedAt:        pat.CreatedAt,
+		PersonalTokenID: pat.ID,
+		Scopes:          pat.Scopes,
+	}
+	if pat.ExpiresAt != nil {
+		claims.ExpiresAt = *pat.ExpiresAt
+	}
+	return claims, nil
+}
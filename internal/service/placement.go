@@ -0,0 +1,172 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+var (
+	ErrInvalidPlacementName    = errors.New("placement name must be 1-50 lowercase letters, digits or hyphens")
+	ErrPlacementArticleInvalid = errors.New("placement articles must exist and must not repeat")
+	ErrPlacementExpiry         = errors.New("expires_at must be in the future")
+)
+
+var placementNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+type PlacementService struct {
+	repo        *repository.PlacementRepository
+	articleRepo *repository.ArticleRepository
+}
+
+func NewPlacementService(repo *repository.PlacementRepository, articleRepo *repository.ArticleRepository) *PlacementService {
+	return &PlacementService{
+		repo:        repo,
+		articleRepo: articleRepo,
+	}
+}
+
+type PlacementItemRequest struct {
+	ArticleID uint       `json:"article_id" binding:"required" example:"1"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2024-07-27T10:00:00Z"`
+}
+
+// SetPlacementRequest 按顺序给出展示位上的文章，整体替换原有内容
+type SetPlacementRequest struct {
+	Articles []PlacementItemRequest `json:"articles"`
+}
+
+// PlacementEntry 展示位管理接口中的一项，文章已删除时 Article 为空
+type PlacementEntry struct {
+	*model.PlacementItem
+	Article *ArticleRef `json:"article,omitempty"`
+}
+
+type Placement struct {
+	Name    string            `json:"name" example:"home-hero"`
+	Entries []*PlacementEntry `json:"entries"`
+}
+
+// List 返回所有展示位及其原始配置，包括已过期和未发布的文章
+func (s *PlacementService) List() ([]*Placement, error) {
+	items, err := s.repo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ArticleID)
+	}
+	articles, err := s.articleRepo.ListByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	refs := make(map[uint]*ArticleRef, len(articles))
+	for _, article := range articles {
+		refs[article.ID] = &ArticleRef{ID: article.ID, Title: article.Title}
+	}
+
+	placements := make([]*Placement, 0)
+	for _, item := range items {
+		if len(placements) == 0 || placements[len(placements)-1].Name != item.Placement {
+			placements = append(placements, &Placement{Name: item.Placement})
+		}
+		current := placements[len(placements)-1]
+		current.Entries = append(current.Entries, &PlacementEntry{
+			PlacementItem: item,
+			Article:       refs[item.ArticleID],
+		})
+	}
+	return placements, nil
+}
+
+// Set 替换展示位上的文章，列表为空时清空展示位
+func (s *PlacementService) Set(name string, req *SetPlacementRequest) error {
+	if !placementNamePattern.MatchString(name) {
+		return ErrInvalidPlacementName
+	}
+
+	now := time.Now()
+	ids := make([]uint, 0, len(req.Articles))
+	seen := make(map[uint]bool, len(req.Articles))
+	items := make([]*model.PlacementItem, 0, len(req.Articles))
+	for i, entry := range req.Articles {
+		if seen[entry.ArticleID] {
+			return ErrPlacementArticleInvalid
+		}
+		if entry.ExpiresAt != nil && !entry.ExpiresAt.After(now) {
+			return ErrPlacementExpiry
+		}
+		seen[entry.ArticleID] = true
+		ids = append(ids, entry.ArticleID)
+		items = append(items, &model.PlacementItem{
+			Placement: name,
+			Position:  i + 1,
+			ArticleID: entry.ArticleID,
+			ExpiresAt: entry.ExpiresAt,
+		})
+	}
+
+	articles, err := s.articleRepo.ListByIDs(ids)
+	if err != nil {
+		return err
+	}
+	if len(articles) != len(ids) {
+		return ErrPlacementArticleInvalid
+	}
+
+	return s.repo.Replace(name, items)
+}
+
+func (s *PlacementService) Delete(name string) error {
+	if !placementNamePattern.MatchString(name) {
+		return ErrInvalidPlacementName
+	}
+	return s.repo.Delete(name)
+}
+
+// Resolve 按顺序返回展示位上访问者可见的已发布文章，
+// 过期、未发布、已删除或访问者无权查看的文章直接略过
+func (s *PlacementService) Resolve(name string, viewer *Viewer) ([]*model.Article, error) {
+	if !placementNamePattern.MatchString(name) {
+		return nil, ErrInvalidPlacementName
+	}
+
+	items, err := s.repo.ListActive(name, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ArticleID)
+	}
+	articles, err := s.articleRepo.ListByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make(map[string]bool)
+	for _, visibility := range listedVisibilities(viewer) {
+		visible[visibility] = true
+	}
+	byID := make(map[uint]*model.Article, len(articles))
+	for _, article := range articles {
+		if article.Status == model.ArticleStatusPublished && visible[article.Visibility] {
+			byID[article.ID] = article
+		}
+	}
+
+	resolved := make([]*model.Article, 0, len(byID))
+	for _, item := range items {
+		if article, ok := byID[item.ArticleID]; ok {
+			redactListed(article)
+			resolved = append(resolved, article)
+		}
+	}
+	return resolved, nil
+}
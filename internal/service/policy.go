@@ -57,6 +57,20 @@ func (s *PolicyService) RemovePolicy(role, path, method string) error {
 	return nil
 }
 
+// RemovePoliciesForPath 移除所有角色在指定路径上的权限策略
+func (s *PolicyService) RemovePoliciesForPath(path string) error {
+	_, err := s.enforcer.RemoveFilteredPolicy(1, path)
+	if err != nil {
+		return fmt.Errorf("failed to remove policies for path: %v", err)
+	}
+	return nil
+}
+
+// HasRoleForUser 判断用户是否拥有指定角色
+func (s *PolicyService) HasRoleForUser(username, role string) (bool, error) {
+	return s.enforcer.HasRoleForUser(UserPrefix+username, RolePrefix+role)
+}
+
 // GetRolesForUser 获取用户的所有角色
 func (s *PolicyService) GetRolesForUser(username string) ([]string, error) {
 	return s.enforcer.GetRolesForUser(username)
@@ -67,6 +81,11 @@ func (s *PolicyService) GetPermissionsForRole(role string) ([][]string, error) {
 	return s.enforcer.GetPermissionsForUser(RolePrefix + role)
 }
 
+// GetPermissionsForUser 获取用户通过角色获得的全部权限，每项为 [主体, 路径, 方法]
+func (s *PolicyService) GetPermissionsForUser(username string) ([][]string, error) {
+	return s.enforcer.GetImplicitPermissionsForUser(UserPrefix + username)
+}
+
 // UpdateRoleName 更新角色名称
 func (s *PolicyService) UpdateRoleName(oldName, newName string) error {
 	// 更新策略规则中的角色名
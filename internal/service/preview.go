@@ -0,0 +1,218 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+const (
+	defaultPreviewExpire = 72 * time.Hour
+	maxPreviewExpire     = 30 * 24 * time.Hour
+)
+
+var (
+	ErrPreviewLinkNotFound = errors.New("preview link not found")
+	ErrInvalidPreviewToken = errors.New("invalid or expired preview link")
+	ErrInvalidExpiresIn    = errors.New("expires_in must be between 1 and 720 hours")
+)
+
+type PreviewService struct {
+	repo          *repository.PreviewRepository
+	articleRepo   *repository.ArticleRepository
+	collaborators *CollaboratorService
+	secret        []byte
+	baseURL       string
+}
+
+func NewPreviewService(repo *repository.PreviewRepository, articleRepo *repository.ArticleRepository,
+	collaborators *CollaboratorService, secret, baseURL string) *PreviewService {
+	return &PreviewService{
+		repo:          repo,
+		articleRepo:   articleRepo,
+		collaborators: collaborators,
+		secret:        []byte(secret),
+		baseURL:       strings.TrimRight(baseURL, "/"),
+	}
+}
+
+type CreatePreviewLinkRequest struct {
+	ExpiresIn int    `json:"expires_in" example:"72"` // 有效期（小时），默认 72
+	Note      string `json:"note" binding:"max=200" example:"外部审稿"`
+}
+
+type PreviewLinkResponse struct {
+	*model.PreviewLink
+	Token string `json:"token"`
+	URL   string `json:"url" example:"http://localhost:8080/api/v1/preview/xxx"`
+}
+
+// CreateLink 为文章生成一个签名的预览链接，需要编辑权限
+func (s *PreviewService) CreateLink(articleID, userID uint, req *CreatePreviewLinkRequest) (*PreviewLinkResponse, error) {
+	if _, err := s.articleRepo.GetByID(articleID); err != nil {
+		return nil, ErrArticleNotFound
+	}
+	if err := s.collaborators.Authorize(userID, articleID, model.GrantRoleEditor); err != nil {
+		return nil, err
+	}
+
+	expire := defaultPreviewExpire
+	if req.ExpiresIn != 0 {
+		expire = time.Duration(req.ExpiresIn) * time.Hour
+		if expire < time.Hour || expire > maxPreviewExpire {
+			return nil, ErrInvalidExpiresIn
+		}
+	}
+
+	link := &model.PreviewLink{
+		ArticleID: articleID,
+		CreatedBy: userID,
+		Note:      req.Note,
+		ExpiresAt: time.Now().Add(expire),
+	}
+	if err := s.repo.Create(link); err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(link), nil
+}
+
+// ListLinks 列出文章的所有预览链接，协作者可以查看
+func (s *PreviewService) ListLinks(articleID, userID uint) ([]*PreviewLinkResponse, error) {
+	if _, err := s.articleRepo.GetByID(articleID); err != nil {
+		return nil, ErrArticleNotFound
+	}
+	if err := s.collaborators.Authorize(userID, articleID, model.GrantRoleViewer); err != nil {
+		return nil, err
+	}
+
+	links, err := s.repo.ListByArticle(articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*PreviewLinkResponse, 0, len(links))
+	for _, link := range links {
+		items = append(items, s.toResponse(link))
+	}
+	return items, nil
+}
+
+// RevokeLink 撤销预览链接，需要编辑权限
+func (s *PreviewService) RevokeLink(articleID, linkID, userID uint) error {
+	link, err := s.repo.GetByID(linkID)
+	if err != nil || link.ArticleID != articleID {
+		return ErrPreviewLinkNotFound
+	}
+	if err := s.collaborators.Authorize(userID, articleID, model.GrantRoleEditor); err != nil {
+		return err
+	}
+	return s.repo.Revoke(link.ID, time.Now())
+}
+
+// ListAccesses 查看预览链接的访问记录，协作者可以查看
+func (s *PreviewService) ListAccesses(articleID, linkID, userID uint, page, pageSize int) ([]*model.PreviewAccess, int64, error) {
+	link, err := s.repo.GetByID(linkID)
+	if err != nil || link.ArticleID != articleID {
+		return nil, 0, ErrPreviewLinkNotFound
+	}
+	if err := s.collaborators.Authorize(userID, articleID, model.GrantRoleViewer); err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	return s.repo.ListAccesses(link.ID, page, pageSize)
+}
+
+// Resolve 校验预览 token 并返回文章，无论文章处于何种状态
+func (s *PreviewService) Resolve(token, ip, userAgent string) (*model.Article, error) {
+	linkID, expiresAt, err := s.parseToken(token)
+	if err != nil {
+		return nil, ErrInvalidPreviewToken
+	}
+
+	now := time.Now()
+	if now.After(expiresAt) {
+		return nil, ErrInvalidPreviewToken
+	}
+
+	link, err := s.repo.GetByID(linkID)
+	if err != nil || !link.Active(now) || link.ExpiresAt.Unix() != expiresAt.Unix() {
+		return nil, ErrInvalidPreviewToken
+	}
+
+	article, err := s.articleRepo.GetByID(link.ArticleID)
+	if err != nil {
+		return nil, ErrArticleNotFound
+	}
+
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	if err := s.repo.RecordAccess(&model.PreviewAccess{
+		CreatedAt:     now,
+		PreviewLinkID: link.ID,
+		IP:            ip,
+		UserAgent:     userAgent,
+	}); err != nil {
+		return nil, err
+	}
+
+	return article, nil
+}
+
+func (s *PreviewService) toResponse(link *model.PreviewLink) *PreviewLinkResponse {
+	token := s.signToken(link.ID, link.ExpiresAt)
+	return &PreviewLinkResponse{
+		PreviewLink: link,
+		Token:       token,
+		URL:         s.baseURL + "/api/v1/preview/" + token,
+	}
+}
+
+// signToken 生成 "<id>.<过期时间>.<签名>" 格式的 token
+func (s *PreviewService) signToken(linkID uint, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d.%d", linkID, expiresAt.Unix())
+	return payload + "." + s.sign(payload)
+}
+
+func (s *PreviewService) parseToken(token string) (uint, time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, time.Time{}, ErrInvalidPreviewToken
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.sign(payload))) {
+		return 0, time.Time{}, ErrInvalidPreviewToken
+	}
+
+	linkID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, time.Time{}, ErrInvalidPreviewToken
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, ErrInvalidPreviewToken
+	}
+
+	return uint(linkID), time.Unix(expiresAt, 0), nil
+}
+
+func (s *PreviewService) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("preview:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
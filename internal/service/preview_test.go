@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+func TestPreviewLinksRequireArticlePermission(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	articles := repository.NewArticleRepository(db)
+	collaborators := NewCollaboratorService(repository.NewArticleGrantRepository(db), articles,
+		repository.NewUserRepository(db), policy)
+	svc := NewPreviewService(repository.NewPreviewRepository(db), articles, collaborators, "secret", "http://localhost")
+
+	alice := createTestUser(t, db, policy, "alice", "user")
+	bob := createTestUser(t, db, policy, "bob", "user")
+	mallory := createTestUser(t, db, policy, "mallory", "user")
+	article := &model.Article{Title: "draft", Content: "x", Status: model.ArticleStatusDraft}
+	if err := articles.Create(article); err != nil {
+		t.Fatal(err)
+	}
+	if err := collaborators.GrantOwner(article.ID, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := collaborators.GrantViewer(article.ID, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := svc.CreateLink(article.ID, alice.ID, &CreatePreviewLinkRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateLink(article.ID, mallory.ID, &CreatePreviewLinkRequest{}); err != ErrArticleForbidden {
+		t.Fatalf("stranger creating link = %v, want ErrArticleForbidden", err)
+	}
+	if _, err := svc.CreateLink(article.ID, bob.ID, &CreatePreviewLinkRequest{}); err != ErrArticleForbidden {
+		t.Fatalf("viewer creating link = %v, want ErrArticleForbidden", err)
+	}
+
+	if _, err := svc.ListLinks(article.ID, bob.ID); err != nil {
+		t.Fatalf("viewer listing links: %v", err)
+	}
+	if _, err := svc.ListLinks(article.ID, mallory.ID); err != ErrArticleForbidden {
+		t.Fatalf("stranger listing links = %v, want ErrArticleForbidden", err)
+	}
+	if _, _, err := svc.ListAccesses(article.ID, link.ID, mallory.ID, 1, 20); err != ErrArticleForbidden {
+		t.Fatalf("stranger listing accesses = %v, want ErrArticleForbidden", err)
+	}
+	if err := svc.RevokeLink(article.ID, link.ID, bob.ID); err != ErrArticleForbidden {
+		t.Fatalf("viewer revoking link = %v, want ErrArticleForbidden", err)
+	}
+	if err := svc.RevokeLink(article.ID, link.ID, alice.ID); err != nil {
+		t.Fatalf("owner revoking link: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+// 审核人提交的结论
+const (
+	ReviewActionApprove        = "approve"
+	ReviewActionRequestChanges = "request_changes"
+)
+
+var (
+	ErrInvalidReviewers      = errors.New("reviewer_ids must list existing users other than the submitter")
+	ErrReviewerRoleRequired  = errors.New("reviewers must have a reviewer role")
+	ErrNotEnoughReviewers    = errors.New("not enough reviewers to reach the required number of approvals")
+	ErrInvalidReviewDecision = errors.New("decision must be one of approve, request_changes")
+	ErrReviewNotFound        = errors.New("you are not a reviewer of this article")
+	ErrArticleNotInReview    = errors.New("article is not in review")
+	ErrArticleNotApproved    = errors.New("article needs more approvals before it can be published")
+)
+
+type SubmitArticleRequest struct {
+	ReviewerIDs []uint     `json:"reviewer_ids" binding:"required" example:"2,3"`
+	DueAt       *time.Time `json:"due_at" example:"2024-07-22T10:00:00Z"` // 审核截止时间，可选
+}
+
+type ReviewDecisionRequest struct {
+	Decision string `json:"decision" binding:"required" example:"approve"` // approve、request_changes
+	Comment  string `json:"comment" binding:"max=2000" example:"第二段需要补充数据来源"`
+}
+
+type Review struct {
+	*model.ArticleReview
+	Reviewer string `json:"reviewer" example:"testuser"`
+}
+
+type PendingReview struct {
+	*model.ArticleReview
+	ArticleTitle string `json:"article_title" example:"文章标题"`
+}
+
+// ReviewEvent 审核结论的 webhook 消息体
+type ReviewEvent struct {
+	Article *model.Article       `json:"article"`
+	Review  *model.ArticleReview `json:"review"`
+}
+
+// ReviewService 管理文章的审核人指派和审核结论，发布前需要足够数量的通过
+type ReviewService struct {
+	repo          *repository.ReviewRepository
+	articleRepo   *repository.ArticleRepository
+	userRepo      *repository.UserRepository
+	collaborators *CollaboratorService
+	webhooks      *WebhookService
+	policyService *PolicyService
+	minApprovals  int
+	reviewerRoles []string
+}
+
+func NewReviewService(repo *repository.ReviewRepository, articleRepo *repository.ArticleRepository,
+	userRepo *repository.UserRepository, collaborators *CollaboratorService, webhooks *WebhookService,
+	policyService *PolicyService, minApprovals int, reviewerRoles []string) *ReviewService {
+	return &ReviewService{
+		repo:          repo,
+		articleRepo:   articleRepo,
+		userRepo:      userRepo,
+		collaborators: collaborators,
+		webhooks:      webhooks,
+		policyService: policyService,
+		minApprovals:  minApprovals,
+		reviewerRoles: reviewerRoles,
+	}
+}
+
+// Assign 为提交的文章指派审核人，替换上一轮的审核记录。
+// 审核人需要拥有配置的审核角色之一，并会获得文章的查看权限
+func (s *ReviewService) Assign(articleID, submitterID uint, req *SubmitArticleRequest) error {
+	seen := make(map[uint]bool, len(req.ReviewerIDs))
+	reviews := make([]*model.ArticleReview, 0, len(req.ReviewerIDs))
+	for _, reviewerID := range req.ReviewerIDs {
+		if seen[reviewerID] {
+			continue
+		}
+		seen[reviewerID] = true
+		if reviewerID == submitterID {
+			return ErrInvalidReviewers
+		}
+		reviewer, err := s.userRepo.FindById(reviewerID)
+		if err != nil {
+			return ErrInvalidReviewers
+		}
+		ok, err := s.canReview(reviewer.Username)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrReviewerRoleRequired
+		}
+		reviews = append(reviews, &model.ArticleReview{
+			ArticleID:  articleID,
+			ReviewerID: reviewerID,
+			AssignedBy: submitterID,
+			Decision:   model.ReviewDecisionPending,
+			DueAt:      req.DueAt,
+		})
+	}
+	if len(reviews) == 0 {
+		return ErrInvalidReviewers
+	}
+	if len(reviews) < s.minApprovals {
+		return ErrNotEnoughReviewers
+	}
+
+	if err := s.repo.Replace(articleID, reviews); err != nil {
+		return err
+	}
+	for _, review := range reviews {
+		if err := s.collaborators.GrantViewer(articleID, review.ReviewerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canReview 判断用户是否拥有任一审核角色
+func (s *ReviewService) canReview(username string) (bool, error) {
+	for _, role := range s.reviewerRoles {
+		ok, err := s.policyService.HasRoleForUser(username, role)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// Decide 记录审核人的结论。要求修改时文章退回草稿，作者修改后需要重新提交
+func (s *ReviewService) Decide(articleID, reviewerID uint, req *ReviewDecisionRequest) (*model.ArticleReview, error) {
+	var decision string
+	switch req.Decision {
+	case ReviewActionApprove:
+		decision = model.ReviewDecisionApproved
+	case ReviewActionRequestChanges:
+		decision = model.ReviewDecisionChangesRequested
+	default:
+		return nil, ErrInvalidReviewDecision
+	}
+
+	article, err := s.articleRepo.GetByID(articleID)
+	if err != nil {
+		return nil, ErrArticleNotFound
+	}
+	review, err := s.repo.Find(articleID, reviewerID)
+	if err != nil {
+		return nil, ErrReviewNotFound
+	}
+	if article.Status != model.ArticleStatusInReview {
+		return nil, ErrArticleNotInReview
+	}
+
+	now := time.Now()
+	review.Decision = decision
+	review.Comment = req.Comment
+	review.DecidedAt = &now
+	if err := s.repo.Update(review); err != nil {
+		return nil, err
+	}
+
+	if decision == model.ReviewDecisionChangesRequested {
+		article.Status = model.ArticleStatusDraft
+		if err := s.articleRepo.Update(article); err != nil {
+			return nil, err
+		}
+	}
+
+	s.webhooks.Publish(EventArticleReviewed, &ReviewEvent{Article: article, Review: review})
+	return review, nil
+}
+
+// List 列出文章当前一轮的审核记录，需要 viewer 及以上权限
+func (s *ReviewService) List(articleID, userID uint) ([]*Review, error) {
+	if _, err := s.articleRepo.GetByID(articleID); err != nil {
+		return nil, ErrArticleNotFound
+	}
+	if err := s.collaborators.Authorize(userID, articleID, model.GrantRoleViewer); err != nil {
+		return nil, err
+	}
+
+	reviews, err := s.repo.ListByArticle(articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Review, 0, len(reviews))
+	for _, review := range reviews {
+		item := &Review{ArticleReview: review}
+		if user, err := s.userRepo.FindById(review.ReviewerID); err == nil {
+			item.Reviewer = user.Username
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Mine 列出等待当前用户给出结论的审核，截止时间早的在前
+func (s *ReviewService) Mine(userID uint) ([]*PendingReview, error) {
+	reviews, err := s.repo.ListPendingByReviewer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(reviews))
+	for _, review := range reviews {
+		ids = append(ids, review.ArticleID)
+	}
+	articles, err := s.articleRepo.ListByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	titles := make(map[uint]string, len(articles))
+	for _, article := range articles {
+		titles[article.ID] = article.Title
+	}
+
+	items := make([]*PendingReview, 0, len(reviews))
+	for _, review := range reviews {
+		items = append(items, &PendingReview{
+			ArticleReview: review,
+			ArticleTitle:  titles[review.ArticleID],
+		})
+	}
+	return items, nil
+}
+
+// CheckApproved 判断文章能否发布：未要求审核时总是允许，
+// 否则文章需处于审核中且通过数不少于配置的最小值
+func (s *ReviewService) CheckApproved(article *model.Article) error {
+	if s.minApprovals <= 0 {
+		return nil
+	}
+	if article.Status != model.ArticleStatusInReview {
+		return ErrArticleNotApproved
+	}
+	approvals, err := s.repo.CountByDecision(article.ID, model.ReviewDecisionApproved)
+	if err != nil {
+		return err
+	}
+	if approvals < int64(s.minApprovals) {
+		return ErrArticleNotApproved
+	}
+	return nil
+}
+
+// RemoveArticle 文章删除时清理审核记录
+func (s *ReviewService) RemoveArticle(articleID uint) error {
+	return s.repo.DeleteByArticle(articleID)
+}
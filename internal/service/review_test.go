@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/lint"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+func newTestArticleService(t *testing.T, db *gorm.DB, policy *PolicyService) *ArticleService {
+	t.Helper()
+	articles := repository.NewArticleRepository(db)
+	users := repository.NewUserRepository(db)
+	webhooks := NewWebhookService(repository.NewWebhookRepository(db))
+	collaborators := NewCollaboratorService(repository.NewArticleGrantRepository(db), articles, users, policy)
+	reviews := NewReviewService(repository.NewReviewRepository(db), articles, users, collaborators, webhooks,
+		policy, 1, []string{"reviewer", "editor", "admin"})
+	linter, err := lint.NewEngine(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewArticleService(articles, NewSeriesService(repository.NewSeriesRepository(db), articles, collaborators),
+		collaborators, reviews, NewSEOService("test", "http://localhost"), linter, webhooks)
+}
+
+func TestReviewRequiresReviewerRole(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	svc := newTestArticleService(t, db, policy)
+
+	author := createTestUser(t, db, policy, "author", "user")
+	plain := createTestUser(t, db, policy, "plain", "user")
+	reviewer := createTestUser(t, db, policy, "reviewer", "user", "reviewer")
+
+	article, err := svc.Create(author.ID, &CreateArticleRequest{Title: "draft", Content: "body"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = svc.Submit(author.ID, article.ID, &SubmitArticleRequest{ReviewerIDs: []uint{plain.ID}})
+	if err != ErrReviewerRoleRequired {
+		t.Fatalf("submit to user without reviewer role = %v, want ErrReviewerRoleRequired", err)
+	}
+	if _, err := svc.Submit(author.ID, article.ID, &SubmitArticleRequest{ReviewerIDs: []uint{reviewer.ID}}); err != nil {
+		t.Fatalf("submit to reviewer: %v", err)
+	}
+}
+
+func TestReviewApprovalDoesNotCoverLaterEdits(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	svc := newTestArticleService(t, db, policy)
+
+	author := createTestUser(t, db, policy, "author", "user")
+	reviewer := createTestUser(t, db, policy, "reviewer", "user", "editor")
+
+	article, err := svc.Create(author.ID, &CreateArticleRequest{Title: "draft", Content: "reviewed body"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Submit(author.ID, article.ID, &SubmitArticleRequest{ReviewerIDs: []uint{reviewer.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.reviews.Decide(article.ID, reviewer.ID, &ReviewDecisionRequest{Decision: ReviewActionApprove}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = svc.Update(author.ID, article.ID, &UpdateArticleRequest{Content: "unreviewed body"})
+	if err != ErrArticleInReview {
+		t.Fatalf("editing approved article = %v, want ErrArticleInReview", err)
+	}
+	published, err := svc.Update(author.ID, article.ID, &UpdateArticleRequest{Status: model.ArticleStatusPublished})
+	if err != nil {
+		t.Fatalf("publishing approved article: %v", err)
+	}
+	if published.Content != "reviewed body" {
+		t.Fatalf("published content = %q, want the reviewed content", published.Content)
+	}
+}
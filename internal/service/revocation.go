@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+// 未撤销的查询结果在缓存中保留的时间，多实例部署时其它实例的撤销最多延迟这么久生效
+const revocationCacheTTL = 30 * time.Second
+
+type revocationEntry struct {
+	revoked   bool      // 单个 token 是否已撤销
+	cutoff    time.Time // 用户全部 token 的撤销时间，零值表示没有
+	checkedAt time.Time
+}
+
+// RevocationService 访问 token 的撤销记录，数据库表前有一层内存缓存。
+// 已撤销的单个 token 一直缓存到其过期，其余结果缓存 revocationCacheTTL
+type RevocationService struct {
+	repo         *repository.TokenRevocationRepository
+	accessExpire time.Duration
+
+	mu     sync.RWMutex
+	tokens map[string]*revocationEntry
+	users  map[uint]*revocationEntry
+}
+
+func NewRevocationService(repo *repository.TokenRevocationRepository, accessExpire time.Duration) *RevocationService {
+	return &RevocationService{
+		repo:         repo,
+		accessExpire: accessExpire,
+		tokens:       make(map[string]*revocationEntry),
+		users:        make(map[uint]*revocationEntry),
+	}
+}
+
+// IsRevoked 实现 auth.RevocationStore
+func (s *RevocationService) IsRevoked(claims *auth.Claims) (bool, error) {
+	now := time.Now()
+
+	cutoff, err := s.userCutoff(claims.UserID, now)
+	if err != nil {
+		return false, err
+	}
+	// 撤销时刻及之后签发的 token 有效，例如重置密码后立即登录
+	if !cutoff.IsZero() && claims.IssuedAt.Before(cutoff) {
+		return true, nil
+	}
+
+	if claims.ID == "" {
+		return false, nil
+	}
+	return s.tokenRevoked(claims.ID, now)
+}
+
+// RevokeToken 撤销单个访问 token，用于注销
+func (s *RevocationService) RevokeToken(claims *auth.Claims) error {
+	if err := s.repo.Create(&model.TokenRevocation{
+		JTI:       claims.ID,
+		UserID:    claims.UserID,
+		ExpiresAt: claims.ExpiresAt,
+	}); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tokens[claims.ID] = &revocationEntry{revoked: true, checkedAt: time.Now()}
+	s.mu.Unlock()
+	return nil
+}
+
+// RevokeUser 撤销用户此前签发的全部访问 token。
+// 撤销时间精确到毫秒，与 token 的 iat_ms 及数据库时间精度一致
+func (s *RevocationService) RevokeUser(userID uint) error {
+	now := time.Now().Truncate(time.Millisecond)
+	if err := s.repo.Create(&model.TokenRevocation{
+		UserID:       userID,
+		IssuedBefore: &now,
+		ExpiresAt:    now.Add(s.accessExpire + time.Second),
+	}); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.users[userID] = &revocationEntry{cutoff: now, checkedAt: time.Now()}
+	s.mu.Unlock()
+	return nil
+}
+
+// Run 定期清理过期的撤销记录及缓存，随服务启动
+func (s *RevocationService) Run(ctx context.Context) {
+	ticker := time.NewTicker(revocationCacheTTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.prune(time.Now())
+		}
+	}
+}
+
+func (s *RevocationService) prune(now time.Time) {
+	if _, err := s.repo.DeleteExpired(now); err != nil {
+		log.Printf("revocation: failed to prune expired revocations: %v", err)
+	}
+
+	// 已撤销的 token 在其过期前都可能被使用，保留到 accessExpire 之后
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for jti, entry := range s.tokens {
+		ttl := revocationCacheTTL
+		if entry.revoked {
+			ttl = s.accessExpire
+		}
+		if now.Sub(entry.checkedAt) > ttl {
+			delete(s.tokens, jti)
+		}
+	}
+	for userID, entry := range s.users {
+		if now.Sub(entry.checkedAt) > revocationCacheTTL {
+			delete(s.users, userID)
+		}
+	}
+}
+
+func (s *RevocationService) userCutoff(userID uint, now time.Time) (time.Time, error) {
+	s.mu.RLock()
+	entry, ok := s.users[userID]
+	s.mu.RUnlock()
+	if ok && now.Sub(entry.checkedAt) < revocationCacheTTL {
+		return entry.cutoff, nil
+	}
+
+	cutoff, err := s.repo.LatestCutoff(userID, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	s.mu.Lock()
+	s.users[userID] = &revocationEntry{cutoff: cutoff, checkedAt: now}
+	s.mu.Unlock()
+	return cutoff, nil
+}
+
+func (s *RevocationService) tokenRevoked(jti string, now time.Time) (bool, error) {
+	s.mu.RLock()
+	entry, ok := s.tokens[jti]
+	s.mu.RUnlock()
+	if ok && (entry.revoked || now.Sub(entry.checkedAt) < revocationCacheTTL) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := s.repo.ExistsJTI(jti, now)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	s.tokens[jti] = &revocationEntry{revoked: revoked, checkedAt: now}
+	s.mu.Unlock()
+	return revoked, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+func TestRevokeUserKeepsLoginInSameSecond(t *testing.T) {
+	db := newTestDB(t)
+	auth.Initialize("test-secret", time.Minute)
+	repo := repository.NewTokenRevocationRepository(db)
+	svc := NewRevocationService(repo, time.Minute)
+	auth.SetRevocationStore(svc)
+	t.Cleanup(func() { auth.SetRevocationStore(nil) })
+
+	old, err := auth.GenerateToken(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if err := svc.RevokeUser(1); err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := auth.GenerateToken(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 第二个实例没有缓存，从数据库读取撤销时间
+	for name, store := range map[string]*RevocationService{"cached": svc, "database": NewRevocationService(repo, time.Minute)} {
+		auth.SetRevocationStore(store)
+		if _, err := auth.Authenticate(old); err != auth.ErrTokenRevoked {
+			t.Errorf("%s: token issued before the revocation = %v, want ErrTokenRevoked", name, err)
+		}
+		if _, err := auth.Authenticate(fresh); err != nil {
+			t.Errorf("%s: login right after the revocation: %v", name, err)
+		}
+	}
+}
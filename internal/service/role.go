@@ -16,23 +16,27 @@ var (
 type RoleService struct {
 	roleRepo      *repository.RoleRepository
 	policyService *PolicyService
+	webhooks      *WebhookService
 }
 
-func NewRoleService(roleRepo *repository.RoleRepository, policyService *PolicyService) *RoleService {
+func NewRoleService(roleRepo *repository.RoleRepository, policyService *PolicyService, webhooks *WebhookService) *RoleService {
 	return &RoleService{
 		roleRepo:      roleRepo,
 		policyService: policyService,
+		webhooks:      webhooks,
 	}
 }
 
+// PolicyRule 接口权限，path 中的 :name 匹配一段路径，* 匹配之后的任意路径
 type PolicyRule struct {
-	Path   string `json:"path" binding:"required"`
+	Path   string `json:"path" binding:"required" example:"/api/v1/articles/:id"`
 	Method string `json:"method" binding:"required"`
 }
 
 type CreateRoleRequest struct {
-	Name     string       `json:"name" binding:"required"`
-	Policies []PolicyRule `json:"policies" binding:"required"`
+	Name             string       `json:"name" binding:"required"`
+	Policies         []PolicyRule `json:"policies" binding:"required"`
+	RequireTwoFactor bool         `json:"require_two_factor"` // 拥有该角色的用户必须启用两步验证
 }
 
 func (s *RoleService) Create(req *CreateRoleRequest) (*model.Role, error) {
@@ -44,7 +48,8 @@ func (s *RoleService) Create(req *CreateRoleRequest) (*model.Role, error) {
 
 	// 创建角色
 	role := &model.Role{
-		Name: req.Name,
+		Name:             req.Name,
+		RequireTwoFactor: req.RequireTwoFactor,
 	}
 
 	role, err = s.roleRepo.Create(role)
@@ -59,6 +64,7 @@ func (s *RoleService) Create(req *CreateRoleRequest) (*model.Role, error) {
 		}
 	}
 
+	s.webhooks.Publish(EventRoleCreated, role)
 	return role, nil
 }
 
@@ -71,8 +77,9 @@ func (s *RoleService) Get(id uint) (*model.Role, error) {
 }
 
 type UpdateRoleRequest struct {
-	Name     string       `json:"name" binding:"omitempty"`
-	Policies []PolicyRule `json:"policies" binding:"omitempty"`
+	Name             string       `json:"name" binding:"omitempty"`
+	Policies         []PolicyRule `json:"policies" binding:"omitempty"`
+	RequireTwoFactor *bool        `json:"require_two_factor"`
 }
 
 func (s *RoleService) Update(id uint, req *UpdateRoleRequest) (*model.Role, error) {
@@ -98,12 +105,23 @@ func (s *RoleService) Update(id uint, req *UpdateRoleRequest) (*model.Role, erro
 		}
 	}
 
+	if req.RequireTwoFactor != nil && *req.RequireTwoFactor != role.RequireTwoFactor {
+		role.RequireTwoFactor = *req.RequireTwoFactor
+		role.UpdatedAt = time.Now()
+
+		role, err = s.roleRepo.Update(role)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if req.Policies != nil {
 		if err := s.UpdatePermissions(id, req.Policies); err != nil {
 			return nil, err
 		}
 	}
 
+	s.webhooks.Publish(EventRoleUpdated, role)
 	return role, nil
 }
 
@@ -125,7 +143,12 @@ func (s *RoleService) Delete(id uint) error {
 		}
 	}
 
-	return s.roleRepo.Delete(id)
+	if err := s.roleRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.webhooks.Publish(EventRoleDeleted, role)
+	return nil
 }
 
 func (s *RoleService) UpdatePermissions(roleID uint, policies []PolicyRule) error {
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wuwen/hello-go/internal/model"
+)
+
+// 元数据长度限制，超出后搜索结果和分享卡片会被截断
+const (
+	maxExcerptLength         = 500
+	maxMetaTitleLength       = 70
+	maxMetaDescriptionLength = 160
+	maxURLLength             = 500
+)
+
+var robotsDirectives = map[string]bool{
+	"all":          true,
+	"none":         true,
+	"index":        true,
+	"noindex":      true,
+	"follow":       true,
+	"nofollow":     true,
+	"noarchive":    true,
+	"nosnippet":    true,
+	"noimageindex": true,
+}
+
+// SEOMeta 补全默认值后的文章元数据
+type SEOMeta struct {
+	Title        string `json:"title" example:"文章标题"`
+	Description  string `json:"description" example:"文章描述"`
+	CanonicalURL string `json:"canonical_url" example:"https://example.com/articles/1"`
+	OGImage      string `json:"og_image,omitempty" example:"https://example.com/uploads/cover.png"`
+	Robots       string `json:"robots" example:"index,follow"`
+}
+
+type SEOMetaTags struct {
+	*SEOMeta
+	HTML string `json:"html" example:"<title>文章标题</title>"`
+}
+
+// SEOService 负责元数据的校验、默认值推导及 meta 标签渲染
+type SEOService struct {
+	siteName string
+	baseURL  string
+}
+
+func NewSEOService(siteName, baseURL string) *SEOService {
+	return &SEOService{
+		siteName: siteName,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Validate 校验摘要及元数据的长度和格式
+func (s *SEOService) Validate(article *model.Article) error {
+	verr := &ValidationError{}
+	checkLength(verr, "excerpt", article.Excerpt, maxExcerptLength)
+	checkLength(verr, "seo.meta_title", article.SEO.MetaTitle, maxMetaTitleLength)
+	checkLength(verr, "seo.meta_description", article.SEO.MetaDescription, maxMetaDescriptionLength)
+	checkURL(verr, "seo.canonical_url", article.SEO.CanonicalURL)
+	checkURL(verr, "seo.og_image", article.SEO.OGImage)
+
+	if article.SEO.Robots != "" {
+		for _, directive := range strings.Split(article.SEO.Robots, ",") {
+			if !robotsDirectives[strings.TrimSpace(directive)] {
+				verr.Add("seo.robots", "robots", fmt.Sprintf("seo.robots has unknown directive %q", strings.TrimSpace(directive)))
+				break
+			}
+		}
+	}
+	return verr.Err()
+}
+
+// Resolve 返回补全默认值后的元数据：标题取文章标题，描述依次取摘要和正文开头，
+// 非公开文章默认不允许索引
+func (s *SEOService) Resolve(article *model.Article) *SEOMeta {
+	meta := &SEOMeta{
+		Title:        article.SEO.MetaTitle,
+		Description:  article.SEO.MetaDescription,
+		CanonicalURL: article.SEO.CanonicalURL,
+		OGImage:      article.SEO.OGImage,
+		Robots:       article.SEO.Robots,
+	}
+
+	if meta.Title == "" {
+		meta.Title = truncateRunes(article.Title, maxMetaTitleLength)
+	}
+	if meta.Description == "" {
+		description := article.Excerpt
+		if description == "" && article.Visibility == model.ArticleVisibilityPublic {
+			description = article.Content
+		}
+		meta.Description = truncateRunes(strings.Join(strings.Fields(description), " "), maxMetaDescriptionLength)
+	}
+	if meta.CanonicalURL == "" {
+		meta.CanonicalURL = fmt.Sprintf("%s/articles/%d", s.baseURL, article.ID)
+	}
+	if meta.Robots == "" {
+		meta.Robots = "index,follow"
+		if article.Visibility != model.ArticleVisibilityPublic || article.Status != model.ArticleStatusPublished {
+			meta.Robots = "noindex,nofollow"
+		}
+	}
+	return meta
+}
+
+// Tags 渲染可以直接放进 <head> 的 meta 标签
+func (s *SEOService) Tags(article *model.Article) *SEOMetaTags {
+	meta := s.Resolve(article)
+
+	var b strings.Builder
+	writeTag := func(format string, args ...interface{}) {
+		for i, arg := range args {
+			args[i] = html.EscapeString(fmt.Sprint(arg))
+		}
+		fmt.Fprintf(&b, format+"\n", args...)
+	}
+
+	writeTag(`<title>%s</title>`, meta.Title)
+	writeTag(`<meta name="description" content="%s">`, meta.Description)
+	writeTag(`<meta name="robots" content="%s">`, meta.Robots)
+	writeTag(`<link rel="canonical" href="%s">`, meta.CanonicalURL)
+	writeTag(`<meta property="og:type" content="article">`)
+	writeTag(`<meta property="og:title" content="%s">`, meta.Title)
+	writeTag(`<meta property="og:description" content="%s">`, meta.Description)
+	writeTag(`<meta property="og:url" content="%s">`, meta.CanonicalURL)
+	if s.siteName != "" {
+		writeTag(`<meta property="og:site_name" content="%s">`, s.siteName)
+	}
+	card := "summary"
+	if meta.OGImage != "" {
+		card = "summary_large_image"
+		writeTag(`<meta property="og:image" content="%s">`, meta.OGImage)
+		writeTag(`<meta name="twitter:image" content="%s">`, meta.OGImage)
+	}
+	if article.PublishedAt != nil {
+		writeTag(`<meta property="article:published_time" content="%s">`, article.PublishedAt.UTC().Format("2006-01-02T15:04:05Z"))
+	}
+	writeTag(`<meta name="twitter:card" content="%s">`, card)
+	writeTag(`<meta name="twitter:title" content="%s">`, meta.Title)
+	writeTag(`<meta name="twitter:description" content="%s">`, meta.Description)
+
+	return &SEOMetaTags{
+		SEOMeta: meta,
+		HTML:    b.String(),
+	}
+}
+
+func checkLength(verr *ValidationError, field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		verr.Add(field, "max_length", fmt.Sprintf("%s must be at most %d characters", field, max))
+	}
+}
+
+func checkURL(verr *ValidationError, field, value string) {
+	if value == "" {
+		return
+	}
+	if len(value) > maxURLLength {
+		verr.Add(field, "max_length", fmt.Sprintf("%s must be at most %d characters", field, maxURLLength))
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		verr.Add(field, "url", fmt.Sprintf("%s must be an absolute http(s) URL", field))
+	}
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
+
+// noIndex 判断 robots 指令是否禁止索引
+func noIndex(robots string) bool {
+	for _, directive := range strings.Split(robots, ",") {
+		switch strings.TrimSpace(directive) {
+		case "noindex", "none":
+			return true
+		}
+	}
+	return false
+}
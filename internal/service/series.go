@@ -0,0 +1,345 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+var (
+	ErrSeriesNotFound        = errors.New("series not found")
+	ErrSeriesVersionConflict = errors.New("series was modified by someone else, reload and try again")
+	ErrSeriesArticleInvalid  = errors.New("series articles must exist and must not repeat")
+	ErrArticleInOtherSeries  = errors.New("article already belongs to another series")
+	ErrSeriesForbidden       = errors.New("you do not have permission on this series")
+)
+
+type SeriesService struct {
+	repo          *repository.SeriesRepository
+	articleRepo   *repository.ArticleRepository
+	collaborators *CollaboratorService
+}
+
+func NewSeriesService(repo *repository.SeriesRepository, articleRepo *repository.ArticleRepository,
+	collaborators *CollaboratorService) *SeriesService {
+	return &SeriesService{
+		repo:          repo,
+		articleRepo:   articleRepo,
+		collaborators: collaborators,
+	}
+}
+
+type CreateSeriesRequest struct {
+	Title       string `json:"title" binding:"required" example:"Go 入门教程"`
+	Description string `json:"description" example:"从零开始学习 Go"`
+	ArticleIDs  []uint `json:"article_ids" example:"1,2,3"`
+}
+
+type UpdateSeriesRequest struct {
+	Title       string `json:"title" example:"Go 入门教程"`
+	Description string `json:"description" example:"从零开始学习 Go"`
+}
+
+type ReorderSeriesRequest struct {
+	Version    int    `json:"version" binding:"required" example:"1"` // 客户端读取到的版本号
+	ArticleIDs []uint `json:"article_ids" binding:"required" example:"3,1,2"`
+}
+
+// SeriesArticle 系列中的文章摘要
+type SeriesArticle struct {
+	Position int    `json:"position" example:"1"`
+	ID       uint   `json:"id" example:"1"`
+	Title    string `json:"title" example:"文章标题"`
+	Status   int    `json:"status" example:"2"`
+}
+
+type SeriesDetail struct {
+	*model.Series
+	Articles []*SeriesArticle `json:"articles"`
+}
+
+// ArticleRef 上一篇/下一篇导航中的文章引用
+type ArticleRef struct {
+	ID    uint   `json:"id" example:"1"`
+	Title string `json:"title" example:"文章标题"`
+}
+
+// SeriesNavigation 文章在系列中的位置及前后文章
+type SeriesNavigation struct {
+	ID       uint        `json:"id" example:"1"`
+	Title    string      `json:"title" example:"Go 入门教程"`
+	Position int         `json:"position" example:"2"`
+	Total    int         `json:"total" example:"5"`
+	Prev     *ArticleRef `json:"prev,omitempty"`
+	Next     *ArticleRef `json:"next,omitempty"`
+}
+
+// Create 创建系列，创建者成为系列的所有者，需要对每篇文章都有编辑权限
+func (s *SeriesService) Create(userID uint, req *CreateSeriesRequest) (*SeriesDetail, error) {
+	if err := s.checkArticles(0, userID, req.ArticleIDs); err != nil {
+		return nil, err
+	}
+
+	series := &model.Series{
+		Title:       req.Title,
+		Description: req.Description,
+		OwnerID:     userID,
+		Version:     1,
+	}
+	if err := s.repo.Create(series, req.ArticleIDs); err != nil {
+		return nil, err
+	}
+
+	return s.detail(series, nil)
+}
+
+// Get 按访问者身份返回系列，只列出访问者能在列表中看到的已发布文章
+func (s *SeriesService) Get(id uint, viewer *Viewer) (*SeriesDetail, error) {
+	series, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrSeriesNotFound
+	}
+	return s.detail(series, viewer)
+}
+
+func (s *SeriesService) List(page, pageSize int) ([]*model.Series, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	return s.repo.List(page, pageSize)
+}
+
+func (s *SeriesService) Update(id, userID uint, req *UpdateSeriesRequest) (*SeriesDetail, error) {
+	series, err := s.owned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	title, description := series.Title, series.Description
+	if req.Title != "" {
+		title = req.Title
+	}
+	if req.Description != "" {
+		description = req.Description
+	}
+	if err := s.repo.UpdateInfo(series.ID, title, description); err != nil {
+		return nil, err
+	}
+
+	return s.managedDetail(series.ID)
+}
+
+// Reorder 原子地替换系列中的文章及顺序。
+// 请求需携带读取时的版本号，版本不一致说明存在并发修改，返回 ErrSeriesVersionConflict
+func (s *SeriesService) Reorder(id, userID uint, req *ReorderSeriesRequest) (*SeriesDetail, error) {
+	if _, err := s.owned(id, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkArticles(id, userID, req.ArticleIDs); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.ReplaceParts(id, req.Version, req.ArticleIDs)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrSeriesVersionConflict
+	}
+
+	return s.managedDetail(id)
+}
+
+func (s *SeriesService) Delete(id, userID uint) error {
+	if _, err := s.owned(id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// Navigation 返回文章所在系列的导航信息，文章不属于任何系列时返回 nil。
+// 只列出访问者能在列表中看到的已发布文章，当前文章本身总是包含在内
+func (s *SeriesService) Navigation(articleID uint, viewer *Viewer) (*SeriesNavigation, error) {
+	part, err := s.repo.FindPartByArticle(articleID)
+	if err != nil || part == nil {
+		return nil, err
+	}
+
+	series, err := s.repo.GetByID(part.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := s.repo.ListParts(series.ID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(parts))
+	for _, p := range parts {
+		ids = append(ids, p.ArticleID)
+	}
+	found, err := s.articleRepo.ListByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Article, len(found))
+	for _, a := range found {
+		byID[a.ID] = a
+	}
+
+	visible := listedTo(viewer)
+	articles := make([]*model.Article, 0, len(parts))
+	for _, p := range parts {
+		a, ok := byID[p.ArticleID]
+		if !ok {
+			continue
+		}
+		if a.ID == articleID || visible(a) {
+			articles = append(articles, a)
+		}
+	}
+
+	nav := &SeriesNavigation{
+		ID:    series.ID,
+		Title: series.Title,
+		Total: len(articles),
+	}
+	for i, a := range articles {
+		if a.ID != articleID {
+			continue
+		}
+		nav.Position = i + 1
+		if i > 0 {
+			nav.Prev = &ArticleRef{ID: articles[i-1].ID, Title: articles[i-1].Title}
+		}
+		if i < len(articles)-1 {
+			nav.Next = &ArticleRef{ID: articles[i+1].ID, Title: articles[i+1].Title}
+		}
+	}
+	return nav, nil
+}
+
+// RemoveArticle 文章删除时将其从系列中移除
+func (s *SeriesService) RemoveArticle(articleID uint) error {
+	return s.repo.RemoveArticle(articleID)
+}
+
+// owned 返回用户可以管理的系列，只有所有者和管理员可以管理
+func (s *SeriesService) owned(id, userID uint) (*model.Series, error) {
+	series, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrSeriesNotFound
+	}
+	if (series.OwnerID == 0 || series.OwnerID != userID) && !s.collaborators.isAdmin(userID) {
+		return nil, ErrSeriesForbidden
+	}
+	return series, nil
+}
+
+// managedDetail 返回管理接口的系列详情，包含未发布的文章
+func (s *SeriesService) managedDetail(id uint) (*SeriesDetail, error) {
+	series, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrSeriesNotFound
+	}
+	return s.detail(series, nil)
+}
+
+// detail 组装系列详情，viewer 为 nil 时列出全部文章
+func (s *SeriesService) detail(series *model.Series, viewer *Viewer) (*SeriesDetail, error) {
+	articles, err := s.articles(series.ID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	return &SeriesDetail{Series: series, Articles: articles}, nil
+}
+
+// articles 按顺序返回系列中的文章，已不存在的文章会被跳过。
+// viewer 不为 nil 时只返回访问者能在列表中看到的已发布文章
+func (s *SeriesService) articles(seriesID uint, viewer *Viewer) ([]*SeriesArticle, error) {
+	parts, err := s.repo.ListParts(seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(parts))
+	for _, p := range parts {
+		ids = append(ids, p.ArticleID)
+	}
+	found, err := s.articleRepo.ListByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Article, len(found))
+	for _, a := range found {
+		byID[a.ID] = a
+	}
+
+	visible := func(*model.Article) bool { return true }
+	if viewer != nil {
+		visible = listedTo(viewer)
+	}
+	articles := make([]*SeriesArticle, 0, len(parts))
+	for _, p := range parts {
+		a, ok := byID[p.ArticleID]
+		if !ok || !visible(a) {
+			continue
+		}
+		articles = append(articles, &SeriesArticle{
+			Position: len(articles) + 1,
+			ID:       a.ID,
+			Title:    a.Title,
+			Status:   a.Status,
+		})
+	}
+	return articles, nil
+}
+
+// checkArticles 校验文章存在、不重复、用户有编辑权限，且不属于其他系列
+func (s *SeriesService) checkArticles(seriesID, userID uint, articleIDs []uint) error {
+	seen := make(map[uint]bool, len(articleIDs))
+	for _, id := range articleIDs {
+		if seen[id] {
+			return ErrSeriesArticleInvalid
+		}
+		seen[id] = true
+	}
+
+	found, err := s.articleRepo.ListByIDs(articleIDs)
+	if err != nil {
+		return err
+	}
+	if len(found) != len(articleIDs) {
+		return ErrSeriesArticleInvalid
+	}
+	for _, id := range articleIDs {
+		if err := s.collaborators.Authorize(userID, id, model.GrantRoleEditor); err != nil {
+			return err
+		}
+	}
+
+	others, err := s.repo.FindPartsInOtherSeries(seriesID, articleIDs)
+	if err != nil {
+		return err
+	}
+	if len(others) > 0 {
+		return ErrArticleInOtherSeries
+	}
+	return nil
+}
+
+// listedTo 返回判断文章是否出现在访问者列表中的函数：已发布且可见性允许
+func listedTo(viewer *Viewer) func(*model.Article) bool {
+	visible := make(map[string]bool)
+	for _, v := range listedVisibilities(viewer) {
+		visible[v] = true
+	}
+	return func(a *model.Article) bool {
+		return a.Status == model.ArticleStatusPublished && visible[a.Visibility]
+	}
+}
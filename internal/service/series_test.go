@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+func TestSeriesRequiresOwnershipAndHidesUnpublishedParts(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	articles := repository.NewArticleRepository(db)
+	collaborators := NewCollaboratorService(repository.NewArticleGrantRepository(db), articles,
+		repository.NewUserRepository(db), policy)
+	svc := NewSeriesService(repository.NewSeriesRepository(db), articles, collaborators)
+
+	alice := createTestUser(t, db, policy, "alice", "user")
+	mallory := createTestUser(t, db, policy, "mallory", "user")
+	now := time.Now()
+	published := &model.Article{Title: "part 1", Content: "x", Status: model.ArticleStatusPublished,
+		Visibility: model.ArticleVisibilityPublic, PublishedAt: &now}
+	draft := &model.Article{Title: "part 2", Content: "x", Status: model.ArticleStatusDraft,
+		Visibility: model.ArticleVisibilityPublic}
+	members := &model.Article{Title: "part 3", Content: "x", Status: model.ArticleStatusPublished,
+		Visibility: model.ArticleVisibilityMembers, PublishedAt: &now}
+	for _, a := range []*model.Article{published, draft, members} {
+		if err := articles.Create(a); err != nil {
+			t.Fatal(err)
+		}
+		if err := collaborators.GrantOwner(a.ID, alice.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ids := []uint{published.ID, draft.ID, members.ID}
+
+	if _, err := svc.Create(mallory.ID, &CreateSeriesRequest{Title: "stolen", ArticleIDs: ids}); err != ErrArticleForbidden {
+		t.Fatalf("series of someone else's articles = %v, want ErrArticleForbidden", err)
+	}
+	series, err := svc.Create(alice.ID, &CreateSeriesRequest{Title: "mine", ArticleIDs: ids})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series.Articles) != 3 {
+		t.Fatalf("owner sees %d parts, want 3", len(series.Articles))
+	}
+
+	anonymous, err := svc.Get(series.ID, &Viewer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anonymous.Articles) != 1 || anonymous.Articles[0].ID != published.ID {
+		t.Fatalf("anonymous sees %+v, want only the published public part", anonymous.Articles)
+	}
+	member, err := svc.Get(series.ID, &Viewer{UserID: mallory.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(member.Articles) != 2 {
+		t.Fatalf("member sees %d parts, want 2", len(member.Articles))
+	}
+
+	reorder := &ReorderSeriesRequest{Version: series.Version, ArticleIDs: []uint{members.ID, published.ID}}
+	if _, err := svc.Reorder(series.ID, mallory.ID, reorder); err != ErrSeriesForbidden {
+		t.Fatalf("stranger reordering = %v, want ErrSeriesForbidden", err)
+	}
+	if _, err := svc.Update(series.ID, mallory.ID, &UpdateSeriesRequest{Title: "x"}); err != ErrSeriesForbidden {
+		t.Fatalf("stranger updating = %v, want ErrSeriesForbidden", err)
+	}
+	if err := svc.Delete(series.ID, mallory.ID); err != ErrSeriesForbidden {
+		t.Fatalf("stranger deleting = %v, want ErrSeriesForbidden", err)
+	}
+	if _, err := svc.Reorder(series.ID, alice.ID, reorder); err != nil {
+		t.Fatalf("owner reordering: %v", err)
+	}
+	if err := svc.Delete(series.ID, alice.ID); err != nil {
+		t.Fatalf("owner deleting: %v", err)
+	}
+}
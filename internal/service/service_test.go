@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+// newTestDB 返回迁移好的内存数据库，每个测试独立
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(
+		&model.Role{},
+		&model.User{},
+		&model.RefreshToken{},
+		&model.TokenRevocation{},
+		&model.PersonalToken{},
+		&model.ExternalIdentity{},
+		&model.TwoFactor{},
+		&model.BackupCode{},
+		&model.LoginFailure{},
+		&model.Article{},
+		&model.ArticleGrant{},
+		&model.ArticleReview{},
+		&model.Series{},
+		&model.SeriesPart{},
+		&model.PreviewLink{},
+		&model.PreviewAccess{},
+		&model.Webhook{},
+		&model.WebhookDelivery{},
+	); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// newTestPolicy 返回只保存在内存中的权限策略
+func newTestPolicy(t *testing.T) *PolicyService {
+	t.Helper()
+	enforcer, err := casbin.NewEnforcer("../../configs/model.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewPolicyService(enforcer)
+}
+
+// createTestUser 创建已激活的用户，roles 为其角色
+func createTestUser(t *testing.T, db *gorm.DB, policy *PolicyService, username string, roles ...string) *model.User {
+	t.Helper()
+	user := &model.User{Username: username, Email: username + "@example.com", Status: model.UserStatusActive}
+	if err := user.SetPassword("correct-horse-42"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.NewUserRepository(db).Create(user); err != nil {
+		t.Fatal(err)
+	}
+	for _, role := range roles {
+		if err := policy.AddRoleForUser(username, role); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return user
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/theme"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+// SiteService 为服务端渲染的公开站点准备页面数据，只展示已发布的公开文章
+type SiteService struct {
+	articles    *ArticleService
+	articleRepo *repository.ArticleRepository
+	grantRepo   *repository.ArticleGrantRepository
+	userRepo    *repository.UserRepository
+	seo         *SEOService
+	site        theme.Site
+	pageSize    int
+}
+
+func NewSiteService(articles *ArticleService, articleRepo *repository.ArticleRepository,
+	grantRepo *repository.ArticleGrantRepository, userRepo *repository.UserRepository,
+	seo *SEOService, site theme.Site, pageSize int) *SiteService {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	return &SiteService{
+		articles:    articles,
+		articleRepo: articleRepo,
+		grantRepo:   grantRepo,
+		userRepo:    userRepo,
+		seo:         seo,
+		site:        site,
+		pageSize:    pageSize,
+	}
+}
+
+// Home 首页文章列表
+func (s *SiteService) Home(page int) (*theme.Page, error) {
+	return s.list(&repository.PublishedQuery{}, page, func(p int) string {
+		return fmt.Sprintf("/?page=%d", p)
+	})
+}
+
+// Article 文章页，访问规则与公开接口一致，草稿视为不存在
+func (s *SiteService) Article(id uint) (*theme.Page, error) {
+	detail, err := s.articles.Get(id, &Viewer{})
+	if err != nil {
+		return nil, err
+	}
+	if detail.Status != model.ArticleStatusPublished {
+		return nil, ErrArticleNotFound
+	}
+
+	data := s.page(detail.Title)
+	data.Meta = template.HTML(s.seo.Tags(detail.Article).HTML)
+	data.Article = detail.Article
+	if grant, err := s.grantRepo.FindOwner(id); err == nil {
+		if user, err := s.userRepo.FindById(grant.UserID); err == nil {
+			data.Author = user.Username
+		}
+	}
+	return data, nil
+}
+
+// Author 作者页，列出用户拥有的文章
+func (s *SiteService) Author(username string, page int) (*theme.Page, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	data, err := s.list(&repository.PublishedQuery{OwnerID: user.ID}, page, func(p int) string {
+		return fmt.Sprintf("/authors/%s?page=%d", url.PathEscape(user.Username), p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	data.Title = user.Username
+	data.Author = user.Username
+	return data, nil
+}
+
+// Search 按关键词搜索文章，关键词为空时只展示搜索框
+func (s *SiteService) Search(keyword string, page int) (*theme.Page, error) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return s.page("搜索"), nil
+	}
+
+	data, err := s.list(&repository.PublishedQuery{Keyword: keyword}, page, func(p int) string {
+		return fmt.Sprintf("/search?q=%s&page=%d", url.QueryEscape(keyword), p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	data.Title = keyword
+	data.Query = keyword
+	return data, nil
+}
+
+// Error 错误页
+func (s *SiteService) Error(title, message string) *theme.Page {
+	data := s.page(title)
+	data.Message = message
+	return data
+}
+
+func (s *SiteService) list(q *repository.PublishedQuery, page int, pageURL func(int) string) (*theme.Page, error) {
+	if page < 1 {
+		page = 1
+	}
+	q.Visibilities = []string{model.ArticleVisibilityPublic}
+
+	articles, total, err := s.articleRepo.ListPublishedPage(q, page, s.pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int((total + int64(s.pageSize) - 1) / int64(s.pageSize))
+	pagination := &theme.Pagination{Page: page, TotalPages: totalPages}
+	if page > 1 {
+		pagination.PrevURL = pageURL(page - 1)
+	}
+	if page < totalPages {
+		pagination.NextURL = pageURL(page + 1)
+	}
+
+	data := s.page("")
+	data.Articles = articles
+	data.Pagination = pagination
+	return data, nil
+}
+
+func (s *SiteService) page(title string) *theme.Page {
+	return &theme.Page{
+		Site:    s.site,
+		Title:   title,
+		Dynamic: true,
+	}
+}
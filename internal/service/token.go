@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+const (
+	refreshTokenPrefix   = "rt_"
+	defaultRefreshExpire = 30 * 24 * time.Hour
+	tokenPruneInterval   = time.Hour
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, all sessions of this login have been revoked")
+)
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenPair 短期的访问 token 及用于续期的刷新 token
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in" example:"900"` // 访问 token 有效期（秒）
+}
+
+// TokenService 签发访问 token 和刷新 token，负责刷新 token 的轮换及复用检测
+type TokenService struct {
+	repo          *repository.RefreshTokenRepository
+	userRepo      *repository.UserRepository
+	accessExpire  time.Duration
+	refreshExpire time.Duration
+}
+
+func NewTokenService(repo *repository.RefreshTokenRepository, userRepo *repository.UserRepository,
+	accessExpire, refreshExpire time.Duration) *TokenService {
+	if refreshExpire <= 0 {
+		refreshExpire = defaultRefreshExpire
+	}
+	return &TokenService{
+		repo:          repo,
+		userRepo:      userRepo,
+		accessExpire:  accessExpire,
+		refreshExpire: refreshExpire,
+	}
+}
+
+// Issue 登录成功后签发一对新的 token，刷新 token 开始一个新的 family，
+// family 的有效期从此时开始计算，轮换不会延长
+func (s *TokenService) Issue(userID uint) (*TokenPair, error) {
+	return s.issue(userID, randomHex(16), time.Now().Add(s.refreshExpire))
+}
+
+// Refresh 用刷新 token 换取新的一对 token，原刷新 token 作废。
+// 已作废的 token 被再次使用时撤销整个 family
+func (s *TokenService) Refresh(refreshToken string) (*TokenPair, error) {
+	if !strings.HasPrefix(refreshToken, refreshTokenPrefix) {
+		return nil, ErrInvalidRefreshToken
+	}
+	token, err := s.repo.FindByHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil || now.After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if token.UsedAt != nil {
+		return nil, s.revokeReused(token, now)
+	}
+	// 并发刷新时只有一个请求能成功轮换，其余按复用处理
+	ok, err := s.repo.MarkUsed(token.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, s.revokeReused(token, now)
+	}
+
+	user, err := s.userRepo.FindById(token.UserID)
+	if err != nil || user.Status != model.UserStatusActive {
+		return nil, ErrInvalidRefreshToken
+	}
+	return s.issue(user.ID, token.FamilyID, token.ExpiresAt)
+}
+
+// RevokeFamily 注销时撤销刷新 token 所在的 family，token 不属于该用户时忽略
+func (s *TokenService) RevokeFamily(userID uint, refreshToken string) error {
+	token, err := s.repo.FindByHash(hashToken(refreshToken))
+	if err != nil || token.UserID != userID {
+		return nil
+	}
+	return s.repo.RevokeFamily(token.FamilyID, time.Now())
+}
+
+// RevokeUser 撤销用户的全部刷新 token
+func (s *TokenService) RevokeUser(userID uint) error {
+	return s.repo.RevokeByUser(userID, time.Now())
+}
+
+// Run 定期清理过期的刷新 token，随服务启动
+func (s *TokenService) Run(ctx context.Context) {
+	ticker := time.NewTicker(tokenPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.repo.DeleteExpired(time.Now()); err != nil {
+				log.Printf("token: failed to prune expired refresh tokens: %v", err)
+			}
+		}
+	}
+}
+
+func (s *TokenService) issue(userID uint, familyID string, expiresAt time.Time) (*TokenPair, error) {
+	access, err := auth.GenerateToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh := newOpaqueToken(refreshTokenPrefix)
+	if err := s.repo.Create(&model.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refresh),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		Token:        access,
+		RefreshToken: refresh,
+		ExpiresIn:    int(s.accessExpire.Seconds()),
+	}, nil
+}
+
+func (s *TokenService) revokeReused(token *model.RefreshToken, now time.Time) error {
+	log.Printf("token: refresh token reuse detected for user %d, revoking family %s", token.UserID, token.FamilyID)
+	if err := s.repo.RevokeFamily(token.FamilyID, now); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// newOpaqueToken 生成带前缀的随机 token，前缀便于识别 token 类型
+func newOpaqueToken(prefix string) string {
+	return prefix + randomHex(32)
+}
+
+// hashToken 数据库中只保存 token 的 SHA-256 哈希
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
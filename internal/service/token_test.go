@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+func TestRefreshKeepsFamilyExpiry(t *testing.T) {
+	auth.Initialize("test-secret", time.Minute)
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	tokens := repository.NewRefreshTokenRepository(db)
+	svc := NewTokenService(tokens, repository.NewUserRepository(db), time.Minute, time.Hour)
+	user := createTestUser(t, db, policy, "alice", "user")
+
+	pair, err := svc.Issue(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := tokens.FindByHash(hashToken(pair.RefreshToken))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if pair, err = svc.Refresh(pair.RefreshToken); err != nil {
+			t.Fatalf("refresh %d: %v", i, err)
+		}
+	}
+	last, err := tokens.FindByHash(hashToken(pair.RefreshToken))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.ExpiresAt.Equal(first.ExpiresAt) {
+		t.Fatalf("rotated token expires at %v, want family expiry %v", last.ExpiresAt, first.ExpiresAt)
+	}
+}
+
+func TestRefreshRequiresActiveUser(t *testing.T) {
+	auth.Initialize("test-secret", time.Minute)
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	svc := NewTokenService(repository.NewRefreshTokenRepository(db), repository.NewUserRepository(db), time.Minute, time.Hour)
+	user := createTestUser(t, db, policy, "alice", "user")
+
+	for _, status := range []model.UserStatus{model.UserStatusInactive, model.UserStatusBanned} {
+		pair, err := svc.Issue(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Model(user).Update("status", status).Error; err != nil {
+			t.Fatal(err)
+		}
+		if _, err := svc.Refresh(pair.RefreshToken); err != ErrInvalidRefreshToken {
+			t.Errorf("refresh for %s user = %v, want ErrInvalidRefreshToken", status, err)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+	"github.com/wuwen/hello-go/internal/pkg/totp"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+const (
+	twoFactorLoginAudience = "2fa-login" // 已启用两步验证，等待输入验证码
+	twoFactorSetupAudience = "2fa-setup" // 角色要求两步验证但尚未启用，等待完成绑定
+	twoFactorChallengeTTL  = 5 * time.Minute
+	totpSkew               = 1 // 允许前后各一个时间步的时钟偏差
+	backupCodeCount        = 10
+	maxTwoFactorFailures   = 5
+	twoFactorLockout       = 15 * time.Minute
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetUp       = errors.New("two-factor authentication has not been set up")
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is required for your role")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrTwoFactorLocked         = errors.New("too many invalid two-factor codes, try again later")
+	ErrInvalidChallenge        = errors.New("invalid or expired login challenge")
+)
+
+// backupCodeEncoding 恢复码使用小写 base32 字符，避免 0/O、1/l 混淆
+var backupCodeEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"` // 验证器应用中的验证码或备用恢复码
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required" example:"123456"`
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// TwoFactorSetup 新生成的密钥，ProvisioningURI 生成二维码后供验证器应用扫描
+type TwoFactorSetup struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/CMS:testuser?issuer=CMS&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+}
+
+// BackupCodes 备用恢复码，只在生成时返回一次
+type BackupCodes struct {
+	Codes []string `json:"backup_codes" example:"k7m2p-x9qrt"`
+}
+
+type TwoFactorStatus struct {
+	Enabled              bool  `json:"enabled"`
+	Required             bool  `json:"required"` // 用户的角色要求两步验证
+	BackupCodesRemaining int64 `json:"backup_codes_remaining" example:"10"`
+}
+
+// TwoFactorService 基于 TOTP 的两步验证：绑定验证器、备用恢复码及登录时的第二步校验
+type TwoFactorService struct {
+	repo          *repository.TwoFactorRepository
+	userRepo      *repository.UserRepository
+	roleRepo      *repository.RoleRepository
+	policyService *PolicyService
+	issuer        string
+}
+
+func NewTwoFactorService(repo *repository.TwoFactorRepository, userRepo *repository.UserRepository,
+	roleRepo *repository.RoleRepository, policyService *PolicyService, issuer string) *TwoFactorService {
+	return &TwoFactorService{
+		repo:          repo,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+		policyService: policyService,
+		issuer:        issuer,
+	}
+}
+
+// Status 返回用户的两步验证状态
+func (s *TwoFactorService) Status(userID uint) (*TwoFactorStatus, error) {
+	user, err := s.userRepo.FindById(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	required, err := s.Required(user)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &TwoFactorStatus{Required: required}
+	if tf, err := s.repo.Find(userID); err == nil && tf.EnabledAt != nil {
+		status.Enabled = true
+		if status.BackupCodesRemaining, err = s.repo.CountUnusedBackupCodes(userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// Required 用户的任一角色要求两步验证时返回 true
+func (s *TwoFactorService) Required(user *model.User) (bool, error) {
+	roles, err := s.roleRepo.ListRequiringTwoFactor()
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		ok, err := s.policyService.HasRoleForUser(user.Username, role.Name)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Setup 生成新的密钥，需要用验证码确认后才会启用
+func (s *TwoFactorService) Setup(userID uint) (*TwoFactorSetup, error) {
+	user, err := s.userRepo.FindById(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if tf, err := s.repo.Find(userID); err == nil && tf.EnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveSecret(userID, secret); err != nil {
+		return nil, err
+	}
+	return &TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, user.Username, secret),
+	}, nil
+}
+
+// Confirm 用验证器应用中的验证码确认绑定，启用两步验证并返回备用恢复码
+func (s *TwoFactorService) Confirm(userID uint, code string) (*BackupCodes, error) {
+	tf, err := s.repo.Find(userID)
+	if err != nil {
+		return nil, ErrTwoFactorNotSetUp
+	}
+	if tf.EnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if err := s.verify(tf, code, false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes := newBackupCodes()
+	if err := s.repo.Enable(userID, time.Now(), hashes); err != nil {
+		return nil, err
+	}
+	return &BackupCodes{Codes: codes}, nil
+}
+
+// Disable 关闭两步验证，角色要求两步验证时不允许关闭
+func (s *TwoFactorService) Disable(userID uint, code string) error {
+	user, err := s.userRepo.FindById(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	required, err := s.Required(user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+
+	tf, err := s.enabled(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verify(tf, code, true); err != nil {
+		return err
+	}
+	return s.repo.Delete(userID)
+}
+
+// RegenerateBackupCodes 生成新的一组备用恢复码，旧的全部作废
+func (s *TwoFactorService) RegenerateBackupCodes(userID uint, code string) (*BackupCodes, error) {
+	tf, err := s.enabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verify(tf, code, false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes := newBackupCodes()
+	if err := s.repo.ReplaceBackupCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return &BackupCodes{Codes: codes}, nil
+}
+
+// Challenge 密码校验通过后调用：已启用两步验证时返回登录 challenge token；
+// 角色要求两步验证但尚未启用时返回绑定用的 challenge token，setup 为 true；都不需要时返回空字符串
+func (s *TwoFactorService) Challenge(user *model.User) (token string, setup bool, err error) {
+	if tf, err := s.repo.Find(user.ID); err == nil && tf.EnabledAt != nil {
+		token, err := auth.GenerateScopedToken(twoFactorLoginAudience, strconv.FormatUint(uint64(user.ID), 10), twoFactorChallengeTTL)
+		return token, false, err
+	}
+
+	required, err := s.Required(user)
+	if err != nil || !required {
+		return "", false, err
+	}
+	token, err = auth.GenerateScopedToken(twoFactorSetupAudience, strconv.FormatUint(uint64(user.ID), 10), twoFactorChallengeTTL)
+	return token, true, err
+}
+
+// SetupChallenge 使用绑定用的 challenge token 生成密钥，用于登录时被要求启用两步验证的用户
+func (s *TwoFactorService) SetupChallenge(challenge string) (*TwoFactorSetup, error) {
+	userID, err := parseChallenge(twoFactorSetupAudience, challenge)
+	if err != nil {
+		return nil, err
+	}
+	return s.Setup(userID)
+}
+
+// Complete 校验登录第二步，返回用户 ID。绑定用的 challenge 校验通过时同时启用两步验证并返回备用恢复码
+func (s *TwoFactorService) Complete(req *TwoFactorLoginRequest) (uint, *BackupCodes, error) {
+	if userID, err := parseChallenge(twoFactorLoginAudience, req.ChallengeToken); err == nil {
+		tf, err := s.enabled(userID)
+		if err != nil {
+			return 0, nil, ErrInvalidChallenge
+		}
+		if err := s.verify(tf, req.Code, true); err != nil {
+			return 0, nil, err
+		}
+		return userID, nil, nil
+	}
+
+	userID, err := parseChallenge(twoFactorSetupAudience, req.ChallengeToken)
+	if err != nil {
+		return 0, nil, err
+	}
+	codes, err := s.Confirm(userID, req.Code)
+	if err != nil {
+		return 0, nil, err
+	}
+	return userID, codes, nil
+}
+
+func (s *TwoFactorService) enabled(userID uint) (*model.TwoFactor, error) {
+	tf, err := s.repo.Find(userID)
+	if err != nil || tf.EnabledAt == nil {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	return tf, nil
+}
+
+// verify 校验 TOTP 验证码，allowBackup 时也接受备用恢复码。
+// 连续失败 maxTwoFactorFailures 次后锁定 twoFactorLockout
+func (s *TwoFactorService) verify(tf *model.TwoFactor, code string, allowBackup bool) error {
+	now := time.Now()
+	if tf.LockedUntil != nil && now.Before(*tf.LockedUntil) {
+		return ErrTwoFactorLocked
+	}
+
+	if counter, ok := totp.Validate(tf.Secret, code, now, totpSkew); ok {
+		used, err := s.repo.UseCounter(tf.UserID, counter)
+		if err != nil {
+			return err
+		}
+		if used {
+			return nil
+		}
+	} else if allowBackup {
+		used, err := s.repo.UseBackupCode(tf.UserID, hashToken(normalizeBackupCode(code)), now)
+		if err != nil {
+			return err
+		}
+		if used {
+			return s.repo.ResetFailures(tf.UserID)
+		}
+	}
+
+	var lockedUntil *time.Time
+	if tf.FailedAttempts+1 >= maxTwoFactorFailures {
+		until := now.Add(twoFactorLockout)
+		lockedUntil = &until
+	}
+	if err := s.repo.RecordFailure(tf.UserID, lockedUntil); err != nil {
+		return err
+	}
+	return ErrInvalidTwoFactorCode
+}
+
+func parseChallenge(audience, challenge string) (uint, error) {
+	subject, err := auth.ParseScopedToken(audience, challenge)
+	if err != nil {
+		return 0, ErrInvalidChallenge
+	}
+	userID, err := strconv.ParseUint(subject, 10, 32)
+	if err != nil {
+		return 0, ErrInvalidChallenge
+	}
+	return uint(userID), nil
+}
+
+// newBackupCodes 生成备用恢复码及其哈希，恢复码形如 k7m2p-x9qrt
+func newBackupCodes() ([]string, []string) {
+	codes := make([]string, 0, backupCodeCount)
+	hashes := make([]string, 0, backupCodeCount)
+	for i := 0; i < backupCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			panic(err)
+		}
+		raw := backupCodeEncoding.EncodeToString(b)[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes
+}
+
+func normalizeBackupCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/pkg/totp"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+func TestTwoFactorRejectsReusedCode(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	userRepo := repository.NewUserRepository(db)
+	svc := NewTwoFactorService(repository.NewTwoFactorRepository(db), userRepo, repository.NewRoleRepository(db), policy, "test")
+
+	alice := createTestUser(t, db, policy, "alice", "user")
+	setup, err := svc.Setup(alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter := totp.Counter(time.Now())
+	code, err := totp.Code(setup.Secret, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Confirm(alice.ID, code); err != nil {
+		t.Fatalf("confirm with current code: %v", err)
+	}
+
+	// 同一个验证码不能再次使用，即使仍在有效期内
+	if _, err := svc.RegenerateBackupCodes(alice.ID, code); err != ErrInvalidTwoFactorCode {
+		t.Fatalf("reusing the confirm code = %v, want ErrInvalidTwoFactorCode", err)
+	}
+	next, err := totp.Code(setup.Secret, counter+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RegenerateBackupCodes(alice.ID, next); err != nil {
+		t.Fatalf("code of the next step: %v", err)
+	}
+}
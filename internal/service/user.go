@@ -14,19 +14,45 @@ var (
 	ErrUserNotFound = errors.New("user not found")
 	ErrInvalidAuth  = errors.New("invalid username or password")
 	ErrUserExist    = errors.New("user already exists")
+	ErrUserInactive = errors.New("email address has not been verified")
+	ErrUserBanned   = errors.New("user is banned")
+
+	ErrUserForbidden          = errors.New("you can only update your own account")
+	ErrInvalidCurrentPassword = errors.New("current_password is missing or incorrect")
 )
 
 type UserService struct {
-	repo          *repository.UserRepository
-	roleRepo      *repository.RoleRepository
-	policyService *PolicyService
+	repo           *repository.UserRepository
+	roleRepo       *repository.RoleRepository
+	policyService  *PolicyService
+	tokens         *TokenService
+	revocations    *RevocationService
+	personalTokens *PersonalTokenService
+	verification   *VerificationService
+	resets         *PasswordResetService
+	twoFactor      *TwoFactorService
+	loginGuard     *LoginGuardService
+	passwords      *PasswordPolicy
+	webhooks       *WebhookService
 }
 
-func NewUserService(repo *repository.UserRepository, roleRepo *repository.RoleRepository, policyService *PolicyService) *UserService {
+func NewUserService(repo *repository.UserRepository, roleRepo *repository.RoleRepository, policyService *PolicyService,
+	tokens *TokenService, revocations *RevocationService, personalTokens *PersonalTokenService, verification *VerificationService,
+	resets *PasswordResetService, twoFactor *TwoFactorService, loginGuard *LoginGuardService,
+	passwords *PasswordPolicy, webhooks *WebhookService) *UserService {
 	return &UserService{
-		repo:          repo,
-		roleRepo:      roleRepo,
-		policyService: policyService,
+		repo:           repo,
+		roleRepo:       roleRepo,
+		policyService:  policyService,
+		tokens:         tokens,
+		revocations:    revocations,
+		personalTokens: personalTokens,
+		verification:   verification,
+		resets:         resets,
+		twoFactor:      twoFactor,
+		loginGuard:     loginGuard,
+		passwords:      passwords,
+		webhooks:       webhooks,
 	}
 }
 
@@ -34,6 +60,7 @@ type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
+	Locale   string `json:"locale" binding:"omitempty,max=16" example:"zh-CN"` // 邮件使用的语言，可选
 }
 
 type LoginRequest struct {
@@ -41,14 +68,26 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginResponse 登录结果。需要两步验证时只返回 challenge token，
+// 用它和验证码调用 /users/login/2fa 完成登录
 type LoginResponse struct {
-	Token string      `json:"token"`
-	User  *model.User `json:"user"`
+	*TokenPair
+	User                   *model.User `json:"user,omitempty"`
+	TwoFactorRequired      bool        `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool        `json:"two_factor_setup_required,omitempty"` // 角色要求两步验证，需要先完成绑定
+	ChallengeToken         string      `json:"challenge_token,omitempty"`
+	BackupCodes            []string    `json:"backup_codes,omitempty"` // 登录时完成绑定才会返回
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"` // 可选，同时撤销该刷新 token
 }
 
 type UpdateRequest struct {
-	Email    string `json:"email" binding:"omitempty,email"`
-	Password string `json:"password" binding:"omitempty"`
+	Email           string `json:"email" binding:"omitempty,email"`
+	Password        string `json:"password" binding:"omitempty"`
+	CurrentPassword string `json:"current_password"` // 修改邮箱或密码时必填，为操作者自己的当前密码
+	Locale          string `json:"locale" binding:"omitempty,max=16" example:"zh-CN"`
 }
 
 func (s *UserService) Register(req *RegisterRequest) (*model.User, error) {
@@ -58,11 +97,20 @@ func (s *UserService) Register(req *RegisterRequest) (*model.User, error) {
 		return nil, ErrUserExist
 	}
 
+	if err := s.passwords.Validate(req.Password, req.Username, req.Email); err != nil {
+		return nil, err
+	}
+
 	user := &model.User{
 		Username: req.Username,
 		Email:    req.Email,
+		Locale:   req.Locale,
 		Status:   model.UserStatusActive,
 	}
+	// 需要验证邮箱时，用户在点击验证链接前处于未激活状态
+	if s.verification.Required() {
+		user.Status = model.UserStatusInactive
+	}
 
 	if err := user.SetPassword(req.Password); err != nil {
 		return nil, err
@@ -73,32 +121,207 @@ func (s *UserService) Register(req *RegisterRequest) (*model.User, error) {
 		return nil, fmt.Errorf("failed to assign default role: %v", err)
 	}
 
-	return s.repo.Create(user)
+	user, err = s.repo.Create(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status == model.UserStatusInactive {
+		if err := s.verification.Send(user); err != nil {
+			return nil, err
+		}
+	}
+
+	s.webhooks.Publish(EventUserRegistered, user)
+	return user, nil
+}
+
+// VerifyEmail 校验邮箱验证链接并激活用户
+func (s *UserService) VerifyEmail(token string) (*model.User, error) {
+	return s.verification.Verify(token)
 }
 
-func (s *UserService) Login(req *LoginRequest) (*LoginResponse, error) {
+// ResendVerification 重新发送验证邮件
+func (s *UserService) ResendVerification(req *ResendVerificationRequest) error {
+	return s.verification.Resend(req)
+}
+
+// Login 校验用户名和密码，ip 为客户端地址，用于统计登录失败
+func (s *UserService) Login(req *LoginRequest, ip string) (*LoginResponse, error) {
+	if err := s.loginGuard.Check(req.Username, ip); err != nil {
+		return nil, err
+	}
+
 	user, err := s.repo.FindByUsername(req.Username)
-	if err != nil {
+	if err != nil || !user.ValidatePassword(req.Password) {
+		if err := s.loginGuard.Fail(req.Username, ip); err != nil {
+			return nil, err
+		}
 		return nil, ErrInvalidAuth
 	}
+	if err := s.loginGuard.Succeed(req.Username); err != nil {
+		return nil, err
+	}
+	// 密码正确后才区分账号状态，避免泄露账号信息
+	return s.completeLogin(user)
+}
 
-	if !user.ValidatePassword(req.Password) {
-		return nil, ErrInvalidAuth
+// LoginExternal 为已通过外部身份提供方认证的用户完成登录，同样检查账号状态及两步验证
+func (s *UserService) LoginExternal(user *model.User) (*LoginResponse, error) {
+	return s.completeLogin(user)
+}
+
+func (s *UserService) completeLogin(user *model.User) (*LoginResponse, error) {
+	switch user.Status {
+	case model.UserStatusInactive:
+		return nil, ErrUserInactive
+	case model.UserStatusBanned:
+		return nil, ErrUserBanned
+	}
+
+	// 启用了两步验证或角色要求两步验证时，先返回 challenge token
+	challenge, setup, err := s.twoFactor.Challenge(user)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != "" {
+		return &LoginResponse{
+			TwoFactorRequired:      !setup,
+			TwoFactorSetupRequired: setup,
+			ChallengeToken:         challenge,
+		}, nil
+	}
+
+	return s.issueLogin(user, nil)
+}
+
+// LoginTwoFactor 登录第二步：校验 challenge token 及验证码后签发 token
+func (s *UserService) LoginTwoFactor(req *TwoFactorLoginRequest) (*LoginResponse, error) {
+	userID, codes, err := s.twoFactor.Complete(req)
+	if err != nil {
+		return nil, err
 	}
 
-	// 生成 JWT token
-	token, err := auth.GenerateToken(user.ID)
+	user, err := s.repo.FindById(userID)
+	if err != nil || user.Status != model.UserStatusActive {
+		return nil, ErrInvalidChallenge
+	}
+	return s.issueLogin(user, codes)
+}
+
+// SetupTwoFactorLogin 登录时被要求启用两步验证的用户，用 challenge token 生成密钥
+func (s *UserService) SetupTwoFactorLogin(req *TwoFactorChallengeRequest) (*TwoFactorSetup, error) {
+	return s.twoFactor.SetupChallenge(req.ChallengeToken)
+}
+
+func (s *UserService) issueLogin(user *model.User, codes *BackupCodes) (*LoginResponse, error) {
+	// 签发访问 token 和刷新 token
+	tokens, err := s.tokens.Issue(user.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	return &LoginResponse{
-		Token: token,
-		User:  user,
-	}, nil
+	resp := &LoginResponse{
+		TokenPair: tokens,
+		User:      user,
+	}
+	if codes != nil {
+		resp.BackupCodes = codes.Codes
+	}
+	return resp, nil
+}
+
+// RefreshToken 轮换刷新 token 并签发新的访问 token
+func (s *UserService) RefreshToken(req *RefreshTokenRequest) (*TokenPair, error) {
+	return s.tokens.Refresh(req.RefreshToken)
+}
+
+// Logout 撤销当前访问 token，传入刷新 token 时一并撤销
+func (s *UserService) Logout(claims *auth.Claims, req *LogoutRequest) error {
+	if claims.Personal() {
+		return ErrSessionRequired
+	}
+	if err := s.revocations.RevokeToken(claims); err != nil {
+		return err
+	}
+	if req.RefreshToken != "" {
+		return s.tokens.RevokeFamily(claims.UserID, req.RefreshToken)
+	}
+	return nil
+}
+
+// ForgotPassword 发送找回密码邮件
+func (s *UserService) ForgotPassword(req *ForgotPasswordRequest) error {
+	return s.resets.Request(req.Email)
+}
+
+// ResetPassword 使用邮件中的 token 设置新密码，并撤销该用户已有的全部会话
+func (s *UserService) ResetPassword(req *ResetPasswordRequest) error {
+	user, err := s.resets.Consume(req.Token, func(user *model.User) error {
+		return s.passwords.Validate(req.Password, user.Username, user.Email)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := user.SetPassword(req.Password); err != nil {
+		return err
+	}
+	// 重置链接发送到注册邮箱，能够完成重置同样证明了邮箱归属
+	if user.Status == model.UserStatusInactive {
+		user.Status = model.UserStatusActive
+	}
+	user.UpdatedAt = time.Now()
+	if _, err := s.repo.Update(user); err != nil {
+		return err
+	}
+
+	return s.RevokeSessions(user.ID)
+}
+
+// UnlockLogin 管理员解除用户因登录失败过多造成的锁定
+func (s *UserService) UnlockLogin(userID, adminID uint) error {
+	user, err := s.repo.FindById(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	return s.loginGuard.Unlock(user.Username, adminID)
+}
+
+// RevokeSessions 撤销用户的全部访问 token、刷新 token 和个人访问 token
+func (s *UserService) RevokeSessions(userID uint) error {
+	if _, err := s.repo.FindById(userID); err != nil {
+		return ErrUserNotFound
+	}
+	if err := s.revocations.RevokeUser(userID); err != nil {
+		return err
+	}
+	if err := s.personalTokens.RevokeUser(userID); err != nil {
+		return err
+	}
+	return s.tokens.RevokeUser(userID)
 }
 
-func (s *UserService) UpdateUser(id uint, req *UpdateRequest) (*model.User, error) {
+// UpdateUser 修改用户信息，只能修改自己的账号，管理员可以修改任何人。
+// 修改邮箱或密码需要操作者的当前密码，修改密码后撤销该用户的全部会话
+func (s *UserService) UpdateUser(actorID, id uint, req *UpdateRequest) (*model.User, error) {
+	actor, err := s.repo.FindById(actorID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if actorID != id {
+		admin, err := s.policyService.HasRoleForUser(actor.Username, "admin")
+		if err != nil {
+			return nil, err
+		}
+		if !admin {
+			return nil, ErrUserForbidden
+		}
+	}
+	if (req.Email != "" || req.Password != "") && !actor.ValidatePassword(req.CurrentPassword) {
+		return nil, ErrInvalidCurrentPassword
+	}
+
 	user, err := s.repo.FindById(id)
 	if err != nil {
 		return nil, ErrUserNotFound
@@ -108,7 +331,14 @@ func (s *UserService) UpdateUser(id uint, req *UpdateRequest) (*model.User, erro
 		user.Email = req.Email
 	}
 
+	if req.Locale != "" {
+		user.Locale = req.Locale
+	}
+
 	if req.Password != "" {
+		if err := s.passwords.Validate(req.Password, user.Username, user.Email); err != nil {
+			return nil, err
+		}
 		if err := user.SetPassword(req.Password); err != nil {
 			return nil, err
 		}
@@ -116,7 +346,18 @@ func (s *UserService) UpdateUser(id uint, req *UpdateRequest) (*model.User, erro
 
 	user.UpdatedAt = time.Now()
 
-	return s.repo.Update(user)
+	user, err = s.repo.Update(user)
+	if err != nil {
+		return nil, err
+	}
+	if req.Password != "" {
+		if err := s.RevokeSessions(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.webhooks.Publish(EventUserUpdated, user)
+	return user, nil
 }
 
 func (s *UserService) UpdateUserRole(userID uint, roleID uint) error {
@@ -149,5 +390,9 @@ func (s *UserService) UpdateUserRole(userID uint, roleID uint) error {
 		return fmt.Errorf("failed to add role %s: %v", role.Name, err)
 	}
 
+	s.webhooks.Publish(EventUserRoleChanged, map[string]interface{}{
+		"user": user,
+		"role": role,
+	})
 	return nil
 }
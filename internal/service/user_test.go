@@ -0,0 +1,106 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+// newTestUserService 返回带有会话撤销和个人访问 token 的用户服务，不发送邮件
+func newTestUserService(t *testing.T, db *gorm.DB, policy *PolicyService) (*UserService, *PersonalTokenService) {
+	t.Helper()
+	auth.Initialize("test-secret", time.Minute)
+	userRepo := repository.NewUserRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	personalTokens := NewPersonalTokenService(repository.NewPersonalTokenRepository(db), userRepo, policy)
+	users := NewUserService(userRepo, roleRepo, policy,
+		NewTokenService(repository.NewRefreshTokenRepository(db), userRepo, time.Minute, time.Hour),
+		NewRevocationService(repository.NewTokenRevocationRepository(db), time.Minute), personalTokens,
+		nil, nil, NewTwoFactorService(repository.NewTwoFactorRepository(db), userRepo, roleRepo, policy, "test"),
+		nil, NewPasswordPolicy(PasswordRules{}, nil), NewWebhookService(repository.NewWebhookRepository(db)))
+	return users, personalTokens
+}
+
+// createTestPersonalToken 直接在库中为用户创建个人访问 token，返回明文
+func createTestPersonalToken(t *testing.T, db *gorm.DB, userID uint) string {
+	t.Helper()
+	token := newOpaqueToken(auth.PersonalTokenPrefix)
+	if err := repository.NewPersonalTokenRepository(db).Create(&model.PersonalToken{
+		UserID: userID, Name: "ci", TokenHash: hashToken(token), Scopes: model.StringList{"GET /api/v1/articles"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRevokeSessionsDeletesPersonalTokens(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	users, personalTokens := newTestUserService(t, db, policy)
+
+	alice := createTestUser(t, db, policy, "alice", "user")
+	token := createTestPersonalToken(t, db, alice.ID)
+	if _, err := personalTokens.AuthenticatePersonalToken(token); err != nil {
+		t.Fatalf("personal token before revoking sessions: %v", err)
+	}
+
+	if err := users.RevokeSessions(alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := personalTokens.AuthenticatePersonalToken(token); err != auth.ErrInvalidToken {
+		t.Fatalf("personal token after revoking sessions = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestUpdateUserRequiresOwnerAndCurrentPassword(t *testing.T) {
+	db := newTestDB(t)
+	policy := newTestPolicy(t)
+	users, personalTokens := newTestUserService(t, db, policy)
+
+	admin := createTestUser(t, db, policy, "admin", "admin")
+	alice := createTestUser(t, db, policy, "alice", "user")
+	const current = "correct-horse-42"
+
+	_, err := users.UpdateUser(alice.ID, admin.ID, &UpdateRequest{Password: "new-password-42", CurrentPassword: current})
+	if err != ErrUserForbidden {
+		t.Fatalf("user changing the admin's password = %v, want ErrUserForbidden", err)
+	}
+	for _, req := range []*UpdateRequest{
+		{Email: "alice@evil.example.com"},
+		{Email: "alice@evil.example.com", CurrentPassword: "wrong"},
+		{Password: "new-password-42"},
+	} {
+		if _, err := users.UpdateUser(alice.ID, alice.ID, req); err != ErrInvalidCurrentPassword {
+			t.Errorf("update %+v = %v, want ErrInvalidCurrentPassword", req, err)
+		}
+	}
+	if _, err := users.UpdateUser(alice.ID, alice.ID, &UpdateRequest{Locale: "en"}); err != nil {
+		t.Fatalf("changing locale without password: %v", err)
+	}
+
+	token := createTestPersonalToken(t, db, alice.ID)
+	updated, err := users.UpdateUser(alice.ID, alice.ID, &UpdateRequest{Password: "new-password-42", CurrentPassword: current})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.ValidatePassword("new-password-42") {
+		t.Fatal("password not changed")
+	}
+	if _, err := personalTokens.AuthenticatePersonalToken(token); err != auth.ErrInvalidToken {
+		t.Fatalf("personal token after password change = %v, want ErrInvalidToken", err)
+	}
+
+	// 管理员使用自己的密码修改其他用户
+	updated, err = users.UpdateUser(admin.ID, alice.ID, &UpdateRequest{Email: "alice@new.example.com", CurrentPassword: current})
+	if err != nil {
+		t.Fatalf("admin changing a user's email: %v", err)
+	}
+	if updated.Email != "alice@new.example.com" {
+		t.Fatalf("email = %s, want alice@new.example.com", updated.Email)
+	}
+}
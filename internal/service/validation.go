@@ -0,0 +1,36 @@
+package service
+
+import "strings"
+
+// FieldError 字段级别的校验错误
+type FieldError struct {
+	Field   string `json:"field" example:"title"`
+	Rule    string `json:"rule" example:"required"`
+	Message string `json:"message" example:"title is required"`
+}
+
+// ValidationError 汇总一次校验中出现的所有字段错误
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		messages = append(messages, fe.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Add 记录一个字段错误
+func (e *ValidationError) Add(field, rule, message string) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Rule: rule, Message: message})
+}
+
+// Err 没有字段错误时返回 nil
+func (e *ValidationError) Err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/pkg/auth"
+	"github.com/wuwen/hello-go/internal/pkg/mail"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+const (
+	emailVerifyAudience         = "email-verify"
+	defaultVerifyExpire         = 24 * time.Hour
+	defaultVerifyResendInterval = time.Minute
+)
+
+var (
+	ErrInvalidVerifyToken = errors.New("invalid or expired verification link")
+)
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// verifyEmailData 验证邮件模板 verify_email 的数据
+type verifyEmailData struct {
+	SiteName string
+	Username string
+	Link     string
+	Expire   time.Duration
+}
+
+// VerificationService 注册时的邮箱验证：签发带签名的验证链接，校验后激活用户
+type VerificationService struct {
+	repo           *repository.UserRepository
+	mailer         *mail.Mailer
+	required       bool
+	expire         time.Duration
+	resendInterval time.Duration
+	siteName       string
+	baseURL        string
+}
+
+func NewVerificationService(repo *repository.UserRepository, mailer *mail.Mailer, required bool,
+	expire, resendInterval time.Duration, siteName, baseURL string) *VerificationService {
+	if expire <= 0 {
+		expire = defaultVerifyExpire
+	}
+	if resendInterval <= 0 {
+		resendInterval = defaultVerifyResendInterval
+	}
+	return &VerificationService{
+		repo:           repo,
+		mailer:         mailer,
+		required:       required,
+		expire:         expire,
+		resendInterval: resendInterval,
+		siteName:       siteName,
+		baseURL:        strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Required 新注册的用户是否需要先验证邮箱
+func (s *VerificationService) Required() bool {
+	return s.required
+}
+
+// Send 向未激活的用户发送验证邮件，距上次发送不足 resendInterval 时不发送。
+// 不返回错误，否则能通过响应区分邮箱是否已注册
+func (s *VerificationService) Send(user *model.User) error {
+	now := time.Now()
+	if user.VerificationSentAt != nil && now.Sub(*user.VerificationSentAt) < s.resendInterval {
+		return nil
+	}
+
+	// subject 带上邮箱，修改邮箱后旧链接失效
+	token, err := auth.GenerateScopedToken(emailVerifyAudience, fmt.Sprintf("%d:%s", user.ID, user.Email), s.expire)
+	if err != nil {
+		return err
+	}
+
+	user.VerificationSentAt = &now
+	if _, err := s.repo.Update(user); err != nil {
+		return err
+	}
+
+	data := &verifyEmailData{
+		SiteName: s.siteName,
+		Username: user.Username,
+		Link:     s.baseURL + "/api/v1/users/verify?token=" + token,
+		Expire:   s.expire,
+	}
+	if err := s.mailer.Send(context.Background(), user.Email, user.Locale, "verify_email", data); err != nil {
+		log.Printf("verification: failed to send email to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// Resend 重新发送验证邮件。邮箱不存在、用户已激活或发送过于频繁时同样返回成功，避免泄露账号是否存在
+func (s *VerificationService) Resend(req *ResendVerificationRequest) error {
+	user, err := s.repo.FindByEmail(req.Email)
+	if err != nil || user.Status != model.UserStatusInactive {
+		return nil
+	}
+	return s.Send(user)
+}
+
+// Verify 校验验证链接并激活用户，重复验证时直接返回
+func (s *VerificationService) Verify(token string) (*model.User, error) {
+	subject, err := auth.ParseScopedToken(emailVerifyAudience, token)
+	if err != nil {
+		return nil, ErrInvalidVerifyToken
+	}
+	parts := strings.SplitN(subject, ":", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidVerifyToken
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return nil, ErrInvalidVerifyToken
+	}
+
+	user, err := s.repo.FindById(uint(userID))
+	if err != nil || user.Email != parts[1] {
+		return nil, ErrInvalidVerifyToken
+	}
+	switch user.Status {
+	case model.UserStatusActive:
+		return user, nil
+	case model.UserStatusInactive:
+		user.Status = model.UserStatusActive
+		return s.repo.Update(user)
+	default:
+		return nil, ErrInvalidVerifyToken
+	}
+}
@@ -0,0 +1,426 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wuwen/hello-go/internal/model"
+	"github.com/wuwen/hello-go/internal/repository"
+)
+
+// 可订阅的事件
+const (
+	EventArticleCreated   = "article.created"
+	EventArticleUpdated   = "article.updated"
+	EventArticlePublished = "article.published"
+	EventArticleSubmitted = "article.submitted"
+	EventArticleReviewed  = "article.reviewed"
+	EventArticleDeleted   = "article.deleted"
+	EventUserRegistered   = "user.registered"
+	EventUserUpdated      = "user.updated"
+	EventUserRoleChanged  = "user.role_changed"
+	EventRoleCreated      = "role.created"
+	EventRoleUpdated      = "role.updated"
+	EventRoleDeleted      = "role.deleted"
+	EventLoginLocked      = "security.login_locked"
+	EventLoginUnlocked    = "security.login_unlocked"
+)
+
+var webhookEvents = []string{
+	EventArticleCreated, EventArticleUpdated, EventArticlePublished, EventArticleDeleted,
+	EventArticleSubmitted, EventArticleReviewed,
+	EventUserRegistered, EventUserUpdated, EventUserRoleChanged,
+	EventRoleCreated, EventRoleUpdated, EventRoleDeleted,
+	EventLoginLocked, EventLoginUnlocked,
+}
+
+const (
+	webhookPollInterval   = 5 * time.Second
+	webhookBatchSize      = 20
+	webhookTimeout        = 10 * time.Second
+	webhookMaxAttempts    = 8
+	webhookBaseBackoff    = 30 * time.Second
+	webhookMaxBackoff     = 6 * time.Hour
+	webhookMaxErrorLength = 500
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidWebhook   = errors.New("webhook url must be an absolute http(s) url")
+	ErrInvalidEvent     = errors.New("unknown webhook event")
+)
+
+type WebhookService struct {
+	repo   *repository.WebhookRepository
+	client *http.Client
+}
+
+func NewWebhookService(repo *repository.WebhookRepository) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required" example:"https://example.com/hooks/cms"`
+	Description string   `json:"description" example:"搜索索引"`
+	Events      []string `json:"events" binding:"required" example:"article.published,user.registered"`
+	Secret      string   `json:"secret" example:"留空则自动生成"`
+}
+
+type UpdateWebhookRequest struct {
+	URL         string   `json:"url" example:"https://example.com/hooks/cms"`
+	Description string   `json:"description" example:"搜索索引"`
+	Events      []string `json:"events" example:"article.*"`
+	Secret      string   `json:"secret"`
+	Active      *bool    `json:"active" example:"true"`
+}
+
+// WebhookSecretResponse 创建时返回一次签名密钥
+type WebhookSecretResponse struct {
+	*model.Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookEvent 投递给订阅方的消息体
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+func (s *WebhookService) Create(req *CreateWebhookRequest) (*WebhookSecretResponse, error) {
+	if err := validateWebhook(req.URL, req.Events); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = randomHex(32)
+	}
+
+	webhook := &model.Webhook{
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      req.Events,
+		Secret:      secret,
+		Active:      true,
+	}
+	if err := s.repo.Create(webhook); err != nil {
+		return nil, err
+	}
+
+	return &WebhookSecretResponse{Webhook: webhook, Secret: secret}, nil
+}
+
+func (s *WebhookService) List() ([]*model.Webhook, error) {
+	return s.repo.List()
+}
+
+func (s *WebhookService) Get(id uint) (*model.Webhook, error) {
+	webhook, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) Update(id uint, req *UpdateWebhookRequest) (*model.Webhook, error) {
+	webhook, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	if req.URL != "" {
+		webhook.URL = req.URL
+	}
+	if req.Events != nil {
+		webhook.Events = req.Events
+	}
+	if err := validateWebhook(webhook.URL, webhook.Events); err != nil {
+		return nil, err
+	}
+	if req.Description != "" {
+		webhook.Description = req.Description
+	}
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := s.repo.Update(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) Delete(id uint) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return ErrWebhookNotFound
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *WebhookService) ListDeliveries(webhookID uint, page, pageSize int) ([]*model.WebhookDelivery, int64, error) {
+	if _, err := s.repo.GetByID(webhookID); err != nil {
+		return nil, 0, ErrWebhookNotFound
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	return s.repo.ListDeliveries(webhookID, page, pageSize)
+}
+
+// Replay 以相同的消息体重新投递一次
+func (s *WebhookService) Replay(webhookID, deliveryID uint) (*model.WebhookDelivery, error) {
+	original, err := s.repo.GetDelivery(webhookID, deliveryID)
+	if err != nil {
+		return nil, ErrDeliveryNotFound
+	}
+
+	now := time.Now()
+	delivery := &model.WebhookDelivery{
+		WebhookID:     original.WebhookID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        model.DeliveryStatusPending,
+		NextAttemptAt: &now,
+		ReplayOf:      &original.ID,
+	}
+	if err := s.repo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Publish 为所有订阅了该事件的 webhook 创建投递记录，由后台任务异步发送。
+// 失败只记录日志，不影响触发事件的业务操作
+func (s *WebhookService) Publish(event string, data interface{}) {
+	webhooks, err := s.repo.ListActive()
+	if err != nil {
+		log.Printf("webhook: failed to load subscriptions for %s: %v", event, err)
+		return
+	}
+
+	var payload []byte
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event) {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = json.Marshal(WebhookEvent{
+				ID:        randomHex(16),
+				Event:     event,
+				CreatedAt: now,
+				Data:      data,
+			})
+			if err != nil {
+				log.Printf("webhook: failed to encode %s payload: %v", event, err)
+				return
+			}
+		}
+
+		delivery := &model.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        model.DeliveryStatusPending,
+			NextAttemptAt: &now,
+		}
+		if err := s.repo.CreateDelivery(delivery); err != nil {
+			log.Printf("webhook: failed to queue %s for webhook %d: %v", event, webhook.ID, err)
+		}
+	}
+}
+
+// Run 定期投递到期的记录，直到 ctx 结束
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.dispatchDue(ctx)
+		}
+	}
+}
+
+func (s *WebhookService) dispatchDue(ctx context.Context) {
+	now := time.Now()
+	deliveries, err := s.repo.ListDue(now, webhookBatchSize)
+	if err != nil {
+		log.Printf("webhook: failed to load due deliveries: %v", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		claimed, err := s.repo.Claim(delivery, now.Add(2*webhookTimeout))
+		if err != nil || !claimed {
+			continue
+		}
+		s.attempt(ctx, delivery)
+	}
+}
+
+// attempt 发送一次并根据结果更新状态，失败时按指数退避安排下次重试
+func (s *WebhookService) attempt(ctx context.Context, delivery *model.WebhookDelivery) {
+	webhook, err := s.repo.GetByID(delivery.WebhookID)
+	if err != nil {
+		delivery.Status = model.DeliveryStatusFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = "webhook no longer exists"
+		s.saveDelivery(delivery)
+		return
+	}
+
+	delivery.Attempts++
+	status, err := s.send(ctx, webhook, delivery)
+	delivery.ResponseStatus = status
+
+	now := time.Now()
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliveryStatusSucceeded
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = model.DeliveryStatusFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = truncate(err.Error(), webhookMaxErrorLength)
+	default:
+		next := now.Add(webhookBackoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		delivery.LastError = truncate(err.Error(), webhookMaxErrorLength)
+	}
+	s.saveDelivery(delivery)
+}
+
+func (s *WebhookService) send(ctx context.Context, webhook *model.Webhook, delivery *model.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "hello-go-webhook/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhookPayload(webhook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *WebhookService) saveDelivery(delivery *model.WebhookDelivery) {
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		log.Printf("webhook: failed to update delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// SignWebhookPayload 计算 HMAC-SHA256(secret, "<timestamp>.<body>")，订阅方可用同样方式校验
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff 第 n 次失败后的等待时间：30s、1m、2m……最长 6h
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff << (attempts - 1)
+	if backoff <= 0 || backoff > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+	return backoff
+}
+
+func validateWebhook(rawURL string, events []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhook
+	}
+
+	if len(events) == 0 {
+		return ErrInvalidEvent
+	}
+	for _, event := range events {
+		if !knownEventPattern(event) {
+			return fmt.Errorf("%w: %s", ErrInvalidEvent, event)
+		}
+	}
+	return nil
+}
+
+func knownEventPattern(pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+	for _, event := range webhookEvents {
+		if event == pattern {
+			return true
+		}
+		if strings.HasSuffix(pattern, ".*") && strings.HasPrefix(event, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}